@@ -103,3 +103,9 @@ func GetConfig(c *caddy.Controller) *Config {
 	ctx.saveConfig(key, &Config{ListenHosts: []string{""}})
 	return GetConfig(c)
 }
+
+// GetConfigs returns the configs of all server blocks that c is part of, i.e. all the
+// configs from the Corefile being loaded.
+func GetConfigs(c *caddy.Controller) []*Config {
+	return c.Context().(*dnsContext).configs
+}
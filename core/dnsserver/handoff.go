@@ -0,0 +1,79 @@
+package dnsserver
+
+import (
+	"strings"
+	"sync"
+
+	"github.com/coredns/caddy"
+	"github.com/coredns/coredns/plugin"
+)
+
+// Handoff is an optional interface plugin handlers can implement to take over the state of the instance they
+// replace when the Corefile is reloaded. This keeps, for instance, cache contents and upstream connections
+// across a reload.
+type Handoff interface {
+	// Handoff is called on the new handler with the handler of the same plugin from the same server
+	// block in the previous Corefile, before any OnStartup functions of the new instance run. The
+	// previous handler keeps serving queries until it is shut down, so state must be shared or copied,
+	// not taken away. If the configurations differ in a way that makes the state unusable, Handoff should do nothing.
+	Handoff(previous plugin.Handler)
+}
+
+var (
+	restartMu  sync.Mutex
+	restarting *dnsContext // context of the instance being restarted, if any
+)
+
+// trackRestart registers callbacks on i that remember h while i is being restarted, so the handlers of
+// the new instance can take over the state of the handlers in h.
+func trackRestart(i *caddy.Instance, h *dnsContext) {
+	i.OnRestart = append(i.OnRestart, func() error {
+		restartMu.Lock()
+		restarting = h
+		restartMu.Unlock()
+		return nil
+	})
+	i.OnRestartFailed = append(i.OnRestartFailed, func() error {
+		restartMu.Lock()
+		restarting = nil
+		restartMu.Unlock()
+		return nil
+	})
+}
+
+// handoff calls Handoff on the handlers in h that implement it, with the handler of the same plugin in the
+// same server block of the instance being restarted.
+func (h *dnsContext) handoff() {
+	restartMu.Lock()
+	prev := restarting
+	restarting = nil
+	restartMu.Unlock()
+
+	if prev == nil || prev == h {
+		return
+	}
+
+	previous := make(map[string]plugin.Handler)
+	for _, c := range prev.configs {
+		for name, hd := range c.registry {
+			previous[handoffKey(c, name)] = hd
+		}
+	}
+
+	for _, c := range h.configs {
+		for name, hd := range c.registry {
+			ho, ok := hd.(Handoff)
+			if !ok {
+				continue
+			}
+			if p, ok := previous[handoffKey(c, name)]; ok {
+				ho.Handoff(p)
+			}
+		}
+	}
+}
+
+// handoffKey returns a key that identifies the handler of plugin name in config c across reloads.
+func handoffKey(c *Config, name string) string {
+	return c.Transport + "://" + c.Zone + ":" + c.Port + "/" + strings.Join(c.ListenHosts, ",") + "/" + c.ViewName + "/" + name
+}
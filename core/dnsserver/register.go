@@ -31,7 +31,11 @@ func init() {
 }
 
 func newContext(i *caddy.Instance) caddy.Context {
-	return &dnsContext{keysToConfigs: make(map[string]*Config)}
+	h := &dnsContext{keysToConfigs: make(map[string]*Config)}
+	if i != nil {
+		trackRestart(i, h)
+	}
+	return h
 }
 
 type dnsContext struct {
@@ -211,6 +215,9 @@ func (h *dnsContext) MakeServers() ([]caddy.Server, error) {
 		return nil, errValid
 	}
 
+	// When restarting let the plugins take over the state of the instance they replace.
+	h.handoff()
+
 	return servers, nil
 }
 
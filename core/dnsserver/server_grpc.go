@@ -3,14 +3,20 @@ package dnsserver
 import (
 	"context"
 	"crypto/tls"
+	"encoding/binary"
 	"errors"
 	"fmt"
+	"io"
 	"net"
+	"sort"
+	"strings"
+	"sync"
 
 	"github.com/coredns/caddy"
 	"github.com/coredns/coredns/pb"
 	"github.com/coredns/coredns/plugin/pkg/reuseport"
 	"github.com/coredns/coredns/plugin/pkg/transport"
+	"github.com/coredns/coredns/plugin/pkg/watch"
 
 	"github.com/grpc-ecosystem/grpc-opentracing/go/otgrpc"
 	"github.com/miekg/dns"
@@ -26,6 +32,15 @@ type ServergRPC struct {
 	grpcServer *grpc.Server
 	listenAddr net.Addr
 	tlsConfig  *tls.Config
+
+	watchMu  sync.Mutex
+	watchers map[*watcher]struct{}
+}
+
+// watcher is a query that is being watched by a client.
+type watcher struct {
+	qname   string
+	changed chan struct{} // the data under qname may have changed
 }
 
 // NewServergRPC returns a new CoreDNS GRPC server and compiles all plugin in to it.
@@ -49,7 +64,30 @@ func NewServergRPC(addr string, group []*Config) (*ServergRPC, error) {
 		tlsConfig.NextProtos = []string{"h2"}
 	}
 
-	return &ServergRPC{Server: s, tlsConfig: tlsConfig}, nil
+	gs := &ServergRPC{Server: s, tlsConfig: tlsConfig, watchers: make(map[*watcher]struct{})}
+
+	// Have the plugins that can signal changes tell us about them, for Watch. The configs of a server
+	// block share their plugins, so only look at the first one of each block.
+	seen := map[*Config]struct{}{}
+	for _, z := range s.zones {
+		for _, conf := range z {
+			first := conf.firstConfigInBlock
+			if first == nil {
+				first = conf
+			}
+			if _, ok := seen[first]; ok {
+				continue
+			}
+			seen[first] = struct{}{}
+			for _, h := range conf.Handlers() {
+				if w, ok := h.(watch.Watchable); ok {
+					w.Watch(gs.changed)
+				}
+			}
+		}
+	}
+
+	return gs, nil
 }
 
 // Compile-time check to ensure Server implements the caddy.GracefulServer interface
@@ -66,7 +104,8 @@ func (s *ServergRPC) Serve(l net.Listener) error {
 			return parentSpanCtx != nil
 		}
 		intercept := otgrpc.OpenTracingServerInterceptor(s.Tracer(), otgrpc.IncludingSpans(onlyIfParent))
-		s.grpcServer = grpc.NewServer(grpc.UnaryInterceptor(intercept))
+		streamIntercept := otgrpc.OpenTracingStreamServerInterceptor(s.Tracer(), otgrpc.IncludingSpans(onlyIfParent))
+		s.grpcServer = grpc.NewServer(grpc.UnaryInterceptor(intercept), grpc.StreamInterceptor(streamIntercept))
 	} else {
 		s.grpcServer = grpc.NewServer()
 	}
@@ -128,6 +167,177 @@ func (s *ServergRPC) Query(ctx context.Context, in *pb.DnsPacket) (*pb.DnsPacket
 		return nil, err
 	}
 
+	reply, err := s.serve(ctx, msg)
+	if err != nil {
+		return nil, err
+	}
+
+	packed, err := reply.Pack()
+	if err != nil {
+		return nil, err
+	}
+
+	return &pb.DnsPacket{Msg: packed}, nil
+}
+
+// maxStreamQueries is the maximum number of queries on a stream that are answered at the same time.
+const maxStreamQueries = 1024
+
+// Stream answers the queries received on the stream concurrently, so replies can be sent in a different
+// order than the queries came in; the client matches them on the message ID. Every query gets a reply,
+// FORMERR if it can't be unpacked and SERVFAIL if it can't be answered.
+func (s *ServergRPC) Stream(stream pb.DnsService_StreamServer) error {
+	ctx := stream.Context()
+
+	var (
+		wg     sync.WaitGroup
+		sendMu sync.Mutex
+		sem    = make(chan struct{}, maxStreamQueries)
+	)
+	defer wg.Wait()
+
+	send := func(m *dns.Msg) error {
+		packed, err := m.Pack()
+		if err != nil {
+			return err
+		}
+		sendMu.Lock()
+		defer sendMu.Unlock()
+		return stream.Send(&pb.DnsPacket{Msg: packed})
+	}
+
+	for {
+		in, err := stream.Recv()
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		msg := new(dns.Msg)
+		if err := msg.Unpack(in.Msg); err != nil {
+			// Without an ID the client can't match the reply to the query, so there's nothing to send.
+			if len(in.Msg) >= 2 {
+				formerr := new(dns.Msg)
+				formerr.Id = binary.BigEndian.Uint16(in.Msg)
+				formerr.Response = true
+				formerr.Rcode = dns.RcodeFormatError
+				send(formerr)
+			}
+			continue
+		}
+
+		sem <- struct{}{}
+		wg.Add(1)
+		go func() {
+			defer func() {
+				<-sem
+				wg.Done()
+			}()
+			reply, err := s.serve(ctx, msg)
+			if err == nil {
+				if err = send(reply); err == nil {
+					return
+				}
+			}
+			servfail := new(dns.Msg)
+			servfail.Id = msg.Id
+			servfail.Response = true
+			servfail.Opcode = msg.Opcode
+			servfail.Rcode = dns.RcodeServerFailure
+			servfail.Question = msg.Question
+			send(servfail)
+		}()
+	}
+}
+
+// Watch sends the reply to the query in in, and after that a new reply each time the answer changes,
+// until the client goes away. Changes are signalled by the plugins that implement watch.Watchable.
+func (s *ServergRPC) Watch(in *pb.DnsPacket, stream pb.DnsService_WatchServer) error {
+	msg := new(dns.Msg)
+	if err := msg.Unpack(in.Msg); err != nil {
+		return err
+	}
+	if len(msg.Question) != 1 {
+		return errors.New("watch query must have one question")
+	}
+
+	w := &watcher{qname: msg.Question[0].Name, changed: make(chan struct{}, 1)}
+	s.watchMu.Lock()
+	s.watchers[w] = struct{}{}
+	s.watchMu.Unlock()
+	defer func() {
+		s.watchMu.Lock()
+		delete(s.watchers, w)
+		s.watchMu.Unlock()
+	}()
+
+	ctx := stream.Context()
+	last := ""
+	for {
+		// Plugins may change the request, so each lookup gets a fresh copy.
+		msg := new(dns.Msg)
+		msg.Unpack(in.Msg)
+		reply, err := s.serve(ctx, msg)
+		if err != nil {
+			return err
+		}
+		if key := answerKey(reply); key != last {
+			packed, err := reply.Pack()
+			if err != nil {
+				return err
+			}
+			if err := stream.Send(&pb.DnsPacket{Msg: packed}); err != nil {
+				return err
+			}
+			last = key
+		}
+
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-w.changed:
+		}
+	}
+}
+
+// changed signals the watchers of the names under name that their answer may have changed.
+func (s *ServergRPC) changed(name string) {
+	s.watchMu.Lock()
+	defer s.watchMu.Unlock()
+	for w := range s.watchers {
+		if !dns.IsSubDomain(name, w.qname) {
+			continue
+		}
+		select {
+		case w.changed <- struct{}{}:
+		default:
+		}
+	}
+}
+
+// answerKey returns a string that is the same for replies with the same rcode and records, regardless
+// of their order and TTLs.
+func answerKey(m *dns.Msg) string {
+	parts := []string{dns.RcodeToString[m.Rcode]}
+	for _, section := range [][]dns.RR{m.Answer, m.Ns, m.Extra} {
+		rrs := make([]string, 0, len(section))
+		for _, rr := range section {
+			if rr.Header().Rrtype == dns.TypeOPT {
+				continue
+			}
+			rr = dns.Copy(rr)
+			rr.Header().Ttl = 0
+			rrs = append(rrs, rr.String())
+		}
+		sort.Strings(rrs)
+		parts = append(parts, strings.Join(rrs, "\n"))
+	}
+	return strings.Join(parts, "\n\n")
+}
+
+// serve runs msg through the plugins and returns the reply.
+func (s *ServergRPC) serve(ctx context.Context, msg *dns.Msg) (*dns.Msg, error) {
 	p, ok := peer.FromContext(ctx)
 	if !ok {
 		return nil, errors.New("no peer in gRPC context")
@@ -144,12 +354,7 @@ func (s *ServergRPC) Query(ctx context.Context, in *pb.DnsPacket) (*pb.DnsPacket
 	dnsCtx = context.WithValue(dnsCtx, LoopKey{}, 0)
 	s.ServeDNS(dnsCtx, w, msg)
 
-	packed, err := w.Msg.Pack()
-	if err != nil {
-		return nil, err
-	}
-
-	return &pb.DnsPacket{Msg: packed}, nil
+	return w.Msg, nil
 }
 
 // Shutdown stops the server (non gracefully).
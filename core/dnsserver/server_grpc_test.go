@@ -0,0 +1,67 @@
+package dnsserver
+
+import (
+	"testing"
+
+	"github.com/coredns/coredns/plugin/pkg/watch"
+	"github.com/coredns/coredns/plugin/test"
+
+	"github.com/miekg/dns"
+)
+
+type testWatchPlugin struct {
+	testPlugin
+	*watch.Notifier
+}
+
+func TestGRPCWatchChanged(t *testing.T) {
+	p := testWatchPlugin{Notifier: new(watch.Notifier)}
+	s, err := NewServergRPC("127.0.0.1:53", []*Config{testConfig("grpc", p)})
+	if err != nil {
+		t.Fatalf("Expected no error for NewServergRPC, got %s", err)
+	}
+
+	w1 := &watcher{qname: "www.example.com.", changed: make(chan struct{}, 1)}
+	w2 := &watcher{qname: "www.example.net.", changed: make(chan struct{}, 1)}
+	s.watchers[w1] = struct{}{}
+	s.watchers[w2] = struct{}{}
+
+	p.Changed("example.com.")
+	p.Changed("example.com.") // doesn't block
+
+	select {
+	case <-w1.changed:
+	default:
+		t.Errorf("Expected watcher of www.example.com. to be signalled")
+	}
+	select {
+	case <-w2.changed:
+		t.Errorf("Expected watcher of www.example.net. not to be signalled")
+	default:
+	}
+}
+
+func TestAnswerKey(t *testing.T) {
+	m1 := new(dns.Msg)
+	m1.Answer = []dns.RR{test.A("example.com. 300 IN A 127.0.0.1"), test.A("example.com. 300 IN A 127.0.0.2")}
+	m1.SetEdns0(4096, false)
+
+	m2 := new(dns.Msg)
+	m2.Answer = []dns.RR{test.A("example.com. 10 IN A 127.0.0.2"), test.A("example.com. 10 IN A 127.0.0.1")}
+
+	if answerKey(m1) != answerKey(m2) {
+		t.Errorf("Expected the same key for replies that differ in order, TTL and OPT record")
+	}
+
+	m2.Ns, m2.Answer = m2.Answer[:1], m2.Answer[1:]
+	if answerKey(m1) == answerKey(m2) {
+		t.Errorf("Expected a different key for replies with records in different sections")
+	}
+
+	m2.Answer = m1.Answer
+	m2.Ns = nil
+	m2.Rcode = dns.RcodeServerFailure
+	if answerKey(m1) == answerKey(m2) {
+		t.Errorf("Expected a different key for replies with a different rcode")
+	}
+}
@@ -23,6 +23,7 @@ var Directives = []string{
 	"debug",
 	"trace",
 	"ready",
+	"admin",
 	"health",
 	"pprof",
 	"prometheus",
@@ -32,6 +33,9 @@ var Directives = []string{
 	"local",
 	"dns64",
 	"acl",
+	"throttle",
+	"rpz",
+	"blocklist",
 	"any",
 	"chaos",
 	"loadbalance",
@@ -44,6 +48,8 @@ var Directives = []string{
 	"minimal",
 	"template",
 	"transfer",
+	"geodns",
+	"gslb",
 	"hosts",
 	"route53",
 	"azure",
@@ -24,6 +24,8 @@ func init() {
 	flag.StringVar(&caddy.PidFile, "pidfile", "", "Path to write pid file")
 	flag.BoolVar(&version, "version", false, "Show version")
 	flag.BoolVar(&dnsserver.Quiet, "quiet", false, "Quiet mode (no initialization output)")
+	flag.StringVar(&validateFile, "validate-zone", "", "Zone file to check for problems, instead of starting")
+	flag.StringVar(&zoneOrigin, "zone-origin", "", "Origin of the zone given with -validate-zone (default the owner of its SOA)")
 
 	caddy.RegisterCaddyfileLoader("flag", caddy.LoaderFunc(confLoader))
 	caddy.SetDefaultCaddyfileLoader("default", caddy.LoaderFunc(defaultLoader))
@@ -55,6 +57,16 @@ func Run() {
 		fmt.Println(caddy.DescribePlugins())
 		os.Exit(0)
 	}
+	if validateFile != "" {
+		n, err := validateZone(os.Stdout, validateFile, zoneOrigin)
+		if err != nil {
+			mustLogFatal(err)
+		}
+		if n > 0 {
+			os.Exit(1)
+		}
+		os.Exit(0)
+	}
 
 	// Get Corefile input
 	corefile, err := caddy.LoadCaddyfile(serverType)
@@ -166,9 +178,11 @@ func setVersion() {
 
 // Flags that control program flow or startup
 var (
-	conf    string
-	version bool
-	plugins bool
+	conf         string
+	version      bool
+	plugins      bool
+	validateFile string
+	zoneOrigin   string
 )
 
 // Build information obtained with the help of -ldflags
@@ -0,0 +1,70 @@
+package coremain
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+
+	"github.com/coredns/coredns/plugin/file"
+
+	"github.com/miekg/dns"
+)
+
+// validateZone parses the zone file path and prints the problems found in it to w. Origin is the origin
+// of the zone, if empty the owner of the SOA record is used. It returns the number of problems, or an error
+// when the zone can't be parsed at all.
+func validateZone(w io.Writer, path, origin string) (int, error) {
+	if origin == "" {
+		o, err := soaOwner(path)
+		if err != nil {
+			return 0, err
+		}
+		origin = o
+	}
+	origin = dns.Fqdn(origin)
+
+	f, err := os.Open(filepath.Clean(path))
+	if err != nil {
+		return 0, err
+	}
+	defer f.Close()
+
+	z, err := file.Parse(f, origin, path, 0)
+	if err != nil {
+		return 0, err
+	}
+	problems := z.Problems()
+	for _, p := range problems {
+		fmt.Fprintf(w, "%s: %s\n", path, p)
+	}
+	return len(problems), nil
+}
+
+// noOrigin is the origin the zone file is parsed with to find the SOA. An owner below it was relative,
+// e.g. @, and there was no $ORIGIN.
+const noOrigin = "origin.invalid."
+
+// soaOwner returns the owner name of the first SOA record in the zone file path.
+func soaOwner(path string) (string, error) {
+	f, err := os.Open(filepath.Clean(path))
+	if err != nil {
+		return "", err
+	}
+	defer f.Close()
+
+	zp := dns.NewZoneParser(f, noOrigin, path)
+	for rr, ok := zp.Next(); ok; rr, ok = zp.Next() {
+		if rr.Header().Rrtype != dns.TypeSOA {
+			continue
+		}
+		if dns.IsSubDomain(noOrigin, rr.Header().Name) {
+			return "", fmt.Errorf("the SOA owner in %s is relative and there is no $ORIGIN, use -zone-origin to set the origin", path)
+		}
+		return rr.Header().Name, nil
+	}
+	if err := zp.Err(); err != nil {
+		return "", err
+	}
+	return "", fmt.Errorf("no SOA record in %s, use -zone-origin to set the origin", path)
+}
@@ -0,0 +1,84 @@
+package coremain
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestValidateZone(t *testing.T) {
+	tests := []struct {
+		name     string
+		zone     string
+		origin   string
+		problems []string
+		err      bool
+	}{
+		{
+			name: "valid",
+			zone: `$ORIGIN example.org.
+@   IN SOA ns1 hostmaster 1 7200 3600 1209600 3600
+    IN NS  ns1
+ns1 IN A   192.0.2.53`,
+		},
+		{
+			name: "problems",
+			zone: `$ORIGIN example.org.
+@   IN SOA ns1 hostmaster 1 7200 3600 1209600 3600
+    IN NS  ns1
+    IN NS  ns2
+ns1 IN A   192.0.2.53`,
+			problems: []string{"example.org.: NS ns2.example.org. has no address records"},
+		},
+		{
+			name: "absolute SOA owner",
+			zone: `example.org. IN SOA ns1.example.org. hostmaster.example.org. 1 7200 3600 1209600 3600
+example.org. IN NS  ns1.example.org.
+ns1.example.org. IN A 192.0.2.53`,
+		},
+		{
+			name: "relative SOA owner without origin",
+			zone: `@   IN SOA ns1 hostmaster 1 7200 3600 1209600 3600
+    IN NS  ns1
+ns1 IN A   192.0.2.53`,
+			err: true,
+		},
+		{
+			name: "relative SOA owner with -zone-origin",
+			zone: `@   IN SOA ns1 hostmaster 1 7200 3600 1209600 3600
+    IN NS  ns1
+ns1 IN A   192.0.2.53`,
+			origin: "example.org",
+		},
+		{
+			name: "no SOA",
+			zone: `$ORIGIN example.org.
+ns1 IN A 192.0.2.53`,
+			err: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), "db.zone")
+			if err := os.WriteFile(path, []byte(tt.zone+"\n"), 0644); err != nil {
+				t.Fatal(err)
+			}
+			buf := &bytes.Buffer{}
+			n, err := validateZone(buf, path, tt.origin)
+			if (err != nil) != tt.err {
+				t.Fatalf("Expected error %t, got %v", tt.err, err)
+			}
+			if n != len(tt.problems) {
+				t.Errorf("Expected %d problems, got %d: %s", len(tt.problems), n, buf)
+			}
+			for _, p := range tt.problems {
+				if !strings.Contains(buf.String(), path+": "+p+"\n") {
+					t.Errorf("Expected problem %q, got %q", p, buf)
+				}
+			}
+		})
+	}
+}
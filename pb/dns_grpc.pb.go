@@ -23,6 +23,8 @@ const _ = grpc.SupportPackageIsVersion7
 // For semantics around ctx use and closing/ending streaming RPCs, please refer to https://pkg.go.dev/google.golang.org/grpc/?tab=doc#ClientConn.NewStream.
 type DnsServiceClient interface {
 	Query(ctx context.Context, in *DnsPacket, opts ...grpc.CallOption) (*DnsPacket, error)
+	Stream(ctx context.Context, opts ...grpc.CallOption) (DnsService_StreamClient, error)
+	Watch(ctx context.Context, in *DnsPacket, opts ...grpc.CallOption) (DnsService_WatchClient, error)
 }
 
 type dnsServiceClient struct {
@@ -42,11 +44,76 @@ func (c *dnsServiceClient) Query(ctx context.Context, in *DnsPacket, opts ...grp
 	return out, nil
 }
 
+func (c *dnsServiceClient) Stream(ctx context.Context, opts ...grpc.CallOption) (DnsService_StreamClient, error) {
+	stream, err := c.cc.NewStream(ctx, &DnsService_ServiceDesc.Streams[0], "/coredns.dns.DnsService/Stream", opts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &dnsServiceStreamClient{stream}
+	return x, nil
+}
+
+type DnsService_StreamClient interface {
+	Send(*DnsPacket) error
+	Recv() (*DnsPacket, error)
+	grpc.ClientStream
+}
+
+type dnsServiceStreamClient struct {
+	grpc.ClientStream
+}
+
+func (x *dnsServiceStreamClient) Send(m *DnsPacket) error {
+	return x.ClientStream.SendMsg(m)
+}
+
+func (x *dnsServiceStreamClient) Recv() (*DnsPacket, error) {
+	m := new(DnsPacket)
+	if err := x.ClientStream.RecvMsg(m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+func (c *dnsServiceClient) Watch(ctx context.Context, in *DnsPacket, opts ...grpc.CallOption) (DnsService_WatchClient, error) {
+	stream, err := c.cc.NewStream(ctx, &DnsService_ServiceDesc.Streams[1], "/coredns.dns.DnsService/Watch", opts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &dnsServiceWatchClient{stream}
+	if err := x.ClientStream.SendMsg(in); err != nil {
+		return nil, err
+	}
+	if err := x.ClientStream.CloseSend(); err != nil {
+		return nil, err
+	}
+	return x, nil
+}
+
+type DnsService_WatchClient interface {
+	Recv() (*DnsPacket, error)
+	grpc.ClientStream
+}
+
+type dnsServiceWatchClient struct {
+	grpc.ClientStream
+}
+
+func (x *dnsServiceWatchClient) Recv() (*DnsPacket, error) {
+	m := new(DnsPacket)
+	if err := x.ClientStream.RecvMsg(m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
 // DnsServiceServer is the server API for DnsService service.
 // All implementations must embed UnimplementedDnsServiceServer
 // for forward compatibility
 type DnsServiceServer interface {
 	Query(context.Context, *DnsPacket) (*DnsPacket, error)
+	Stream(DnsService_StreamServer) error
+	Watch(*DnsPacket, DnsService_WatchServer) error
 	mustEmbedUnimplementedDnsServiceServer()
 }
 
@@ -57,6 +124,12 @@ type UnimplementedDnsServiceServer struct {
 func (UnimplementedDnsServiceServer) Query(context.Context, *DnsPacket) (*DnsPacket, error) {
 	return nil, status.Errorf(codes.Unimplemented, "method Query not implemented")
 }
+func (UnimplementedDnsServiceServer) Stream(DnsService_StreamServer) error {
+	return status.Errorf(codes.Unimplemented, "method Stream not implemented")
+}
+func (UnimplementedDnsServiceServer) Watch(*DnsPacket, DnsService_WatchServer) error {
+	return status.Errorf(codes.Unimplemented, "method Watch not implemented")
+}
 func (UnimplementedDnsServiceServer) mustEmbedUnimplementedDnsServiceServer() {}
 
 // UnsafeDnsServiceServer may be embedded to opt out of forward compatibility for this service.
@@ -88,6 +161,53 @@ func _DnsService_Query_Handler(srv interface{}, ctx context.Context, dec func(in
 	return interceptor(ctx, in, info, handler)
 }
 
+func _DnsService_Stream_Handler(srv interface{}, stream grpc.ServerStream) error {
+	return srv.(DnsServiceServer).Stream(&dnsServiceStreamServer{stream})
+}
+
+type DnsService_StreamServer interface {
+	Send(*DnsPacket) error
+	Recv() (*DnsPacket, error)
+	grpc.ServerStream
+}
+
+type dnsServiceStreamServer struct {
+	grpc.ServerStream
+}
+
+func (x *dnsServiceStreamServer) Send(m *DnsPacket) error {
+	return x.ServerStream.SendMsg(m)
+}
+
+func (x *dnsServiceStreamServer) Recv() (*DnsPacket, error) {
+	m := new(DnsPacket)
+	if err := x.ServerStream.RecvMsg(m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+func _DnsService_Watch_Handler(srv interface{}, stream grpc.ServerStream) error {
+	m := new(DnsPacket)
+	if err := stream.RecvMsg(m); err != nil {
+		return err
+	}
+	return srv.(DnsServiceServer).Watch(m, &dnsServiceWatchServer{stream})
+}
+
+type DnsService_WatchServer interface {
+	Send(*DnsPacket) error
+	grpc.ServerStream
+}
+
+type dnsServiceWatchServer struct {
+	grpc.ServerStream
+}
+
+func (x *dnsServiceWatchServer) Send(m *DnsPacket) error {
+	return x.ServerStream.SendMsg(m)
+}
+
 // DnsService_ServiceDesc is the grpc.ServiceDesc for DnsService service.
 // It's only intended for direct use with grpc.RegisterService,
 // and not to be introspected or modified (even as a copy)
@@ -100,6 +220,18 @@ var DnsService_ServiceDesc = grpc.ServiceDesc{
 			Handler:    _DnsService_Query_Handler,
 		},
 	},
-	Streams:  []grpc.StreamDesc{},
+	Streams: []grpc.StreamDesc{
+		{
+			StreamName:    "Stream",
+			Handler:       _DnsService_Stream_Handler,
+			ServerStreams: true,
+			ClientStreams: true,
+		},
+		{
+			StreamName:    "Watch",
+			Handler:       _DnsService_Watch_Handler,
+			ServerStreams: true,
+		},
+	},
 	Metadata: "dns.proto",
 }
@@ -19,6 +19,10 @@ type ACL struct {
 	Next plugin.Handler
 
 	Rules []rule
+
+	// tsigSecret returns the TSIG keys of the server, a tsig condition only matches requests signed
+	// with one of them. It's a function, because the tsig plugin is set up after acl.
+	tsigSecret func() map[string]string
 }
 
 // rule defines a list of Zones and some ACL policies which will be
@@ -33,11 +37,13 @@ type action int
 
 // policy defines the ACL policy for DNS queries.
 // A policy performs the specified action (block/allow) on all DNS queries
-// matched by source IP or QTYPE.
+// matched by source IP or QTYPE, and its other conditions.
 type policy struct {
 	action action
 	qtypes map[uint16]struct{}
 	filter *iptree.Tree
+	files  []*netFile // files with more source IPs
+	conditions
 }
 
 const (
@@ -67,7 +73,7 @@ RulesCheckLoop:
 			continue
 		}
 
-		action := matchWithPolicies(rule.policies, w, r)
+		action := a.matchWithPolicies(ctx, rule.policies, w, r)
 		switch action {
 		case actionDrop:
 			{
@@ -109,7 +115,7 @@ RulesCheckLoop:
 
 // matchWithPolicies matches the DNS query with a list of ACL polices and returns suitable
 // action against the query.
-func matchWithPolicies(policies []policy, w dns.ResponseWriter, r *dns.Msg) action {
+func (a ACL) matchWithPolicies(ctx context.Context, policies []policy, w dns.ResponseWriter, r *dns.Msg) action {
 	state := request.Request{W: w, Req: r}
 
 	var ip net.IP
@@ -135,10 +141,20 @@ func matchWithPolicies(policies []policy, w dns.ResponseWriter, r *dns.Msg) acti
 		}
 
 		_, contained := policy.filter.GetByIP(ip)
+		for _, f := range policy.files {
+			if contained {
+				break
+			}
+			contained = f.contains(ip)
+		}
 		if !contained {
 			continue
 		}
 
+		if !policy.conditions.match(ctx, state, a.tsigSecret) {
+			continue
+		}
+
 		// matched.
 		return policy.action
 	}
@@ -2,9 +2,12 @@ package acl
 
 import (
 	"context"
+	"net"
+	"strings"
 	"testing"
 
 	"github.com/coredns/caddy"
+	"github.com/coredns/coredns/plugin/metadata"
 	"github.com/coredns/coredns/plugin/test"
 
 	"github.com/miekg/dns"
@@ -12,10 +15,14 @@ import (
 
 type testResponseWriter struct {
 	test.ResponseWriter
-	Rcode int
-	Msg   *dns.Msg
+	Rcode   int
+	Msg     *dns.Msg
+	tsigErr error // returned by TsigStatus
 }
 
+// TsigStatus implements the dns.ResponseWriter interface.
+func (t *testResponseWriter) TsigStatus() error { return t.tsigErr }
+
 func (t *testResponseWriter) setRemoteIP(ip string) {
 	t.RemoteIP = ip
 }
@@ -597,3 +604,131 @@ func TestACLServeDNS(t *testing.T) {
 		})
 	}
 }
+
+func TestACLConditions(t *testing.T) {
+	tests := []struct {
+		name      string
+		config    string
+		tsig      string
+		tsigErr   error
+		edns0     dns.EDNS0
+		label     string
+		wantRcode int
+	}{
+		{
+			name:      "TSIG signed",
+			config:    `acl example.org { allow tsig xfr.example.org. ; block }`,
+			tsig:      "xfr.example.org.",
+			wantRcode: dns.RcodeSuccess,
+		},
+		{
+			name:      "TSIG other key",
+			config:    `acl example.org { allow tsig xfr.example.org. ; block }`,
+			tsig:      "other.example.org.",
+			wantRcode: dns.RcodeRefused,
+		},
+		{
+			name:      "TSIG any key",
+			config:    `acl example.org { allow tsig * ; block }`,
+			tsig:      "xfr.example.org.",
+			wantRcode: dns.RcodeSuccess,
+		},
+		{
+			name:      "TSIG bad signature",
+			config:    `acl example.org { allow tsig xfr.example.org. ; block }`,
+			tsig:      "xfr.example.org.",
+			tsigErr:   dns.ErrSig,
+			wantRcode: dns.RcodeRefused,
+		},
+		{
+			name:      "TSIG key without secret",
+			config:    `acl example.org { allow tsig unknown.example.org. ; block }`,
+			tsig:      "unknown.example.org.",
+			wantRcode: dns.RcodeRefused,
+		},
+		{
+			name:      "TSIG any key without secret",
+			config:    `acl example.org { allow tsig * ; block }`,
+			tsig:      "unknown.example.org.",
+			wantRcode: dns.RcodeRefused,
+		},
+		{
+			name:      "TSIG unsigned",
+			config:    `acl example.org { allow tsig * ; block }`,
+			wantRcode: dns.RcodeRefused,
+		},
+		{
+			name:      "EDNS0 option",
+			config:    `acl example.org { block edns0 SUBNET }`,
+			edns0:     &dns.EDNS0_SUBNET{Code: dns.EDNS0SUBNET, Family: 1, SourceNetmask: 24, Address: net.ParseIP("192.0.2.0")},
+			wantRcode: dns.RcodeRefused,
+		},
+		{
+			name:      "EDNS0 other option",
+			config:    `acl example.org { block edns0 0xfffe }`,
+			edns0:     &dns.EDNS0_NSID{Code: dns.EDNS0NSID},
+			wantRcode: dns.RcodeSuccess,
+		},
+		{
+			name:      "Metadata match",
+			config:    `acl example.org { allow metadata test/label=blue ; block }`,
+			label:     "blue",
+			wantRcode: dns.RcodeSuccess,
+		},
+		{
+			name:      "Metadata no match",
+			config:    `acl example.org { allow metadata test/label=blue ; block }`,
+			label:     "green",
+			wantRcode: dns.RcodeRefused,
+		},
+		{
+			name:      "Expression match",
+			config:    `acl example.org { block expression "type() == 'A' && incidr(client_ip(), '10.240.0.0/16')" }`,
+			wantRcode: dns.RcodeRefused,
+		},
+		{
+			name:      "Expression no match",
+			config:    `acl example.org { block expression "type() == 'AAAA'" }`,
+			wantRcode: dns.RcodeSuccess,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Semicolons make it easier to put several policies on one line.
+			config := strings.NewReplacer("{ ", "{\n", " ; ", "\n", " }", "\n}").Replace(tt.config)
+			a, err := parse(caddy.NewTestController("dns", config))
+			if err != nil {
+				t.Fatalf("Error: Cannot parse acl from config: %v", err)
+			}
+			a.Next = test.NextHandler(dns.RcodeSuccess, nil)
+			// The server has secrets for these keys, unknown.example.org. isn't one of them.
+			a.tsigSecret = func() map[string]string {
+				return map[string]string{"xfr.example.org.": "c2VjcmV0", "other.example.org.": "c2VjcmV0"}
+			}
+
+			m := new(dns.Msg)
+			m.SetQuestion("www.example.org.", dns.TypeA)
+			if tt.edns0 != nil {
+				m.SetEdns0(4096, false)
+				m.IsEdns0().Option = append(m.IsEdns0().Option, tt.edns0)
+			}
+			if tt.tsig != "" {
+				m.SetTsig(tt.tsig, dns.HmacSHA256, 300, 0)
+			}
+
+			ctx := metadata.ContextWithMetadata(context.Background())
+			label := tt.label
+			metadata.SetValueFunc(ctx, "test/label", func() string { return label })
+
+			w := &testResponseWriter{tsigErr: tt.tsigErr}
+			rcode, _ := a.ServeDNS(ctx, w, m)
+			if w.Msg != nil {
+				rcode = w.Rcode
+			}
+			if rcode != tt.wantRcode {
+				t.Errorf("Error: acl.ServeDNS() Rcode = %v, want %v", rcode, tt.wantRcode)
+			}
+		})
+	}
+}
@@ -0,0 +1,170 @@
+package acl
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/coredns/coredns/plugin/metadata"
+	"github.com/coredns/coredns/plugin/pkg/expression"
+	"github.com/coredns/coredns/request"
+
+	"github.com/antonmedv/expr"
+	"github.com/antonmedv/expr/vm"
+	"github.com/miekg/dns"
+)
+
+// conditions are the conditions of a policy other than the query type and source. A condition that
+// isn't set always matches.
+type conditions struct {
+	tsig   map[string]struct{} // names of the TSIG keys the request must be signed with, "*" is any key
+	edns0  map[uint16]struct{} // EDNS0 option codes of which the request must carry one
+	labels []label             // metadata labels of which one must have the given value
+	expr   *vm.Program         // expression that must evaluate to true
+}
+
+// label is a metadata label and the value it must have.
+type label struct {
+	name  string
+	value string
+}
+
+// edns0Codes are the names of EDNS0 options that can be used instead of their codes.
+var edns0Codes = map[string]uint16{
+	"NSID":          dns.EDNS0NSID,
+	"SUBNET":        dns.EDNS0SUBNET,
+	"EXPIRE":        dns.EDNS0EXPIRE,
+	"COOKIE":        dns.EDNS0COOKIE,
+	"TCP-KEEPALIVE": dns.EDNS0TCPKEEPALIVE,
+	"PADDING":       dns.EDNS0PADDING,
+}
+
+// parseTSIG parses the key names of a tsig section. Empty sections are rejected by parse, an empty map
+// would never match.
+func (c *conditions) parseTSIG(tokens []string) {
+	if c.tsig == nil {
+		c.tsig = make(map[string]struct{})
+	}
+	for _, token := range tokens {
+		if token == "*" {
+			c.tsig[token] = struct{}{}
+			continue
+		}
+		c.tsig[dns.CanonicalName(token)] = struct{}{}
+	}
+}
+
+// parseEDNS0 parses the option codes, or names, of an edns0 section. Like tsig, it can't be empty.
+func (c *conditions) parseEDNS0(tokens []string) error {
+	if c.edns0 == nil {
+		c.edns0 = make(map[uint16]struct{})
+	}
+	for _, token := range tokens {
+		if code, ok := edns0Codes[strings.ToUpper(token)]; ok {
+			c.edns0[code] = struct{}{}
+			continue
+		}
+		code, err := strconv.ParseUint(token, 0, 16)
+		if err != nil {
+			return fmt.Errorf("unexpected token %q; expect EDNS0 option code or name", token)
+		}
+		c.edns0[uint16(code)] = struct{}{}
+	}
+	return nil
+}
+
+// parseMetadata parses the LABEL=VALUE pairs of a metadata section.
+func (c *conditions) parseMetadata(tokens []string) error {
+	for _, token := range tokens {
+		name, value, ok := strings.Cut(token, "=")
+		if !ok || name == "" {
+			return fmt.Errorf("unexpected token %q; expect LABEL=VALUE", token)
+		}
+		c.labels = append(c.labels, label{name: name, value: value})
+	}
+	return nil
+}
+
+// parseExpression compiles the expression of an expression section.
+func (c *conditions) parseExpression(tokens []string) error {
+	e := strings.Join(tokens, " ")
+	prog, err := expr.Compile(e, expr.Env(expression.DefaultEnv(context.Background(), nil)))
+	if err != nil {
+		return fmt.Errorf("invalid expression %q: %s", e, err)
+	}
+	c.expr = prog
+	return nil
+}
+
+// match returns true if the request in state meets all conditions. The TSIG keys of the server are
+// returned by secrets.
+func (c conditions) match(ctx context.Context, state request.Request, secrets func() map[string]string) bool {
+	return c.matchTSIG(state, secrets) && c.matchEDNS0(state) && c.matchMetadata(ctx) && c.matchExpression(ctx, state)
+}
+
+// matchTSIG returns true if the request is signed with one of the keys and the signature is valid. The
+// server only verifies signatures made with a key it has a secret for; a request signed with any other
+// key has a nil TsigStatus, so it must not match.
+func (c conditions) matchTSIG(state request.Request, secrets func() map[string]string) bool {
+	if c.tsig == nil {
+		return true
+	}
+	t := state.Req.IsTsig()
+	if t == nil || secrets == nil || state.W.TsigStatus() != nil {
+		return false
+	}
+	name := dns.CanonicalName(t.Hdr.Name)
+	if _, ok := secrets()[name]; !ok {
+		return false
+	}
+	if _, ok := c.tsig["*"]; ok {
+		return true
+	}
+	_, ok := c.tsig[name]
+	return ok
+}
+
+// matchEDNS0 returns true if the request carries one of the EDNS0 options.
+func (c conditions) matchEDNS0(state request.Request) bool {
+	if c.edns0 == nil {
+		return true
+	}
+	opt := state.Req.IsEdns0()
+	if opt == nil {
+		return false
+	}
+	for _, o := range opt.Option {
+		if _, ok := c.edns0[o.Option()]; ok {
+			return true
+		}
+	}
+	return false
+}
+
+// matchMetadata returns true if one of the metadata labels has its value.
+func (c conditions) matchMetadata(ctx context.Context) bool {
+	if c.labels == nil {
+		return true
+	}
+	for _, l := range c.labels {
+		if f := metadata.ValueFunc(ctx, l.name); f != nil && f() == l.value {
+			return true
+		}
+	}
+	return false
+}
+
+// matchExpression returns true if the expression evaluates to true, anything else, including an error,
+// is no match.
+func (c conditions) matchExpression(ctx context.Context, state request.Request) bool {
+	if c.expr == nil {
+		return true
+	}
+	result, err := expr.Run(c.expr, expression.DefaultEnv(ctx, &state))
+	if err != nil {
+		return false
+	}
+	b, ok := result.(bool)
+	return ok && b
+}
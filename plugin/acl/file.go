@@ -0,0 +1,123 @@
+package acl
+
+import (
+	"bufio"
+	"fmt"
+	"net"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/infobloxopen/go-trees/iptree"
+)
+
+// netFileReload is the interval at which files with sources are checked for changes.
+const netFileReload = 5 * time.Second
+
+// netFile is a file with source addresses and networks, which is read again when it changes.
+type netFile struct {
+	path string
+
+	sync.RWMutex
+	tree    *iptree.Tree
+	mtime   time.Time
+	size    int64
+	entries int
+}
+
+// newNetFile returns a netFile for path, of which the contents have been read.
+func newNetFile(path string) (*netFile, error) {
+	f := &netFile{path: path}
+	if _, err := f.readIfChanged(); err != nil {
+		return nil, err
+	}
+	return f, nil
+}
+
+// contains returns true if ip is in one of the networks of the file.
+func (f *netFile) contains(ip net.IP) bool {
+	f.RLock()
+	defer f.RUnlock()
+	_, ok := f.tree.GetByIP(ip)
+	return ok
+}
+
+// readIfChanged reads the file if its modification time or size changed since it was last read.
+// It returns true if it was read. When reading fails, the previous contents are kept.
+func (f *netFile) readIfChanged() (bool, error) {
+	fi, err := os.Stat(f.path)
+	if err != nil {
+		return false, err
+	}
+	f.RLock()
+	same := fi.ModTime().Equal(f.mtime) && fi.Size() == f.size
+	f.RUnlock()
+	if same {
+		return false, nil
+	}
+
+	file, err := os.Open(filepath.Clean(f.path))
+	if err != nil {
+		return false, err
+	}
+	defer file.Close()
+
+	tree := iptree.NewTree()
+	entries := 0
+	scanner := bufio.NewScanner(file)
+	for n := 1; scanner.Scan(); n++ {
+		line := scanner.Text()
+		if i := strings.IndexByte(line, '#'); i >= 0 {
+			line = line[:i]
+		}
+		line = strings.TrimSpace(line)
+		if line == "" {
+			continue
+		}
+		_, source, err := net.ParseCIDR(normalize(line))
+		if err != nil {
+			return false, fmt.Errorf("%s:%d: illegal CIDR notation %q", f.path, n, line)
+		}
+		tree.InplaceInsertNet(source, struct{}{})
+		entries++
+	}
+	if err := scanner.Err(); err != nil {
+		return false, err
+	}
+
+	f.Lock()
+	f.tree = tree
+	f.mtime = fi.ModTime()
+	f.size = fi.Size()
+	f.entries = entries
+	f.Unlock()
+	return true, nil
+}
+
+// reloadNetFiles checks files for changes every netFileReload, until stop is closed.
+func reloadNetFiles(files []*netFile, stop <-chan struct{}) {
+	tick := time.NewTicker(netFileReload)
+	defer tick.Stop()
+
+	for {
+		select {
+		case <-stop:
+			return
+		case <-tick.C:
+			for _, f := range files {
+				read, err := f.readIfChanged()
+				if err != nil {
+					log.Warningf("Failed to reload %s, keeping the previous contents: %s", f.path, err)
+					continue
+				}
+				if read {
+					f.RLock()
+					log.Infof("Reloaded %s with %d entries", f.path, f.entries)
+					f.RUnlock()
+				}
+			}
+		}
+	}
+}
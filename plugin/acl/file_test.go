@@ -0,0 +1,50 @@
+package acl
+
+import (
+	"net"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestNetFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "allow.txt")
+	if err := os.WriteFile(path, []byte("# customers\n192.0.2.0/24\n\n2001:db8::1 # single address\n"), 0600); err != nil {
+		t.Fatal(err)
+	}
+	f, err := newNetFile(path)
+	if err != nil {
+		t.Fatalf("Expected no error, got %s", err)
+	}
+	for ip, want := range map[string]bool{"192.0.2.10": true, "2001:db8::1": true, "2001:db8::2": false, "198.51.100.1": false} {
+		if got := f.contains(net.ParseIP(ip)); got != want {
+			t.Errorf("Expected %s contained: %t, got %t", ip, want, got)
+		}
+	}
+
+	if read, err := f.readIfChanged(); err != nil || read {
+		t.Errorf("Expected unchanged file not to be read, got %t, %v", read, err)
+	}
+
+	// An invalid file keeps the previous contents.
+	later := time.Now().Add(time.Minute)
+	os.WriteFile(path, []byte("192.0.2.0/33\n"), 0600)
+	os.Chtimes(path, later, later)
+	if _, err := f.readIfChanged(); err == nil {
+		t.Errorf("Expected error for invalid file")
+	}
+	if !f.contains(net.ParseIP("192.0.2.10")) {
+		t.Errorf("Expected previous contents to be kept")
+	}
+
+	later = later.Add(time.Minute)
+	os.WriteFile(path, []byte("198.51.100.0/24\n"), 0600)
+	os.Chtimes(path, later, later)
+	if read, err := f.readIfChanged(); err != nil || !read {
+		t.Fatalf("Expected changed file to be read, got %t, %v", read, err)
+	}
+	if f.contains(net.ParseIP("192.0.2.10")) || !f.contains(net.ParseIP("198.51.100.1")) {
+		t.Errorf("Expected new contents")
+	}
+}
@@ -2,6 +2,7 @@ package acl
 
 import (
 	"net"
+	"path/filepath"
 	"strings"
 
 	"github.com/coredns/caddy"
@@ -31,7 +32,27 @@ func setup(c *caddy.Controller) error {
 		return plugin.Error(pluginName, err)
 	}
 
-	dnsserver.GetConfig(c).AddPlugin(func(next plugin.Handler) plugin.Handler {
+	var files []*netFile
+	for _, r := range a.Rules {
+		for _, p := range r.policies {
+			files = append(files, p.files...)
+		}
+	}
+	if len(files) > 0 {
+		stop := make(chan struct{})
+		c.OnStartup(func() error {
+			go reloadNetFiles(files, stop)
+			return nil
+		})
+		c.OnShutdown(func() error {
+			close(stop)
+			return nil
+		})
+	}
+
+	config := dnsserver.GetConfig(c)
+	a.tsigSecret = func() map[string]string { return config.TsigSecret }
+	config.AddPlugin(func(next plugin.Handler) plugin.Handler {
 		a.Next = next
 		return a
 	})
@@ -40,6 +61,7 @@ func setup(c *caddy.Controller) error {
 }
 
 func parse(c *caddy.Controller) (ACL, error) {
+	config := dnsserver.GetConfig(c)
 	a := ACL{}
 	for c.Next() {
 		r := rule{}
@@ -71,7 +93,7 @@ func parse(c *caddy.Controller) (ACL, error) {
 			remainingTokens := c.RemainingArgs()
 			for len(remainingTokens) > 0 {
 				if !isPreservedIdentifier(remainingTokens[0]) {
-					return a, c.Errf("unexpected token %q; expect 'type | net'", remainingTokens[0])
+					return a, c.Errf("unexpected token %q; expect %s", remainingTokens[0], sectionNames)
 				}
 				section := strings.ToLower(remainingTokens[0])
 
@@ -114,8 +136,34 @@ func parse(c *caddy.Controller) (ACL, error) {
 						}
 						p.filter.InplaceInsertNet(source, struct{}{})
 					}
+				case "net-file":
+					hasNetSection = true
+					for _, token := range tokens {
+						if !filepath.IsAbs(token) && config.Root != "" {
+							token = filepath.Join(config.Root, token)
+						}
+						f, err := newNetFile(token)
+						if err != nil {
+							return a, c.Err(err.Error())
+						}
+						p.files = append(p.files, f)
+					}
+				case "tsig":
+					p.parseTSIG(tokens)
+				case "edns0":
+					if err := p.parseEDNS0(tokens); err != nil {
+						return a, c.Err(err.Error())
+					}
+				case "metadata":
+					if err := p.parseMetadata(tokens); err != nil {
+						return a, c.Err(err.Error())
+					}
+				case "expression":
+					if err := p.parseExpression(tokens); err != nil {
+						return a, c.Err(err.Error())
+					}
 				default:
-					return a, c.Errf("unexpected token %q; expect 'type | net'", section)
+					return a, c.Errf("unexpected token %q; expect %s", section, sectionNames)
 				}
 			}
 
@@ -136,9 +184,15 @@ func parse(c *caddy.Controller) (ACL, error) {
 	return a, nil
 }
 
+// sectionNames lists the sections of a policy, for error messages.
+const sectionNames = "'type | net | net-file | tsig | edns0 | metadata | expression'"
+
 func isPreservedIdentifier(token string) bool {
-	identifier := strings.ToLower(token)
-	return identifier == "type" || identifier == "net"
+	switch strings.ToLower(token) {
+	case "type", "net", "net-file", "tsig", "edns0", "metadata", "expression":
+		return true
+	}
+	return false
 }
 
 // normalize appends '/32' for any single IPv4 address and '/128' for IPv6.
@@ -227,6 +227,86 @@ func TestSetup(t *testing.T) {
 			}`,
 			true,
 		},
+		// Condition tests.
+		{
+			"TSIG 1",
+			`acl {
+				allow type AXFR IXFR tsig xfr.example.org. *
+				block type AXFR IXFR
+			}`,
+			false,
+		},
+		{
+			"EDNS0 1",
+			`acl {
+				block edns0 SUBNET 0xfffe 65001
+			}`,
+			false,
+		},
+		{
+			"Metadata 1",
+			`acl {
+				allow metadata geoip/country/code=NL geoip/country/code=BE
+				block
+			}`,
+			false,
+		},
+		{
+			"Expression 1",
+			`acl {
+				block type A expression "name() == 'a.example.org.' && incidr(client_ip(), '10.0.0.0/8')"
+			}`,
+			false,
+		},
+		{
+			"Illegal EDNS0 option",
+			`acl {
+				block edns0 NOSUCHOPTION
+			}`,
+			true,
+		},
+		{
+			"Empty tsig",
+			`acl {
+				allow type AXFR tsig
+			}`,
+			true,
+		},
+		{
+			"Empty tsig before type",
+			`acl {
+				allow tsig type AXFR
+			}`,
+			true,
+		},
+		{
+			"Empty EDNS0",
+			`acl {
+				block edns0
+			}`,
+			true,
+		},
+		{
+			"Illegal metadata",
+			`acl {
+				block metadata geoip/country/code
+			}`,
+			true,
+		},
+		{
+			"Illegal expression",
+			`acl {
+				block expression "nosuchfunc()"
+			}`,
+			true,
+		},
+		{
+			"Missing net-file",
+			`acl {
+				block net-file /does/not/exist
+			}`,
+			true,
+		},
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
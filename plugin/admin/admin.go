@@ -0,0 +1,312 @@
+// Package admin implements an HTTP API to inspect and change the state of the running server.
+package admin
+
+import (
+	"crypto/subtle"
+	"encoding/json"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/coredns/coredns/core/dnsserver"
+	"github.com/coredns/coredns/plugin/forward"
+	clog "github.com/coredns/coredns/plugin/pkg/log"
+	"github.com/coredns/coredns/plugin/pkg/reuseport"
+
+	"github.com/miekg/dns"
+)
+
+var log = clog.NewWithPlugin("admin")
+
+const defaultAddr = "localhost:8182"
+
+const (
+	readHeaderTimeout = 5 * time.Second
+	writeTimeout      = 30 * time.Second // reloading a large zone can take a while
+)
+
+// cacher is implemented by plugins that cache responses, such as cache.
+type cacher interface {
+	// Dump returns the responses cached for name.
+	Dump(name string) []*dns.Msg
+	// Flush removes the responses cached for name and returns the number removed.
+	Flush(name string) int
+}
+
+// throttler is implemented by plugins that rate limit clients, such as throttle.
+type throttler interface {
+	// Throttled returns the clients that are currently throttled, in a form that can be encoded as JSON.
+	Throttled() interface{}
+}
+
+// reloader is implemented by plugins that can reload their zones on request, such as file and hosts.
+type reloader interface {
+	// Reload reloads zone, or all zones if zone is empty, and returns the zones that were reloaded.
+	Reload(zone string) ([]string, error)
+}
+
+type handler struct {
+	addr    string
+	token   string
+	configs func() []*dnsserver.Config
+
+	srv *http.Server
+	mux *http.ServeMux
+}
+
+func (h *handler) Startup() error {
+	ln, err := reuseport.Listen("tcp", h.addr)
+	if err != nil {
+		log.Errorf("Failed to start admin handler: %s", err)
+		return err
+	}
+
+	h.mux = http.NewServeMux()
+	h.mux.HandleFunc("/servers", h.auth(h.servers))
+	h.mux.HandleFunc("/cache", h.auth(h.cache))
+	h.mux.HandleFunc("/forward", h.auth(h.forward))
+	h.mux.HandleFunc("/reload", h.auth(h.reload))
+	h.mux.HandleFunc("/throttle", h.auth(h.throttle))
+
+	srv := &http.Server{Handler: h.mux, ReadHeaderTimeout: readHeaderTimeout, WriteTimeout: writeTimeout}
+	h.srv = srv
+	go func() { srv.Serve(ln) }()
+	return nil
+}
+
+func (h *handler) Shutdown() error {
+	if h.srv != nil {
+		return h.srv.Close()
+	}
+	return nil
+}
+
+// auth only calls next when the request carries the configured bearer token.
+func (h *handler) auth(next http.HandlerFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		header := r.Header.Get("Authorization")
+		if !strings.HasPrefix(header, "Bearer ") || subtle.ConstantTimeCompare([]byte(header[len("Bearer "):]), []byte(h.token)) != 1 {
+			w.Header().Set("WWW-Authenticate", "Bearer")
+			http.Error(w, http.StatusText(http.StatusUnauthorized), http.StatusUnauthorized)
+			return
+		}
+		next(w, r)
+	}
+}
+
+type server struct {
+	Zone      string   `json:"zone"`
+	Transport string   `json:"transport"`
+	Listen    []string `json:"listen"`
+	Port      string   `json:"port"`
+	View      string   `json:"view,omitempty"`
+	Plugins   []string `json:"plugins"`
+}
+
+// servers lists the server blocks and their plugin chains.
+func (h *handler) servers(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, http.StatusText(http.StatusMethodNotAllowed), http.StatusMethodNotAllowed)
+		return
+	}
+	servers := []server{}
+	for _, cfg := range h.configs() {
+		s := server{
+			Zone:      cfg.Zone,
+			Transport: cfg.Transport,
+			Listen:    cfg.ListenHosts,
+			Port:      cfg.Port,
+			View:      cfg.ViewName,
+			Plugins:   []string{},
+		}
+		for _, d := range dnsserver.Directives {
+			if cfg.Handler(d) != nil {
+				s.Plugins = append(s.Plugins, d)
+			}
+		}
+		servers = append(servers, s)
+	}
+	writeJSON(w, servers)
+}
+
+type cacheEntry struct {
+	Zone   string   `json:"zone"`
+	Name   string   `json:"name"`
+	Type   string   `json:"type"`
+	Rcode  string   `json:"rcode"`
+	Answer []string `json:"answer"`
+	Ns     []string `json:"ns"`
+	Extra  []string `json:"extra"`
+}
+
+// cache dumps (GET) or flushes (DELETE) the cache entries for the name given in the name parameter.
+func (h *handler) cache(w http.ResponseWriter, r *http.Request) {
+	name := r.URL.Query().Get("name")
+	if _, ok := dns.IsDomainName(name); name == "" || !ok {
+		http.Error(w, "missing or invalid name parameter", http.StatusBadRequest)
+		return
+	}
+	name = dns.Fqdn(name)
+
+	switch r.Method {
+	case http.MethodGet:
+		entries := []cacheEntry{}
+		seen := map[cacher]struct{}{}
+		for _, cfg := range h.configs() {
+			c, ok := cfg.Handler("cache").(cacher)
+			if !ok {
+				continue
+			}
+			if _, ok := seen[c]; ok {
+				continue
+			}
+			seen[c] = struct{}{}
+			for _, m := range c.Dump(name) {
+				entries = append(entries, cacheEntry{
+					Zone:   cfg.Zone,
+					Name:   m.Question[0].Name,
+					Type:   dns.TypeToString[m.Question[0].Qtype],
+					Rcode:  dns.RcodeToString[m.Rcode],
+					Answer: rrStrings(m.Answer),
+					Ns:     rrStrings(m.Ns),
+					Extra:  rrStrings(m.Extra),
+				})
+			}
+		}
+		writeJSON(w, entries)
+
+	case http.MethodDelete:
+		n := 0
+		for _, cfg := range h.configs() {
+			if c, ok := cfg.Handler("cache").(cacher); ok {
+				n += c.Flush(name)
+			}
+		}
+		log.Infof("Flushed %d cache entries for %q", n, name)
+		writeJSON(w, map[string]int{"flushed": n})
+
+	default:
+		http.Error(w, http.StatusText(http.StatusMethodNotAllowed), http.StatusMethodNotAllowed)
+	}
+}
+
+type upstream struct {
+	Addr    string `json:"addr"`
+	Fails   uint32 `json:"fails"`
+	Healthy bool   `json:"healthy"`
+}
+
+type forwarder struct {
+	Zone      string     `json:"zone"`
+	From      string     `json:"from"`
+	MaxFails  uint32     `json:"max_fails"`
+	Upstreams []upstream `json:"upstreams"`
+}
+
+// forward shows the health of the upstreams of every forward plugin.
+func (h *handler) forward(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, http.StatusText(http.StatusMethodNotAllowed), http.StatusMethodNotAllowed)
+		return
+	}
+	forwarders := []forwarder{}
+	seen := map[*forward.Forward]struct{}{}
+	for _, cfg := range h.configs() {
+		f, ok := cfg.Handler("forward").(*forward.Forward)
+		if !ok {
+			continue
+		}
+		if _, ok := seen[f]; ok {
+			continue
+		}
+		seen[f] = struct{}{}
+
+		fw := forwarder{Zone: cfg.Zone, From: f.From(), MaxFails: f.MaxFails(), Upstreams: []upstream{}}
+		for _, p := range f.Proxies() {
+			fw.Upstreams = append(fw.Upstreams, upstream{
+				Addr:    p.Addr(),
+				Fails:   p.Fails(),
+				Healthy: !p.Down(f.MaxFails()),
+			})
+		}
+		forwarders = append(forwarders, fw)
+	}
+	writeJSON(w, forwarders)
+}
+
+// reload reloads the zones of all plugins that support it, or only the zone given in the zone parameter.
+func (h *handler) reload(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, http.StatusText(http.StatusMethodNotAllowed), http.StatusMethodNotAllowed)
+		return
+	}
+	zone := r.URL.Query().Get("zone")
+
+	reloaded := []string{}
+	seen := map[string]struct{}{}
+	for _, cfg := range h.configs() {
+		for _, hd := range cfg.Handlers() {
+			rl, ok := hd.(reloader)
+			if !ok {
+				continue
+			}
+			zones, err := rl.Reload(zone)
+			if err != nil {
+				http.Error(w, hd.Name()+": "+err.Error(), http.StatusInternalServerError)
+				return
+			}
+			for _, z := range zones {
+				if _, ok := seen[z]; ok {
+					continue
+				}
+				seen[z] = struct{}{}
+				reloaded = append(reloaded, z)
+			}
+		}
+	}
+	log.Infof("Reloaded zones: %v", reloaded)
+	writeJSON(w, map[string][]string{"reloaded": reloaded})
+}
+
+func rrStrings(rrs []dns.RR) []string {
+	s := make([]string, len(rrs))
+	for i := range rrs {
+		s[i] = rrs[i].String()
+	}
+	return s
+}
+
+func writeJSON(w http.ResponseWriter, v interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(v); err != nil {
+		log.Errorf("Failed to encode response: %s", err)
+	}
+}
+
+type throttled struct {
+	Zone    string      `json:"zone"`
+	Clients interface{} `json:"clients"`
+}
+
+// throttle lists the clients that are currently throttled by every throttle plugin.
+func (h *handler) throttle(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, http.StatusText(http.StatusMethodNotAllowed), http.StatusMethodNotAllowed)
+		return
+	}
+	throttlers := []throttled{}
+	seen := map[throttler]struct{}{}
+	for _, cfg := range h.configs() {
+		t, ok := cfg.Handler("throttle").(throttler)
+		if !ok {
+			continue
+		}
+		if _, ok := seen[t]; ok {
+			continue
+		}
+		seen[t] = struct{}{}
+
+		throttlers = append(throttlers, throttled{Zone: cfg.Zone, Clients: t.Throttled()})
+	}
+	writeJSON(w, throttlers)
+}
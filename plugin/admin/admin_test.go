@@ -0,0 +1,269 @@
+package admin
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+
+	"github.com/coredns/coredns/core/dnsserver"
+	"github.com/coredns/coredns/plugin"
+	"github.com/coredns/coredns/plugin/cache"
+	"github.com/coredns/coredns/plugin/forward"
+	"github.com/coredns/coredns/plugin/pkg/dnstest"
+	"github.com/coredns/coredns/plugin/test"
+	"github.com/coredns/coredns/plugin/throttle"
+
+	"github.com/miekg/dns"
+)
+
+func newTestHandler(t *testing.T) (*handler, *cache.Cache) {
+	c := cache.New()
+	c.Next = test.HandlerFunc(func(ctx context.Context, w dns.ResponseWriter, r *dns.Msg) (int, error) {
+		m := new(dns.Msg)
+		m.SetReply(r)
+		m.Response, m.RecursionAvailable = true, true
+		m.Answer = []dns.RR{test.A(r.Question[0].Name + " 300 IN A 127.0.0.1")}
+		w.WriteMsg(m)
+		return dns.RcodeSuccess, nil
+	})
+
+	f := forward.New()
+	f.SetProxy(forward.NewProxy("127.0.0.1:53", "dns"))
+
+	cfg := &dnsserver.Config{Zone: "example.org.", Port: "53", Transport: "dns", ListenHosts: []string{""}}
+	cfg.AddPlugin(func(next plugin.Handler) plugin.Handler { return c })
+	cfg.AddPlugin(func(next plugin.Handler) plugin.Handler { return f })
+	cfg.AddPlugin(func(next plugin.Handler) plugin.Handler { return testReloader{"example.org.", "example.net."} })
+	if _, err := dnsserver.NewServer("dns://:53", []*dnsserver.Config{cfg}); err != nil {
+		t.Fatal(err)
+	}
+
+	h := &handler{token: "s3cret", configs: func() []*dnsserver.Config { return []*dnsserver.Config{cfg} }}
+	h.mux = http.NewServeMux()
+	h.mux.HandleFunc("/servers", h.auth(h.servers))
+	h.mux.HandleFunc("/cache", h.auth(h.cache))
+	h.mux.HandleFunc("/forward", h.auth(h.forward))
+	h.mux.HandleFunc("/reload", h.auth(h.reload))
+	h.mux.HandleFunc("/throttle", h.auth(h.throttle))
+	return h, c
+}
+
+type testReloader []string
+
+func (r testReloader) ServeDNS(ctx context.Context, w dns.ResponseWriter, m *dns.Msg) (int, error) {
+	return dns.RcodeServerFailure, nil
+}
+
+func (r testReloader) Name() string { return "testreloader" }
+
+func (r testReloader) Reload(zone string) ([]string, error) {
+	if zone == "" {
+		return r, nil
+	}
+	for _, z := range r {
+		if z == zone {
+			return []string{z}, nil
+		}
+	}
+	return nil, nil
+}
+
+func do(h *handler, method, target, token string) *httptest.ResponseRecorder {
+	r := httptest.NewRequest(method, target, nil)
+	if token != "" {
+		r.Header.Set("Authorization", "Bearer "+token)
+	}
+	w := httptest.NewRecorder()
+	h.mux.ServeHTTP(w, r)
+	return w
+}
+
+func TestAuth(t *testing.T) {
+	h, _ := newTestHandler(t)
+
+	for _, token := range []string{"", "wrong"} {
+		if w := do(h, http.MethodGet, "/servers", token); w.Code != http.StatusUnauthorized {
+			t.Errorf("Expected status %d with token %q, got %d", http.StatusUnauthorized, token, w.Code)
+		}
+	}
+	if w := do(h, http.MethodGet, "/servers", "s3cret"); w.Code != http.StatusOK {
+		t.Errorf("Expected status %d, got %d", http.StatusOK, w.Code)
+	}
+
+	// The token must be sent as a bearer token.
+	for _, header := range []string{"s3cret", "Basic s3cret", "Bearers3cret"} {
+		r := httptest.NewRequest(http.MethodGet, "/servers", nil)
+		r.Header.Set("Authorization", header)
+		w := httptest.NewRecorder()
+		h.mux.ServeHTTP(w, r)
+		if w.Code != http.StatusUnauthorized {
+			t.Errorf("Expected status %d with header %q, got %d", http.StatusUnauthorized, header, w.Code)
+		}
+	}
+}
+
+func TestServers(t *testing.T) {
+	h, _ := newTestHandler(t)
+
+	w := do(h, http.MethodGet, "/servers", "s3cret")
+	var servers []server
+	if err := json.NewDecoder(w.Body).Decode(&servers); err != nil {
+		t.Fatal(err)
+	}
+	if len(servers) != 1 {
+		t.Fatalf("Expected 1 server, got %d", len(servers))
+	}
+	s := servers[0]
+	if s.Zone != "example.org." || s.Port != "53" || s.Transport != "dns" {
+		t.Errorf("Unexpected server %+v", s)
+	}
+	if len(s.Plugins) != 2 || s.Plugins[0] != "cache" || s.Plugins[1] != "forward" {
+		t.Errorf("Expected plugins [cache forward], got %v", s.Plugins)
+	}
+
+	if w := do(h, http.MethodPost, "/servers", "s3cret"); w.Code != http.StatusMethodNotAllowed {
+		t.Errorf("Expected status %d, got %d", http.StatusMethodNotAllowed, w.Code)
+	}
+}
+
+func TestCache(t *testing.T) {
+	h, c := newTestHandler(t)
+
+	for _, name := range []string{"a.example.org.", "b.example.org."} {
+		req := new(dns.Msg)
+		req.SetQuestion(name, dns.TypeA)
+		c.ServeDNS(context.TODO(), dnstest.NewRecorder(&test.ResponseWriter{}), req)
+	}
+
+	w := do(h, http.MethodGet, "/cache?name=A.example.org", "s3cret")
+	var entries []cacheEntry
+	if err := json.NewDecoder(w.Body).Decode(&entries); err != nil {
+		t.Fatal(err)
+	}
+	if len(entries) != 1 {
+		t.Fatalf("Expected 1 cache entry, got %d", len(entries))
+	}
+	if e := entries[0]; e.Name != "a.example.org." || e.Type != "A" || e.Rcode != "NOERROR" || len(e.Answer) != 1 {
+		t.Errorf("Unexpected cache entry %+v", e)
+	}
+
+	w = do(h, http.MethodDelete, "/cache?name=a.example.org.", "s3cret")
+	var flushed map[string]int
+	if err := json.NewDecoder(w.Body).Decode(&flushed); err != nil {
+		t.Fatal(err)
+	}
+	if flushed["flushed"] != 1 {
+		t.Errorf("Expected 1 flushed entry, got %d", flushed["flushed"])
+	}
+
+	w = do(h, http.MethodGet, "/cache?name=a.example.org.", "s3cret")
+	entries = nil
+	if err := json.NewDecoder(w.Body).Decode(&entries); err != nil {
+		t.Fatal(err)
+	}
+	if len(entries) != 0 {
+		t.Errorf("Expected no cache entries after flush, got %d", len(entries))
+	}
+	w = do(h, http.MethodGet, "/cache?name=b.example.org.", "s3cret")
+	if err := json.NewDecoder(w.Body).Decode(&entries); err != nil {
+		t.Fatal(err)
+	}
+	if len(entries) != 1 {
+		t.Errorf("Expected b.example.org. to still be cached, got %d entries", len(entries))
+	}
+
+	if w := do(h, http.MethodGet, "/cache", "s3cret"); w.Code != http.StatusBadRequest {
+		t.Errorf("Expected status %d, got %d", http.StatusBadRequest, w.Code)
+	}
+}
+
+func TestForward(t *testing.T) {
+	h, _ := newTestHandler(t)
+
+	w := do(h, http.MethodGet, "/forward", "s3cret")
+	var forwarders []forwarder
+	if err := json.NewDecoder(w.Body).Decode(&forwarders); err != nil {
+		t.Fatal(err)
+	}
+	if len(forwarders) != 1 {
+		t.Fatalf("Expected 1 forwarder, got %d", len(forwarders))
+	}
+	fw := forwarders[0]
+	if len(fw.Upstreams) != 1 {
+		t.Fatalf("Expected 1 upstream, got %d", len(fw.Upstreams))
+	}
+	if u := fw.Upstreams[0]; u.Addr != "127.0.0.1:53" || u.Fails != 0 || !u.Healthy {
+		t.Errorf("Unexpected upstream %+v", u)
+	}
+}
+
+func TestReload(t *testing.T) {
+	h, _ := newTestHandler(t)
+
+	if w := do(h, http.MethodGet, "/reload", "s3cret"); w.Code != http.StatusMethodNotAllowed {
+		t.Errorf("Expected status %d, got %d", http.StatusMethodNotAllowed, w.Code)
+	}
+	tests := []struct {
+		zone     string
+		expected []string
+	}{
+		{"", []string{"example.org.", "example.net."}},
+		{"example.net.", []string{"example.net."}},
+		{"example.com.", []string{}},
+	}
+	for i, tc := range tests {
+		w := do(h, http.MethodPost, "/reload?zone="+tc.zone, "s3cret")
+		if w.Code != http.StatusOK {
+			t.Fatalf("Test %d: Expected status %d, got %d", i, http.StatusOK, w.Code)
+		}
+		var reloaded map[string][]string
+		if err := json.NewDecoder(w.Body).Decode(&reloaded); err != nil {
+			t.Fatal(err)
+		}
+		if !reflect.DeepEqual(reloaded["reloaded"], tc.expected) {
+			t.Errorf("Test %d: Expected reloaded zones %v, got %v", i, tc.expected, reloaded["reloaded"])
+		}
+	}
+}
+
+func TestThrottle(t *testing.T) {
+	h, _ := newTestHandler(t)
+
+	th := throttle.New()
+	th.Zones = []string{"example.org."}
+	th.Next = test.NextHandler(dns.RcodeSuccess, nil)
+	cfg := &dnsserver.Config{Zone: "example.org.", Port: "53", Transport: "dns", ListenHosts: []string{""}}
+	cfg.AddPlugin(func(next plugin.Handler) plugin.Handler { return th })
+	if _, err := dnsserver.NewServer("dns://:53", []*dnsserver.Config{cfg}); err != nil {
+		t.Fatal(err)
+	}
+	h.configs = func() []*dnsserver.Config { return []*dnsserver.Config{cfg} }
+
+	m := new(dns.Msg)
+	m.SetQuestion("example.org.", dns.TypeA)
+	for i := 0; i < 110; i++ {
+		th.ServeDNS(context.TODO(), dnstest.NewRecorder(&test.ResponseWriter{}), m)
+	}
+
+	w := do(h, http.MethodGet, "/throttle", "s3cret")
+	var throttlers []struct {
+		Zone    string            `json:"zone"`
+		Clients []throttle.Client `json:"clients"`
+	}
+	if err := json.NewDecoder(w.Body).Decode(&throttlers); err != nil {
+		t.Fatal(err)
+	}
+	if len(throttlers) != 1 {
+		t.Fatalf("Expected 1 throttler, got %d", len(throttlers))
+	}
+	clients := throttlers[0].Clients
+	if len(clients) != 1 {
+		t.Fatalf("Expected 1 throttled client, got %d", len(clients))
+	}
+	if c := clients[0]; c.Key != "10.240.0.1" || c.Action != "refuse" || c.Queries == 0 {
+		t.Errorf("Unexpected throttled client %+v", c)
+	}
+}
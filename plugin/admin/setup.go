@@ -0,0 +1,65 @@
+package admin
+
+import (
+	"net"
+
+	"github.com/coredns/caddy"
+	"github.com/coredns/coredns/core/dnsserver"
+	"github.com/coredns/coredns/plugin"
+)
+
+func init() { plugin.Register("admin", setup) }
+
+func setup(c *caddy.Controller) error {
+	h, err := parse(c)
+	if err != nil {
+		return plugin.Error("admin", err)
+	}
+	h.configs = func() []*dnsserver.Config { return dnsserver.GetConfigs(c) }
+
+	c.OnStartup(h.Startup)
+	c.OnShutdown(h.Shutdown)
+	return nil
+}
+
+func parse(c *caddy.Controller) (*handler, error) {
+	h := &handler{addr: defaultAddr}
+
+	i := 0
+	for c.Next() {
+		if i > 0 {
+			return nil, plugin.ErrOnce
+		}
+		i++
+
+		args := c.RemainingArgs()
+		if len(args) > 1 {
+			return nil, c.ArgErr()
+		}
+		if len(args) == 1 {
+			h.addr = args[0]
+			if _, _, err := net.SplitHostPort(h.addr); err != nil {
+				return nil, c.Errf("%v", err)
+			}
+		}
+
+		for c.NextBlock() {
+			switch c.Val() {
+			case "token":
+				if !c.NextArg() {
+					return nil, c.ArgErr()
+				}
+				h.token = c.Val()
+				if c.NextArg() {
+					return nil, c.ArgErr()
+				}
+			default:
+				return nil, c.Errf("unknown property '%s'", c.Val())
+			}
+		}
+	}
+	if h.token == "" {
+		return nil, c.Err("a token is required")
+	}
+	return h, nil
+}
@@ -0,0 +1,65 @@
+package admin
+
+import (
+	"testing"
+
+	"github.com/coredns/caddy"
+)
+
+func TestSetup(t *testing.T) {
+	tests := []struct {
+		input     string
+		shouldErr bool
+		addr      string
+		token     string
+	}{
+		{`admin {
+			token s3cret
+		}`, false, defaultAddr, "s3cret"},
+		{`admin :9182 {
+			token s3cret
+		}`, false, ":9182", "s3cret"},
+		{`admin`, true, "", ""},
+		{`admin {
+			token
+		}`, true, "", ""},
+		{`admin {
+			token a b
+		}`, true, "", ""},
+		{`admin /foo {
+			token s3cret
+		}`, true, "", ""},
+		{`admin :9182 :9183 {
+			token s3cret
+		}`, true, "", ""},
+		{`admin {
+			tokens s3cret
+		}`, true, "", ""},
+		{`admin {
+			token s3cret
+		}
+		admin {
+			token s3cret
+		}`, true, "", ""},
+	}
+	for i, test := range tests {
+		c := caddy.NewTestController("dns", test.input)
+		h, err := parse(c)
+		if test.shouldErr && err == nil {
+			t.Errorf("Test %d: Expected error but found nil", i)
+			continue
+		} else if !test.shouldErr && err != nil {
+			t.Errorf("Test %d: Expected no error but found error: %v", i, err)
+			continue
+		}
+		if test.shouldErr {
+			continue
+		}
+		if h.addr != test.addr {
+			t.Errorf("Test %d: Expected address %q, got %q", i, test.addr, h.addr)
+		}
+		if h.token != test.token {
+			t.Errorf("Test %d: Expected token %q, got %q", i, test.token, h.token)
+		}
+	}
+}
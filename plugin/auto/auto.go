@@ -7,6 +7,7 @@ import (
 	"time"
 
 	"github.com/coredns/coredns/plugin"
+	"github.com/coredns/coredns/plugin/dnstap"
 	"github.com/coredns/coredns/plugin/file"
 	"github.com/coredns/coredns/plugin/metrics"
 	"github.com/coredns/coredns/plugin/pkg/upstream"
@@ -24,6 +25,7 @@ type (
 
 		metrics  *metrics.Metrics
 		transfer *transfer.Transfer
+		tap      *dnstap.Taps
 		loader
 	}
 
@@ -34,12 +36,14 @@ type (
 
 		ReloadInterval time.Duration
 		upstream       *upstream.Upstream // Upstream for looking up names during the resolution process.
+		validation     file.Strictness    // What to do with the problems found in a zone.
 	}
 )
 
 // ServeDNS implements the plugin.Handler interface.
 func (a Auto) ServeDNS(ctx context.Context, w dns.ResponseWriter, r *dns.Msg) (int, error) {
 	state := request.Request{W: w, Req: r}
+	start := time.Now()
 	qname := state.Name()
 
 	// Precheck with the origins, i.e. are we allowed to look here?
@@ -92,6 +96,7 @@ func (a Auto) ServeDNS(ctx context.Context, w dns.ResponseWriter, r *dns.Msg) (i
 		m.Rcode = dns.RcodeServerFailure
 	}
 
+	a.tap.TapAuth(ctx, state, m, start)
 	w.WriteMsg(m)
 	return dns.RcodeSuccess, nil
 }
@@ -0,0 +1,12 @@
+package auto
+
+// Ready implements the ready.Readiness interface. Auto is ready when the directory has been
+// scanned for zones once.
+func (a Auto) Ready() bool {
+	a.Zones.RLock()
+	defer a.Zones.RUnlock()
+	return a.Zones.walked
+}
+
+// NotReady implements the ready.Reporter interface.
+func (a Auto) NotReady() string { return "directory " + a.loader.directory + " not scanned yet" }
@@ -10,6 +10,8 @@ import (
 	"github.com/coredns/caddy"
 	"github.com/coredns/coredns/core/dnsserver"
 	"github.com/coredns/coredns/plugin"
+	"github.com/coredns/coredns/plugin/dnstap"
+	"github.com/coredns/coredns/plugin/file"
 	"github.com/coredns/coredns/plugin/metrics"
 	clog "github.com/coredns/coredns/plugin/pkg/log"
 	"github.com/coredns/coredns/plugin/pkg/upstream"
@@ -38,6 +40,8 @@ func setup(c *caddy.Controller) error {
 		return nil
 	})
 
+	a.tap = dnstap.NewTaps(c)
+
 	walkChan := make(chan bool)
 
 	c.OnStartup(func() error {
@@ -152,6 +156,17 @@ func autoParse(c *caddy.Controller) (Auto, error) {
 				// remove soon
 				c.RemainingArgs() // eat remaining args
 
+			case "validate":
+				t := c.RemainingArgs()
+				if len(t) != 1 {
+					return a, c.ArgErr()
+				}
+				v, err := file.ParseStrictness(t[0])
+				if err != nil {
+					return a, c.Err(err.Error())
+				}
+				a.loader.validation = v
+
 			default:
 				return Auto{}, c.Errf("unknown property '%s'", c.Val())
 			}
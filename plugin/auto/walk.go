@@ -49,9 +49,14 @@ func (a Auto) Walk() error {
 			log.Warningf("Parse zone `%s': %v", origin, err)
 			return nil
 		}
+		if err := zo.Validate(a.loader.validation, -1); err != nil {
+			log.Warningf("Not loading zone `%s': %v", origin, err)
+			return nil
+		}
 
 		zo.ReloadInterval = a.loader.ReloadInterval
 		zo.Upstream = a.loader.upstream
+		zo.Validation = a.loader.validation
 
 		a.Zones.Add(zo, origin, a.transfer)
 
@@ -82,6 +87,10 @@ func (a Auto) Walk() error {
 		log.Infof("Deleting zone `%s'", origin)
 	}
 
+	a.Zones.Lock()
+	a.Zones.walked = true
+	a.Zones.Unlock()
+
 	return nil
 }
 
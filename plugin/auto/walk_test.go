@@ -38,8 +38,16 @@ func TestWalk(t *testing.T) {
 		Zones:  &Zones{},
 	}
 
+	if a.Ready() {
+		t.Errorf("Expected auto not to be ready before the first walk")
+	}
+
 	a.Walk()
 
+	if !a.Ready() {
+		t.Errorf("Expected auto to be ready after the first walk")
+	}
+
 	// db.example.org and db.example.com should be here (created in createFiles)
 	for _, name := range []string{"example.com.", "example.org."} {
 		if _, ok := a.Zones.Z[name]; !ok {
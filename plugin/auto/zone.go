@@ -5,6 +5,7 @@ import (
 	"sync"
 
 	"github.com/coredns/coredns/plugin/file"
+	"github.com/coredns/coredns/plugin/pkg/watch"
 	"github.com/coredns/coredns/plugin/transfer"
 )
 
@@ -15,8 +16,10 @@ type Zones struct {
 	names []string              // All the keys from the map Z as a string slice.
 
 	origins []string // Any origins from the server block.
+	walked  bool     // Set when the directory has been walked once.
 
 	sync.RWMutex
+	watch.Notifier // signals zones that are added, removed or changed
 }
 
 // Names returns the names from z.
@@ -52,9 +55,11 @@ func (z *Zones) Add(zo *file.Zone, name string, t *transfer.Transfer) {
 
 	z.Z[name] = zo
 	z.names = append(z.names, name)
+	zo.Watch(z.Changed)
 	zo.Reload(t)
 
 	z.Unlock()
+	z.Changed(name)
 }
 
 // Remove removes the zone named name from z. It also stops the zone's reload goroutine.
@@ -74,4 +79,5 @@ func (z *Zones) Remove(name string) {
 	}
 
 	z.Unlock()
+	z.Changed(name)
 }
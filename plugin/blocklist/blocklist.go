@@ -0,0 +1,139 @@
+// Package blocklist implements a plugin that blocks names found in block lists.
+package blocklist
+
+import (
+	"context"
+	"net"
+
+	"github.com/coredns/coredns/plugin"
+	"github.com/coredns/coredns/plugin/metrics"
+	"github.com/coredns/coredns/plugin/pkg/dnsutil"
+	clog "github.com/coredns/coredns/plugin/pkg/log"
+	"github.com/coredns/coredns/request"
+
+	"github.com/miekg/dns"
+)
+
+var log = clog.NewWithPlugin("blocklist")
+
+// action defines the response sent for blocked names.
+type action int
+
+const (
+	// actionNXDomain answers with NXDOMAIN.
+	actionNXDomain action = iota
+	// actionNoData answers with an empty NOERROR response.
+	actionNoData
+	// actionNullIP answers A and AAAA queries with the unspecified address, other types get NODATA.
+	actionNullIP
+	// actionRefused answers with REFUSED.
+	actionRefused
+)
+
+// Blocklist blocks the names found in its block lists, unless they are also found in one of its allow lists.
+type Blocklist struct {
+	Next plugin.Handler
+
+	Zones []string
+	block []*list
+	allow []*list
+
+	action action
+	ttl    uint32
+}
+
+// ServeDNS implements the plugin.Handler interface.
+func (b *Blocklist) ServeDNS(ctx context.Context, w dns.ResponseWriter, r *dns.Msg) (int, error) {
+	state := request.Request{W: w, Req: r}
+	qname := state.Name()
+	zone := plugin.Zones(b.Zones).Matches(qname)
+	if zone == "" {
+		return plugin.NextOrFailure(b.Name(), b.Next, ctx, w, r)
+	}
+
+	l := b.match(qname)
+	if l == nil {
+		return plugin.NextOrFailure(b.Name(), b.Next, ctx, w, r)
+	}
+
+	hitCount.WithLabelValues(metrics.WithServer(ctx), l.source, metrics.WithView(ctx)).Inc()
+
+	m := new(dns.Msg)
+	switch b.action {
+	case actionRefused:
+		m.SetRcode(r, dns.RcodeRefused)
+	case actionNXDomain:
+		m.SetRcode(r, dns.RcodeNameError)
+		m.Ns = b.soa(zone)
+	case actionNoData:
+		m.SetReply(r)
+		m.Ns = b.soa(zone)
+	case actionNullIP:
+		m.SetReply(r)
+		hdr := dns.RR_Header{Name: qname, Rrtype: state.QType(), Class: dns.ClassINET, Ttl: b.ttl}
+		switch state.QType() {
+		case dns.TypeA:
+			m.Answer = []dns.RR{&dns.A{Hdr: hdr, A: net.IPv4zero}}
+		case dns.TypeAAAA:
+			m.Answer = []dns.RR{&dns.AAAA{Hdr: hdr, AAAA: net.IPv6zero}}
+		default:
+			m.Ns = b.soa(zone)
+		}
+	}
+	m.Authoritative = true
+	w.WriteMsg(m)
+	return dns.RcodeSuccess, nil
+}
+
+// soa returns a synthesized SOA record for zone, for the authority section of negative answers, so that
+// they can be cached (RFC 2308).
+func (b *Blocklist) soa(zone string) []dns.RR {
+	hdr := dns.RR_Header{Name: zone, Rrtype: dns.TypeSOA, Class: dns.ClassINET, Ttl: b.ttl}
+	return []dns.RR{&dns.SOA{
+		Hdr:     hdr,
+		Ns:      dnsutil.Join("ns.dns", zone),
+		Mbox:    dnsutil.Join("hostmaster", zone),
+		Serial:  1,
+		Refresh: 7200,
+		Retry:   1800,
+		Expire:  86400,
+		Minttl:  b.ttl,
+	}}
+}
+
+// match returns the block list that contains qname, or nil if the name is not blocked or allowed.
+func (b *Blocklist) match(qname string) *list {
+	for _, l := range b.allow {
+		if l.Match(qname) {
+			return nil
+		}
+	}
+	for _, l := range b.block {
+		if l.Match(qname) {
+			return l
+		}
+	}
+	return nil
+}
+
+// Name implements the plugin.Handler interface.
+func (b *Blocklist) Name() string { return "blocklist" }
+
+// load (re)loads all lists. Lists that fail to load keep their previous content.
+func (b *Blocklist) load() {
+	for _, l := range b.lists() {
+		if err := l.Load(); err != nil {
+			fetchFailureCount.WithLabelValues(l.source).Inc()
+			log.Warningf("Failed to load %q: %s", l.source, err)
+			continue
+		}
+		listEntries.WithLabelValues(l.source).Set(float64(l.Len()))
+	}
+}
+
+// lists returns the allow and block lists.
+func (b *Blocklist) lists() []*list {
+	ls := make([]*list, 0, len(b.allow)+len(b.block))
+	ls = append(ls, b.allow...)
+	return append(ls, b.block...)
+}
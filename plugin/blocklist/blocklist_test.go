@@ -0,0 +1,141 @@
+package blocklist
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+
+	"github.com/coredns/coredns/plugin/pkg/dnstest"
+	"github.com/coredns/coredns/plugin/test"
+
+	"github.com/miekg/dns"
+)
+
+func TestBlocklist(t *testing.T) {
+	blockFile, rm, err := test.TempFile(".", "0.0.0.0 ads.example.org\n*.tracker.example.org\n")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer rm()
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte("||example.net^\n"))
+	}))
+	defer srv.Close()
+
+	allowFile, rm2, err := test.TempFile(".", "www.example.net\n")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer rm2()
+
+	b := &Blocklist{Next: test.NextHandler(dns.RcodeSuccess, nil), Zones: []string{"."}, ttl: 3600}
+	for _, s := range []string{"file://" + blockFile, srv.URL} {
+		l, err := newList(s, "", false)
+		if err != nil {
+			t.Fatal(err)
+		}
+		b.block = append(b.block, l)
+	}
+	l, err := newList(allowFile, "", true)
+	if err != nil {
+		t.Fatal(err)
+	}
+	b.allow = append(b.allow, l)
+
+	if b.Ready() {
+		t.Fatal("Expected not to be ready before loading")
+	}
+	b.load()
+	if !b.Ready() {
+		t.Fatal("Expected to be ready after loading")
+	}
+
+	tests := []struct {
+		qname   string
+		qtype   uint16
+		action  action
+		blocked bool
+		rcode   int
+		answer  string
+	}{
+		{"ads.example.org.", dns.TypeA, actionNXDomain, true, dns.RcodeNameError, ""},
+		{"example.org.", dns.TypeA, actionNXDomain, false, 0, ""},
+		{"tracker.example.org.", dns.TypeA, actionNXDomain, false, 0, ""},
+		{"a.tracker.example.org.", dns.TypeA, actionNXDomain, true, dns.RcodeNameError, ""},
+		{"example.net.", dns.TypeA, actionRefused, true, dns.RcodeRefused, ""},
+		{"a.example.net.", dns.TypeA, actionNoData, true, dns.RcodeSuccess, ""},
+		{"www.example.net.", dns.TypeA, actionNXDomain, false, 0, ""},
+		{"ads.example.org.", dns.TypeA, actionNullIP, true, dns.RcodeSuccess, "ads.example.org.\t3600\tIN\tA\t0.0.0.0"},
+		{"ads.example.org.", dns.TypeAAAA, actionNullIP, true, dns.RcodeSuccess, "ads.example.org.\t3600\tIN\tAAAA\t::"},
+		{"ads.example.org.", dns.TypeMX, actionNullIP, true, dns.RcodeSuccess, ""},
+	}
+
+	for i, tc := range tests {
+		b.action = tc.action
+		m := new(dns.Msg)
+		m.SetQuestion(tc.qname, tc.qtype)
+		rec := dnstest.NewRecorder(&test.ResponseWriter{})
+		b.ServeDNS(context.TODO(), rec, m)
+
+		if !tc.blocked {
+			if rec.Msg != nil {
+				t.Errorf("Test %d: expected %q not to be blocked, got %s", i, tc.qname, rec.Msg)
+			}
+			continue
+		}
+		if rec.Msg == nil {
+			t.Errorf("Test %d: expected %q to be blocked", i, tc.qname)
+			continue
+		}
+		if rec.Msg.Rcode != tc.rcode {
+			t.Errorf("Test %d: expected rcode %d, got %d", i, tc.rcode, rec.Msg.Rcode)
+		}
+		// Negative answers carry an SOA, so they can be cached.
+		negative := tc.action != actionRefused && tc.answer == ""
+		if negative && (len(rec.Msg.Ns) != 1 || rec.Msg.Ns[0].String() != ".\t3600\tIN\tSOA\tns.dns. hostmaster. 1 7200 1800 86400 3600") {
+			t.Errorf("Test %d: expected SOA in the authority section, got %v", i, rec.Msg.Ns)
+		}
+		if !negative && len(rec.Msg.Ns) != 0 {
+			t.Errorf("Test %d: expected no authority section, got %v", i, rec.Msg.Ns)
+		}
+		if tc.answer == "" {
+			if len(rec.Msg.Answer) != 0 {
+				t.Errorf("Test %d: expected no answer, got %v", i, rec.Msg.Answer)
+			}
+			continue
+		}
+		if len(rec.Msg.Answer) != 1 || rec.Msg.Answer[0].String() != tc.answer {
+			t.Errorf("Test %d: expected answer %q, got %v", i, tc.answer, rec.Msg.Answer)
+		}
+	}
+}
+
+func TestBlocklistKeepsList(t *testing.T) {
+	var fail int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if atomic.LoadInt32(&fail) == 1 {
+			w.WriteHeader(http.StatusInternalServerError)
+			return
+		}
+		w.Write([]byte("ads.example.org\n"))
+	}))
+	defer srv.Close()
+
+	l, err := newList(srv.URL, "", false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := l.Load(); err != nil {
+		t.Fatal(err)
+	}
+	atomic.StoreInt32(&fail, 1)
+	if err := l.Load(); err == nil {
+		t.Fatal("Expected error when loading list")
+	}
+	if !l.Match("ads.example.org.") {
+		t.Error("Expected previous list content to be kept")
+	}
+}
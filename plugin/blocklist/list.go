@@ -0,0 +1,236 @@
+package blocklist
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/miekg/dns"
+)
+
+// list is a block or allow list retrieved from a file or an HTTP(S) URL.
+type list struct {
+	source string // as given in the Corefile
+	allow  bool
+
+	path string // set for local files
+	url  string // set for http and https
+
+	sync.RWMutex
+	names  *trie
+	loaded bool
+}
+
+// fetchTimeout is the timeout for retrieving a list over HTTP.
+var fetchTimeout = 30 * time.Second
+
+var client = &http.Client{Timeout: fetchTimeout}
+
+// newList returns a list for source, which is a file:// URL, a http(s):// URL or a path. Relative paths
+// are relative to root.
+func newList(source, root string, allow bool) (*list, error) {
+	l := &list{source: source, allow: allow}
+
+	u, err := url.Parse(source)
+	if err != nil {
+		return nil, err
+	}
+	switch u.Scheme {
+	case "http", "https":
+		l.url = source
+		return l, nil
+	case "file":
+		l.path = u.Path
+		if u.Host != "" {
+			// file://relative/path
+			l.path = u.Host + u.Path
+		}
+	case "":
+		l.path = source
+	default:
+		return nil, fmt.Errorf("unsupported scheme %q in %q", u.Scheme, source)
+	}
+	if !filepath.IsAbs(l.path) && root != "" {
+		l.path = filepath.Join(root, l.path)
+	}
+	return l, nil
+}
+
+// Match returns true if name is in the list.
+func (l *list) Match(name string) bool {
+	l.RLock()
+	defer l.RUnlock()
+	if l.names == nil {
+		return false
+	}
+	return l.names.Match(name)
+}
+
+// Len returns the number of names in the list.
+func (l *list) Len() int {
+	l.RLock()
+	defer l.RUnlock()
+	if l.names == nil {
+		return 0
+	}
+	return l.names.Len()
+}
+
+// Loaded returns true if the list has been retrieved at least once.
+func (l *list) Loaded() bool {
+	l.RLock()
+	defer l.RUnlock()
+	return l.loaded
+}
+
+// Load retrieves and parses the list. If this fails the previous content of the list is kept.
+func (l *list) Load() error {
+	rc, err := l.open()
+	if err != nil {
+		return err
+	}
+	defer rc.Close()
+
+	names, err := parseList(rc, l.allow)
+	if err != nil {
+		return err
+	}
+
+	l.Lock()
+	l.names = names
+	l.loaded = true
+	l.Unlock()
+	return nil
+}
+
+func (l *list) open() (io.ReadCloser, error) {
+	if l.path != "" {
+		return os.Open(filepath.Clean(l.path))
+	}
+	resp, err := client.Get(l.url)
+	if err != nil {
+		return nil, err
+	}
+	if resp.StatusCode != http.StatusOK {
+		resp.Body.Close()
+		return nil, fmt.Errorf("unexpected status %q", resp.Status)
+	}
+	return resp.Body, nil
+}
+
+// parseList parses a list in hosts, AdBlock or plain domain format, the format is detected per line:
+//
+//	0.0.0.0 ads.example.org tracker.example.org   # hosts format, matches the names
+//	||ads.example.org^                            # AdBlock format, matches the name and all names below it
+//	@@||cdn.ads.example.org^                      # AdBlock exception, only used in allow lists
+//	ads.example.org                               # plain format, matches the name
+//	*.ads.example.org                             # plain format, matches all names below it
+//
+// Lines that can't be parsed are skipped. In an allow list AdBlock exceptions are read as normal entries.
+func parseList(r io.Reader, allow bool) (*trie, error) {
+	t := newTrie()
+	scanner := bufio.NewScanner(r)
+	for scanner.Scan() {
+		line := scanner.Text()
+		if strings.Contains(line, "##") || strings.Contains(line, "#@#") {
+			continue // AdBlock element hiding rule
+		}
+		if i := strings.IndexByte(line, '#'); i >= 0 {
+			line = line[:i]
+		}
+		line = strings.TrimSpace(line)
+		if line == "" || line[0] == '!' || line[0] == '[' {
+			continue
+		}
+
+		switch {
+		case strings.HasPrefix(line, "@@||"):
+			if allow {
+				addAdBlock(t, line[2:])
+			}
+		case strings.HasPrefix(line, "||"):
+			addAdBlock(t, line)
+		default:
+			fields := strings.Fields(line)
+			if len(fields) > 1 && net.ParseIP(fields[0]) != nil {
+				for _, f := range fields[1:] {
+					if _, ok := localNames[strings.ToLower(f)]; ok {
+						continue
+					}
+					if name, ok := normalize(f); ok {
+						t.Insert(name, true, false)
+					}
+				}
+				continue
+			}
+			if len(fields) != 1 {
+				continue
+			}
+			if strings.HasPrefix(fields[0], "*.") {
+				if name, ok := normalize(fields[0][2:]); ok {
+					t.Insert(name, false, true)
+				}
+				continue
+			}
+			if name, ok := normalize(fields[0]); ok {
+				t.Insert(name, true, false)
+			}
+		}
+	}
+	return t, scanner.Err()
+}
+
+// addAdBlock adds a basic AdBlock rule, "||name^", to t. Rules with options or paths are skipped.
+func addAdBlock(t *trie, rule string) {
+	rule = strings.TrimPrefix(rule, "||")
+	if !strings.HasSuffix(rule, "^") {
+		return
+	}
+	if name, ok := normalize(strings.TrimSuffix(rule, "^")); ok {
+		t.Insert(name, true, true)
+	}
+}
+
+// normalize returns the name lowercased and fully qualified, ok is false if the name is not a valid domain name.
+func normalize(name string) (string, bool) {
+	if name == "" || name == "." {
+		return "", false
+	}
+	name = strings.ToLower(name)
+	for _, c := range name {
+		if !(c >= 'a' && c <= 'z' || c >= '0' && c <= '9' || c == '-' || c == '_' || c == '.') {
+			return "", false
+		}
+	}
+	if _, ok := dns.IsDomainName(name); !ok {
+		return "", false
+	}
+	if net.ParseIP(name) != nil {
+		return "", false
+	}
+	return dns.Fqdn(name), true
+}
+
+// localNames are names commonly found in hosts files that must not be blocked.
+var localNames = map[string]struct{}{
+	"localhost":             {},
+	"localhost.localdomain": {},
+	"local":                 {},
+	"broadcasthost":         {},
+	"ip6-localhost":         {},
+	"ip6-loopback":          {},
+	"ip6-localnet":          {},
+	"ip6-mcastprefix":       {},
+	"ip6-allnodes":          {},
+	"ip6-allrouters":        {},
+	"ip6-allhosts":          {},
+	"0.0.0.0":               {},
+}
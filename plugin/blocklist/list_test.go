@@ -0,0 +1,103 @@
+package blocklist
+
+import (
+	"strings"
+	"testing"
+)
+
+const testList = `# comment
+[Adblock Plus 2.0]
+! AdBlock comment
+127.0.0.1 localhost
+0.0.0.0 ads.example.org tracker.example.org # trailing comment
+::1 ip6-localhost
+||adblock.example.net^
+||options.example.net^$third-party
+@@||exception.adblock.example.net^
+example.net##.banner
+plain.example.com
+*.wild.example.com
+not a domain
+`
+
+func TestParseList(t *testing.T) {
+	tests := []struct {
+		name  string
+		allow bool
+		match bool
+	}{
+		{"ads.example.org.", false, true},
+		{"tracker.example.org.", false, true},
+		{"sub.ads.example.org.", false, false},
+		{"localhost.", false, false},
+		{"ip6-localhost.", false, false},
+		{"adblock.example.net.", false, true},
+		{"a.b.adblock.example.net.", false, true},
+		{"options.example.net.", false, false},
+		{"example.net.", false, false},
+		{"plain.example.com.", false, true},
+		{"www.plain.example.com.", false, false},
+		{"wild.example.com.", false, false},
+		{"www.wild.example.com.", false, true},
+		{"exception.adblock.example.net.", true, true},
+		{"adblock.example.net.", true, true},
+	}
+
+	block, err := parseList(strings.NewReader(testList), false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	allow, err := parseList(strings.NewReader(testList), true)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if block.Len() != 5 {
+		t.Errorf("Expected 5 names in block list, got %d", block.Len())
+	}
+	if allow.Len() != 6 {
+		t.Errorf("Expected 6 names in allow list, got %d", allow.Len())
+	}
+
+	for i, tc := range tests {
+		tr := block
+		if tc.allow {
+			tr = allow
+		}
+		if got := tr.Match(tc.name); got != tc.match {
+			t.Errorf("Test %d: expected match for %q to be %t, got %t", i, tc.name, tc.match, got)
+		}
+	}
+}
+
+func TestNewList(t *testing.T) {
+	tests := []struct {
+		source string
+		root   string
+		path   string
+		url    string
+		err    bool
+	}{
+		{"file:///etc/blocklist", "", "/etc/blocklist", "", false},
+		{"file://lists/blocklist", "/etc/coredns", "/etc/coredns/lists/blocklist", "", false},
+		{"blocklist", "/etc/coredns", "/etc/coredns/blocklist", "", false},
+		{"http://example.org/list.txt", "", "", "http://example.org/list.txt", false},
+		{"https://example.org/list.txt", "", "", "https://example.org/list.txt", false},
+		{"ftp://example.org/list.txt", "", "", "", true},
+	}
+	for i, tc := range tests {
+		l, err := newList(tc.source, tc.root, false)
+		if tc.err {
+			if err == nil {
+				t.Errorf("Test %d: expected error for %q", i, tc.source)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("Test %d: expected no error, got %s", i, err)
+			continue
+		}
+		if l.path != tc.path || l.url != tc.url {
+			t.Errorf("Test %d: expected path %q and url %q, got %q and %q", i, tc.path, tc.url, l.path, l.url)
+		}
+	}
+}
@@ -0,0 +1,11 @@
+package blocklist
+
+// Ready implements the ready.Readiness interface. It returns true when all lists have been loaded.
+func (b *Blocklist) Ready() bool {
+	for _, l := range b.lists() {
+		if !l.Loaded() {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,140 @@
+package blocklist
+
+import (
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/coredns/caddy"
+	"github.com/coredns/coredns/core/dnsserver"
+	"github.com/coredns/coredns/plugin"
+)
+
+func init() { plugin.Register("blocklist", setup) }
+
+func setup(c *caddy.Controller) error {
+	b, refresh, err := parse(c)
+	if err != nil {
+		return plugin.Error("blocklist", err)
+	}
+
+	stop := make(chan struct{})
+	c.OnStartup(func() error {
+		go func() {
+			b.load()
+			if refresh == 0 {
+				return
+			}
+			tick := time.NewTicker(refresh)
+			defer tick.Stop()
+			for {
+				select {
+				case <-stop:
+					return
+				case <-tick.C:
+					b.load()
+				}
+			}
+		}()
+		return nil
+	})
+
+	c.OnShutdown(func() error {
+		close(stop)
+		return nil
+	})
+
+	dnsserver.GetConfig(c).AddPlugin(func(next plugin.Handler) plugin.Handler {
+		b.Next = next
+		return b
+	})
+
+	return nil
+}
+
+func parse(c *caddy.Controller) (*Blocklist, time.Duration, error) {
+	config := dnsserver.GetConfig(c)
+	b := &Blocklist{action: actionNXDomain, ttl: 3600}
+	refresh := 1 * time.Hour
+
+	i := 0
+	for c.Next() {
+		if i > 0 {
+			return nil, 0, plugin.ErrOnce
+		}
+		i++
+
+		b.Zones = plugin.OriginsFromArgsOrServerBlock(c.RemainingArgs(), c.ServerBlockKeys)
+
+		for c.NextBlock() {
+			switch c.Val() {
+			case "list", "allow":
+				allow := c.Val() == "allow"
+				args := c.RemainingArgs()
+				if len(args) == 0 {
+					return nil, 0, c.ArgErr()
+				}
+				for _, a := range args {
+					l, err := newList(a, config.Root, allow)
+					if err != nil {
+						return nil, 0, c.Err(err.Error())
+					}
+					if allow {
+						b.allow = append(b.allow, l)
+						continue
+					}
+					b.block = append(b.block, l)
+				}
+			case "action":
+				args := c.RemainingArgs()
+				if len(args) != 1 {
+					return nil, 0, c.ArgErr()
+				}
+				switch strings.ToLower(args[0]) {
+				case "nxdomain":
+					b.action = actionNXDomain
+				case "nodata":
+					b.action = actionNoData
+				case "nullip":
+					b.action = actionNullIP
+				case "refused":
+					b.action = actionRefused
+				default:
+					return nil, 0, c.Errf("unknown action '%s'; expect 'nxdomain', 'nodata', 'nullip' or 'refused'", args[0])
+				}
+			case "ttl":
+				args := c.RemainingArgs()
+				if len(args) != 1 {
+					return nil, 0, c.ArgErr()
+				}
+				ttl, err := strconv.Atoi(args[0])
+				if err != nil {
+					return nil, 0, c.Errf("ttl needs a number of second")
+				}
+				if ttl <= 0 || ttl > 65535 {
+					return nil, 0, c.Errf("ttl provided is invalid")
+				}
+				b.ttl = uint32(ttl)
+			case "refresh":
+				args := c.RemainingArgs()
+				if len(args) != 1 {
+					return nil, 0, c.ArgErr()
+				}
+				d, err := time.ParseDuration(args[0])
+				if err != nil {
+					return nil, 0, c.Errf("invalid duration for refresh '%s'", args[0])
+				}
+				if d < 0 {
+					return nil, 0, c.Errf("invalid negative duration for refresh '%s'", args[0])
+				}
+				refresh = d
+			default:
+				return nil, 0, c.Errf("unknown property '%s'", c.Val())
+			}
+		}
+	}
+	if len(b.block) == 0 {
+		return nil, 0, c.Errf("at least one block list is required")
+	}
+	return b, refresh, nil
+}
@@ -0,0 +1,92 @@
+package blocklist
+
+import (
+	"testing"
+	"time"
+
+	"github.com/coredns/caddy"
+)
+
+func TestSetup(t *testing.T) {
+	tests := []struct {
+		input     string
+		shouldErr bool
+		block     int
+		allow     int
+		action    action
+		refresh   time.Duration
+	}{
+		{`blocklist {
+			list /etc/blocklist
+		}`, false, 1, 0, actionNXDomain, time.Hour},
+		{`blocklist example.org {
+			list /etc/blocklist https://example.net/hosts.txt
+			allow file:///etc/allowlist
+			action nullip
+			ttl 60
+			refresh 24h
+		}`, false, 2, 1, actionNullIP, 24 * time.Hour},
+		{`blocklist {
+			list /etc/blocklist
+			action REFUSED
+			refresh 0s
+		}`, false, 1, 0, actionRefused, 0},
+		// fails
+		{`blocklist`, true, 0, 0, 0, 0},
+		{`blocklist {
+			allow /etc/allowlist
+		}`, true, 0, 0, 0, 0},
+		{`blocklist {
+			list
+		}`, true, 0, 0, 0, 0},
+		{`blocklist {
+			list ftp://example.org/list
+		}`, true, 0, 0, 0, 0},
+		{`blocklist {
+			list /etc/blocklist
+			action sinkhole
+		}`, true, 0, 0, 0, 0},
+		{`blocklist {
+			list /etc/blocklist
+			ttl 0
+		}`, true, 0, 0, 0, 0},
+		{`blocklist {
+			list /etc/blocklist
+			refresh -1m
+		}`, true, 0, 0, 0, 0},
+		{`blocklist {
+			list /etc/blocklist
+			blah
+		}`, true, 0, 0, 0, 0},
+		{`blocklist {
+			list /etc/blocklist
+		}
+		blocklist {
+			list /etc/blocklist
+		}`, true, 0, 0, 0, 0},
+	}
+
+	for i, tc := range tests {
+		c := caddy.NewTestController("dns", tc.input)
+		b, refresh, err := parse(c)
+		if tc.shouldErr {
+			if err == nil {
+				t.Errorf("Test %d: expected error, got none", i)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("Test %d: expected no error, got %s", i, err)
+			continue
+		}
+		if len(b.block) != tc.block || len(b.allow) != tc.allow {
+			t.Errorf("Test %d: expected %d block and %d allow lists, got %d and %d", i, tc.block, tc.allow, len(b.block), len(b.allow))
+		}
+		if b.action != tc.action {
+			t.Errorf("Test %d: expected action %d, got %d", i, tc.action, b.action)
+		}
+		if refresh != tc.refresh {
+			t.Errorf("Test %d: expected refresh %s, got %s", i, tc.refresh, refresh)
+		}
+	}
+}
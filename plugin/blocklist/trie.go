@@ -0,0 +1,66 @@
+package blocklist
+
+import "github.com/miekg/dns"
+
+// trie is a domain name suffix trie. Names are stored label by label, starting with the
+// right most label, so that a name and all names below it can be matched with a single walk.
+type trie struct {
+	root *node
+	len  int
+}
+
+type node struct {
+	children map[string]*node
+	exact    bool // the name itself is in the trie
+	wildcard bool // all names below this name are in the trie
+}
+
+func newTrie() *trie { return &trie{root: &node{}} }
+
+// Insert adds name to the trie. If exact is true the name itself matches, if wildcard is true all
+// names below name match.
+func (t *trie) Insert(name string, exact, wildcard bool) {
+	n := t.root
+	end := len(name)
+	for end > 0 {
+		off, _ := dns.PrevLabel(name[:end], 1)
+		label := name[off : end-1]
+		if n.children == nil {
+			n.children = make(map[string]*node)
+		}
+		c, ok := n.children[label]
+		if !ok {
+			c = &node{}
+			n.children[label] = c
+		}
+		n = c
+		end = off
+	}
+	if !n.exact && !n.wildcard {
+		t.len++
+	}
+	n.exact = n.exact || exact
+	n.wildcard = n.wildcard || wildcard
+}
+
+// Match returns true if name, or one of its parents with a wildcard, is in the trie.
+func (t *trie) Match(name string) bool {
+	n := t.root
+	end := len(name)
+	for end > 0 {
+		if n.wildcard {
+			return true
+		}
+		off, _ := dns.PrevLabel(name[:end], 1)
+		c, ok := n.children[name[off:end-1]]
+		if !ok {
+			return false
+		}
+		n = c
+		end = off
+	}
+	return n.exact
+}
+
+// Len returns the number of names in the trie.
+func (t *trie) Len() int { return t.len }
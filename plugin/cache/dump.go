@@ -0,0 +1,49 @@
+package cache
+
+import (
+	"strings"
+	"time"
+
+	"github.com/coredns/coredns/plugin/pkg/cache"
+
+	"github.com/miekg/dns"
+)
+
+// Dump returns the responses cached for name. The TTLs are set to the time the responses have left in
+// the cache, stale responses are returned with a zero TTL.
+func (c *Cache) Dump(name string) []*dns.Msg {
+	now := c.now().UTC()
+	var msgs []*dns.Msg
+	for _, ca := range []*cache.Cache{c.pcache, c.ncache} {
+		ca.Walk(func(items map[uint64]interface{}, key uint64) bool {
+			i, ok := items[key].(*item)
+			if !ok || !strings.EqualFold(i.Name, name) {
+				return true
+			}
+			t := now
+			if ttl := i.ttl(now); ttl < 0 {
+				t = now.Add(time.Duration(ttl) * time.Second)
+			}
+			m := new(dns.Msg)
+			m.SetQuestion(dns.Fqdn(i.Name), i.QType)
+			msgs = append(msgs, i.toMsg(m, t, true, true))
+			return true
+		})
+	}
+	return msgs
+}
+
+// Flush removes all responses cached for name, it returns the number of responses removed.
+func (c *Cache) Flush(name string) int {
+	n := 0
+	for _, ca := range []*cache.Cache{c.pcache, c.ncache} {
+		ca.Walk(func(items map[uint64]interface{}, key uint64) bool {
+			if i, ok := items[key].(*item); ok && strings.EqualFold(i.Name, name) {
+				delete(items, key)
+				n++
+			}
+			return true
+		})
+	}
+	return n
+}
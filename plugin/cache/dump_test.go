@@ -0,0 +1,40 @@
+package cache
+
+import (
+	"context"
+	"testing"
+
+	"github.com/coredns/coredns/plugin/pkg/dnstest"
+	"github.com/coredns/coredns/plugin/test"
+
+	"github.com/miekg/dns"
+)
+
+func TestDumpFlush(t *testing.T) {
+	c := New()
+	c.Next = ttlBackend(60)
+
+	for _, name := range []string{"a.example.org.", "b.example.org."} {
+		req := new(dns.Msg)
+		req.SetQuestion(name, dns.TypeA)
+		c.ServeDNS(context.TODO(), dnstest.NewRecorder(&test.ResponseWriter{}), req)
+	}
+
+	msgs := c.Dump("A.example.ORG.")
+	if len(msgs) != 1 {
+		t.Fatalf("Expected 1 cached response, got %d", len(msgs))
+	}
+	if msgs[0].Question[0].Name != "a.example.org." || len(msgs[0].Answer) != 1 {
+		t.Errorf("Unexpected cached response %s", msgs[0])
+	}
+
+	if n := c.Flush("a.example.org."); n != 1 {
+		t.Errorf("Expected 1 flushed response, got %d", n)
+	}
+	if msgs := c.Dump("a.example.org."); len(msgs) != 0 {
+		t.Errorf("Expected no cached responses after flush, got %d", len(msgs))
+	}
+	if c.pcache.Len() != 1 {
+		t.Errorf("Expected 1 cached response left, got %d", c.pcache.Len())
+	}
+}
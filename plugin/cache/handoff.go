@@ -0,0 +1,31 @@
+package cache
+
+import (
+	"reflect"
+
+	"github.com/coredns/coredns/plugin"
+)
+
+// Handoff implements the dnsserver.Handoff interface. When the configuration is unchanged, c takes over
+// the cached responses of the previous instance.
+func (c *Cache) Handoff(previous plugin.Handler) {
+	prev, ok := previous.(*Cache)
+	if !ok || prev == c || !c.sameConfig(prev) {
+		return
+	}
+	c.pcache = prev.pcache
+	c.ncache = prev.ncache
+	log.Debugf("Took over %d cached responses from the previous configuration", c.pcache.Len()+c.ncache.Len())
+}
+
+// sameConfig returns true if c and prev cache the same responses for the same time.
+func (c *Cache) sameConfig(prev *Cache) bool {
+	return reflect.DeepEqual(c.Zones, prev.Zones) &&
+		c.pcap == prev.pcap && c.pttl == prev.pttl && c.minpttl == prev.minpttl &&
+		c.ncap == prev.ncap && c.nttl == prev.nttl && c.minnttl == prev.minnttl &&
+		c.failttl == prev.failttl &&
+		c.staleUpTo == prev.staleUpTo &&
+		c.keepttl == prev.keepttl &&
+		reflect.DeepEqual(c.pexcept, prev.pexcept) &&
+		reflect.DeepEqual(c.nexcept, prev.nexcept)
+}
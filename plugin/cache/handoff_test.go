@@ -0,0 +1,50 @@
+package cache
+
+import (
+	"context"
+	"testing"
+
+	"github.com/coredns/caddy"
+	"github.com/coredns/coredns/plugin/pkg/dnstest"
+	"github.com/coredns/coredns/plugin/test"
+
+	"github.com/miekg/dns"
+)
+
+func TestHandoff(t *testing.T) {
+	tests := []struct {
+		previous string
+		current  string
+		takeOver bool
+	}{
+		{"cache", "cache", true},
+		{"cache {\nprefetch 10\n}", "cache", true},
+		{"cache 300", "cache", false},
+		{"cache . example.org", "cache .", false},
+		{"cache {\nsuccess 1000\n}", "cache", false},
+		{"cache {\ndisable denial example.org\n}", "cache", false},
+	}
+	for i, tc := range tests {
+		pc := caddy.NewTestController("dns", tc.previous)
+		pc.ServerBlockKeys = []string{"."}
+		prev, err := cacheParse(pc)
+		if err != nil {
+			t.Fatalf("Test %d: %s", i, err)
+		}
+		prev.Next = ttlBackend(60)
+		req := new(dns.Msg)
+		req.SetQuestion("example.org.", dns.TypeA)
+		prev.ServeDNS(context.TODO(), dnstest.NewRecorder(&test.ResponseWriter{}), req)
+
+		cc := caddy.NewTestController("dns", tc.current)
+		cc.ServerBlockKeys = []string{"."}
+		c, err := cacheParse(cc)
+		if err != nil {
+			t.Fatalf("Test %d: %s", i, err)
+		}
+		c.Handoff(prev)
+		if took := c.pcache.Len() == 1; took != tc.takeOver {
+			t.Errorf("Test %d: expected take over to be %t, got %t", i, tc.takeOver, took)
+		}
+	}
+}
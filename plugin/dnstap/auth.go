@@ -0,0 +1,110 @@
+package dnstap
+
+import (
+	"context"
+	"time"
+
+	"github.com/coredns/caddy"
+	"github.com/coredns/coredns/core/dnsserver"
+	"github.com/coredns/coredns/plugin/dnstap/msg"
+	"github.com/coredns/coredns/plugin/pkg/dnstest"
+	"github.com/coredns/coredns/request"
+
+	tap "github.com/dnstap/golang-dnstap"
+	"github.com/miekg/dns"
+)
+
+// Taps are the dnstap plugins of a server block. Plugins that answer authoritatively, like file, use
+// it to send AUTH_QUERY and AUTH_RESPONSE messages. A nil *Taps taps nothing.
+type Taps struct {
+	taps []*Dnstap
+}
+
+// NewTaps returns the Taps of the server block of c. They're filled in on startup, after the plugin
+// chain is built.
+func NewTaps(c *caddy.Controller) *Taps {
+	t := &Taps{}
+	c.OnStartup(func() error {
+		t.taps = nil
+		if taph := dnsserver.GetConfig(c).Handler("dnstap"); taph != nil {
+			for tapPlugin, ok := taph.(*Dnstap); ok; tapPlugin, ok = tapPlugin.Next.(*Dnstap) {
+				t.taps = append(t.taps, tapPlugin)
+			}
+		}
+		return nil
+	})
+	return t
+}
+
+// Len returns the number of dnstap plugins.
+func (t *Taps) Len() int {
+	if t == nil {
+		return 0
+	}
+	return len(t.taps)
+}
+
+// TapAuth sends an AUTH_QUERY and an AUTH_RESPONSE message for the query in state, that was received
+// at queryTime and is answered authoritatively with resp. It should be called before resp is written.
+func (t *Taps) TapAuth(ctx context.Context, state request.Request, resp *dns.Msg, queryTime time.Time) {
+	if t.Len() == 0 {
+		return
+	}
+	now := time.Now()
+	for _, h := range t.taps {
+		if !h.Match(state, resp.Rcode) {
+			continue
+		}
+		// Auth dnstap messages are from the perspective of the authoritative server.
+		q := new(tap.Message)
+		msg.SetQueryTime(q, queryTime)
+		msg.SetQueryAddress(q, state.W.RemoteAddr())
+		msg.SetResponseAddress(q, state.W.LocalAddr())
+		if h.IncludeRawMessage {
+			buf, _ := state.Req.Pack()
+			q.QueryMessage = buf
+		}
+		msg.SetType(q, tap.Message_AUTH_QUERY)
+		h.TapMessageWithMetadata(ctx, q, state, nil)
+
+		r := new(tap.Message)
+		msg.SetQueryTime(r, queryTime)
+		msg.SetResponseTime(r, now)
+		msg.SetQueryAddress(r, state.W.RemoteAddr())
+		msg.SetResponseAddress(r, state.W.LocalAddr())
+		if h.IncludeRawMessage {
+			buf, _ := resp.Pack()
+			r.ResponseMessage = buf
+		}
+		msg.SetType(r, tap.Message_AUTH_RESPONSE)
+		var rr *dnstest.Recorder
+		if h.ExtraFormat != "" {
+			rr = &dnstest.Recorder{ResponseWriter: state.W, Rcode: resp.Rcode, Len: resp.Len(), Msg: resp, Start: queryTime}
+		}
+		h.TapMessageWithMetadata(ctx, r, state, rr)
+	}
+}
+
+// AuthWriter returns a dns.ResponseWriter that calls TapAuth for every message written to it, for the
+// query in state that was received at queryTime. This is for plugins that write their answers via
+// helpers, such as plugin.BackendError.
+func (t *Taps) AuthWriter(ctx context.Context, state request.Request, queryTime time.Time) dns.ResponseWriter {
+	if t.Len() == 0 {
+		return state.W
+	}
+	return &authWriter{ResponseWriter: state.W, ctx: ctx, taps: t, state: state, queryTime: queryTime}
+}
+
+type authWriter struct {
+	dns.ResponseWriter
+	ctx       context.Context
+	taps      *Taps
+	state     request.Request
+	queryTime time.Time
+}
+
+// WriteMsg implements the dns.ResponseWriter interface.
+func (w *authWriter) WriteMsg(m *dns.Msg) error {
+	w.taps.TapAuth(w.ctx, w.state, m, w.queryTime)
+	return w.ResponseWriter.WriteMsg(m)
+}
@@ -0,0 +1,70 @@
+package dnstap
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	test "github.com/coredns/coredns/plugin/test"
+	"github.com/coredns/coredns/request"
+
+	tap "github.com/dnstap/golang-dnstap"
+	"github.com/miekg/dns"
+)
+
+func TestTapAuth(t *testing.T) {
+	rec := &recorder{}
+	filtered := &recorder{}
+	taps := &Taps{taps: []*Dnstap{
+		{io: rec, IncludeRawMessage: true},
+		{io: filtered, filters: parseFilters(t, "filter rcode NXDOMAIN")},
+	}}
+
+	q := new(dns.Msg)
+	q.SetQuestion("example.org.", dns.TypeA)
+	m := new(dns.Msg)
+	m.SetReply(q)
+	m.Authoritative = true
+	state := request.Request{W: &test.ResponseWriter{}, Req: q}
+	taps.TapAuth(context.TODO(), state, m, time.Now())
+
+	types := rec.types()
+	if len(types) != 2 || types[0] != tap.Message_AUTH_QUERY || types[1] != tap.Message_AUTH_RESPONSE {
+		t.Fatalf("Expected AUTH_QUERY and AUTH_RESPONSE, got %v", types)
+	}
+	if len(rec.msgs[0].Message.QueryMessage) == 0 || len(rec.msgs[1].Message.ResponseMessage) == 0 {
+		t.Errorf("Expected the raw messages to be included")
+	}
+	if len(filtered.msgs) != 0 {
+		t.Errorf("Expected no messages for the filtered tap, got %d", len(filtered.msgs))
+	}
+
+	// A nil Taps taps nothing.
+	var none *Taps
+	none.TapAuth(context.TODO(), state, m, time.Now())
+}
+
+func TestAuthWriter(t *testing.T) {
+	rec := &recorder{}
+	taps := &Taps{taps: []*Dnstap{{io: rec}}}
+
+	q := new(dns.Msg)
+	q.SetQuestion("example.org.", dns.TypeA)
+	state := request.Request{W: &test.ResponseWriter{}, Req: q}
+	w := taps.AuthWriter(context.TODO(), state, time.Now())
+
+	m := new(dns.Msg)
+	m.SetRcode(q, dns.RcodeNameError)
+	w.WriteMsg(m)
+
+	types := rec.types()
+	if len(types) != 2 || types[0] != tap.Message_AUTH_QUERY || types[1] != tap.Message_AUTH_RESPONSE {
+		t.Fatalf("Expected AUTH_QUERY and AUTH_RESPONSE, got %v", types)
+	}
+
+	// Without taps the writer is returned as is.
+	var none *Taps
+	if w := none.AuthWriter(context.TODO(), state, time.Now()); w != state.W {
+		t.Errorf("Expected the original writer without taps")
+	}
+}
@@ -4,9 +4,7 @@ import (
 	"io"
 	"time"
 
-	tap "github.com/dnstap/golang-dnstap"
 	fs "github.com/farsightsec/golang-framestream"
-	"google.golang.org/protobuf/proto"
 )
 
 // encoder wraps a golang-framestream.Encoder.
@@ -14,10 +12,12 @@ type encoder struct {
 	fs *fs.Encoder
 }
 
-func newEncoder(w io.Writer, timeout time.Duration) (*encoder, error) {
+// newEncoder returns an encoder writing to w. If bidirectional is true, w must also be an io.Reader and
+// the encoder engages in a handshake with the reader at the other end, as is done on sockets.
+func newEncoder(w io.Writer, timeout time.Duration, bidirectional bool) (*encoder, error) {
 	fs, err := fs.NewEncoder(w, &fs.EncoderOptions{
 		ContentType:   []byte("protobuf:dnstap.Dnstap"),
-		Bidirectional: true,
+		Bidirectional: bidirectional,
 		Timeout:       timeout,
 	})
 	if err != nil {
@@ -26,13 +26,9 @@ func newEncoder(w io.Writer, timeout time.Duration) (*encoder, error) {
 	return &encoder{fs}, nil
 }
 
-func (e *encoder) writeMsg(msg *tap.Dnstap) error {
-	buf, err := proto.Marshal(msg)
-	if err != nil {
-		return err
-	}
-
-	_, err = e.fs.Write(buf) // n < len(buf) should return an error?
+// write writes a protobuf encoded dnstap message.
+func (e *encoder) write(frame []byte) error {
+	_, err := e.fs.Write(frame) // n < len(buf) should return an error?
 	return err
 }
 
@@ -0,0 +1,195 @@
+package dnstap
+
+import (
+	"compress/gzip"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"time"
+)
+
+// file is a Sink that writes Frame Streams to a file, which is rotated when it grows beyond maxSize
+// bytes or is older than interval. Rotated files are renamed to path.1, path.2, etc. and at most keep
+// of them are kept. With compress rotated files are compressed with gzip and get a .gz suffix.
+//
+// While open, the sink holds a lock on path.lock. During a reload the new instance can't open the file
+// until the old one closed it, so it never rotates a file that is still written to.
+type file struct {
+	path     string
+	maxSize  int64         // 0 disables rotating on size
+	interval time.Duration // 0 disables rotating on time
+	keep     int
+	compress bool
+
+	lock   *os.File // path.lock, locked while the sink is open
+	f      *os.File
+	enc    *encoder
+	size   int64
+	opened time.Time
+	empty  bool // no messages have been written since the file was opened
+}
+
+// errLocked is returned when a file is locked by another instance.
+var errLocked = errors.New("in use by another instance")
+
+func (f *file) Open() error {
+	if f.lock == nil {
+		lock, err := os.OpenFile(f.path+".lock", os.O_WRONLY|os.O_CREATE, 0644)
+		if err != nil {
+			return err
+		}
+		if err := flock(lock); err != nil {
+			lock.Close()
+			return fmt.Errorf("%s: %w", f.path, err)
+		}
+		f.lock = lock
+	}
+	if err := f.open(); err != nil {
+		f.unlock()
+		return err
+	}
+	return nil
+}
+
+// open opens the file, rotating it first if it isn't empty. The lock must be held.
+func (f *file) open() error {
+	fd, err := os.OpenFile(f.path, os.O_WRONLY|os.O_CREATE|os.O_APPEND, 0644)
+	if err != nil {
+		return err
+	}
+	fi, err := fd.Stat()
+	if err != nil {
+		fd.Close()
+		return err
+	}
+	// A file holds a single stream and appending to it would break that, so rotate it first. No one
+	// else writes to it, as we hold the lock.
+	if fi.Size() > 0 {
+		fd.Close()
+		if err := f.rotate(); err != nil {
+			return err
+		}
+		if fd, err = os.OpenFile(f.path, os.O_WRONLY|os.O_CREATE|os.O_APPEND, 0644); err != nil {
+			return err
+		}
+	}
+	f.f = fd
+	f.size = 0
+	f.opened = time.Now()
+	f.empty = true
+	f.enc, err = newEncoder(&counter{w: fd, n: &f.size}, 0, false)
+	if err != nil {
+		fd.Close()
+		return err
+	}
+	return nil
+}
+
+func (f *file) Write(frame []byte) error {
+	if !f.empty && ((f.maxSize > 0 && f.size+int64(len(frame)) > f.maxSize) || (f.interval > 0 && time.Since(f.opened) > f.interval)) {
+		if err := f.reopen(); err != nil {
+			return err
+		}
+	}
+	f.empty = false
+	return f.enc.write(frame)
+}
+
+func (f *file) Flush() error {
+	if f.interval > 0 && time.Since(f.opened) > f.interval && !f.empty {
+		return f.reopen()
+	}
+	return f.enc.flush()
+}
+
+func (f *file) Close() error {
+	err := f.close()
+	f.unlock()
+	return err
+}
+
+// reopen closes the file and opens a new one, which rotates it, without giving up the lock.
+func (f *file) reopen() error {
+	if err := f.close(); err != nil {
+		return err
+	}
+	if err := f.open(); err != nil {
+		f.unlock()
+		return err
+	}
+	return nil
+}
+
+func (f *file) close() error {
+	if f.f == nil {
+		return nil
+	}
+	f.enc.close()
+	err := f.f.Close()
+	f.f = nil
+	return err
+}
+
+func (f *file) unlock() {
+	if f.lock != nil {
+		f.lock.Close()
+		f.lock = nil
+	}
+}
+
+// rotate renames path to path.1, after renaming path.1 to path.2, etc. and removes the files beyond keep.
+func (f *file) rotate() error {
+	ext := ""
+	if f.compress {
+		ext = ".gz"
+	}
+	os.Remove(fmt.Sprintf("%s.%d%s", f.path, f.keep, ext))
+	for i := f.keep - 1; i > 0; i-- {
+		os.Rename(fmt.Sprintf("%s.%d%s", f.path, i, ext), fmt.Sprintf("%s.%d%s", f.path, i+1, ext))
+	}
+	if f.keep == 0 {
+		return os.Remove(f.path)
+	}
+	if !f.compress {
+		return os.Rename(f.path, f.path+".1")
+	}
+	if err := gzipFile(f.path, f.path+".1.gz"); err != nil {
+		return err
+	}
+	return os.Remove(f.path)
+}
+
+func gzipFile(src, dst string) error {
+	in, err := os.Open(src)
+	if err != nil {
+		return err
+	}
+	defer in.Close()
+	out, err := os.OpenFile(dst, os.O_WRONLY|os.O_CREATE|os.O_TRUNC, 0644)
+	if err != nil {
+		return err
+	}
+	zw := gzip.NewWriter(out)
+	if _, err := io.Copy(zw, in); err != nil {
+		out.Close()
+		return err
+	}
+	if err := zw.Close(); err != nil {
+		out.Close()
+		return err
+	}
+	return out.Close()
+}
+
+// counter counts the bytes written to w in n.
+type counter struct {
+	w io.Writer
+	n *int64
+}
+
+func (c *counter) Write(p []byte) (int, error) {
+	n, err := c.w.Write(p)
+	*c.n += int64(n)
+	return n, err
+}
@@ -0,0 +1,144 @@
+package dnstap
+
+import (
+	"compress/gzip"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	fs "github.com/farsightsec/golang-framestream"
+)
+
+// readFrames returns the frames in the Frame Streams file r.
+func readFrames(t *testing.T, r io.Reader) []string {
+	dec, err := fs.NewDecoder(r, &fs.DecoderOptions{ContentType: []byte("protobuf:dnstap.Dnstap")})
+	if err != nil {
+		t.Fatalf("Failed to create decoder: %s", err)
+	}
+	frames := []string{}
+	for {
+		frame, err := dec.Decode()
+		if err == io.EOF {
+			return frames
+		}
+		if err != nil {
+			t.Fatalf("Failed to decode frame: %s", err)
+		}
+		frames = append(frames, string(frame))
+	}
+}
+
+func readFile(t *testing.T, path string, compressed bool) []string {
+	f, err := os.Open(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+	if !compressed {
+		return readFrames(t, f)
+	}
+	zr, err := gzip.NewReader(f)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return readFrames(t, zr)
+}
+
+func TestFileRotate(t *testing.T) {
+	for _, compress := range []bool{false, true} {
+		path := filepath.Join(t.TempDir(), "dnstap.fstrm")
+		f := &file{path: path, maxSize: 64, keep: 2, compress: compress}
+		if err := f.Open(); err != nil {
+			t.Fatal(err)
+		}
+		for i := 0; i < 4; i++ {
+			if err := f.Write([]byte(fmt.Sprintf("message %02d with some padding", i))); err != nil {
+				t.Fatal(err)
+			}
+			f.Flush()
+		}
+		if err := f.Close(); err != nil {
+			t.Fatal(err)
+		}
+
+		ext := ""
+		if compress {
+			ext = ".gz"
+		}
+		// Every message fills a file, the oldest is removed as only 2 rotated files are kept.
+		expected := map[string]string{path: "message 03 with some padding", path + ".1" + ext: "message 02 with some padding", path + ".2" + ext: "message 01 with some padding"}
+		for p, msg := range expected {
+			frames := readFile(t, p, p != path && compress)
+			if len(frames) != 1 || frames[0] != msg {
+				t.Errorf("Expected %s to hold %q, got %q", p, msg, frames)
+			}
+		}
+		if _, err := os.Stat(path + ".3" + ext); !os.IsNotExist(err) {
+			t.Errorf("Expected %s.3%s to not exist", path, ext)
+		}
+	}
+}
+
+func TestFileRotateInterval(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "dnstap.fstrm")
+	f := &file{path: path, interval: time.Hour, keep: 1}
+	if err := f.Open(); err != nil {
+		t.Fatal(err)
+	}
+	f.Write([]byte("old"))
+	f.opened = time.Now().Add(-2 * time.Hour)
+	if err := f.Flush(); err != nil {
+		t.Fatal(err)
+	}
+	// An empty file isn't rotated.
+	f.opened = time.Now().Add(-2 * time.Hour)
+	if err := f.Flush(); err != nil {
+		t.Fatal(err)
+	}
+	f.Write([]byte("new"))
+	f.Close()
+
+	if frames := readFile(t, path+".1", false); len(frames) != 1 || frames[0] != "old" {
+		t.Errorf("Expected rotated file to hold \"old\", got %q", frames)
+	}
+	if frames := readFile(t, path, false); len(frames) != 1 || frames[0] != "new" {
+		t.Errorf("Expected file to hold \"new\", got %q", frames)
+	}
+}
+
+func TestFileLocked(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "dnstap.fstrm")
+	f := &file{path: path, keep: 1}
+	if err := f.Open(); err != nil {
+		t.Fatal(err)
+	}
+	f.Write([]byte("old"))
+	f.Flush()
+
+	// A reload opens the same file, this must not rotate the file that is still written to.
+	g := &file{path: path, keep: 1}
+	if err := g.Open(); !errors.Is(err, errLocked) {
+		t.Fatalf("Expected %s, got %v", errLocked, err)
+	}
+	if _, err := os.Stat(path + ".1"); !os.IsNotExist(err) {
+		t.Errorf("Expected %s.1 to not exist", path)
+	}
+	f.Close()
+
+	if err := g.Open(); err != nil {
+		t.Fatal(err)
+	}
+	g.Write([]byte("new"))
+	g.Close()
+
+	if frames := readFile(t, path+".1", false); len(frames) != 1 || frames[0] != "old" {
+		t.Errorf("Expected rotated file to hold \"old\", got %q", frames)
+	}
+	if frames := readFile(t, path, false); len(frames) != 1 || frames[0] != "new" {
+		t.Errorf("Expected file to hold \"new\", got %q", frames)
+	}
+}
@@ -0,0 +1,124 @@
+package dnstap
+
+import (
+	"net"
+	"strings"
+
+	"github.com/coredns/caddy"
+	"github.com/coredns/coredns/plugin"
+	"github.com/coredns/coredns/request"
+
+	"github.com/miekg/dns"
+)
+
+// filter selects the messages that are tapped by zone, query type, rcode or client address. A filter
+// matches if any of its values matches, unless it's negated.
+type filter struct {
+	kind   string
+	negate bool
+
+	zones  []string
+	qtypes []uint16
+	rcodes []int
+	nets   []*net.IPNet
+}
+
+// filters match if all of them match.
+type filters []filter
+
+// match returns true if the query in state, answered with rcode, should be tapped. A negative rcode means
+// the rcode isn't known yet, rcode filters then don't match.
+func (fs filters) match(state request.Request, rcode int) bool {
+	for _, f := range fs {
+		if f.match(state, rcode) == f.negate {
+			return false
+		}
+	}
+	return true
+}
+
+// needRcode returns true if one of the filters matches on the rcode.
+func (fs filters) needRcode() bool {
+	for _, f := range fs {
+		if f.kind == "rcode" {
+			return true
+		}
+	}
+	return false
+}
+
+func (f filter) match(state request.Request, rcode int) bool {
+	switch f.kind {
+	case "zone":
+		return plugin.Zones(f.zones).Matches(state.Name()) != ""
+	case "qtype":
+		qtype := state.QType()
+		for _, t := range f.qtypes {
+			if t == qtype {
+				return true
+			}
+		}
+	case "rcode":
+		for _, r := range f.rcodes {
+			if r == rcode {
+				return true
+			}
+		}
+	case "client":
+		ip := net.ParseIP(state.IP())
+		for _, n := range f.nets {
+			if n.Contains(ip) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// parseFilter parses: filter [not] zone|qtype|rcode|client VALUES...
+func parseFilter(c *caddy.Controller) (filter, error) {
+	args := c.RemainingArgs()
+	f := filter{}
+	if len(args) > 0 && args[0] == "not" {
+		f.negate = true
+		args = args[1:]
+	}
+	if len(args) < 2 {
+		return f, c.ArgErr()
+	}
+	f.kind = args[0]
+	for _, v := range args[1:] {
+		switch f.kind {
+		case "zone":
+			f.zones = append(f.zones, plugin.Host(v).NormalizeExact()...)
+		case "qtype":
+			t, ok := dns.StringToType[strings.ToUpper(v)]
+			if !ok {
+				return f, c.Errf("invalid qtype '%s'", v)
+			}
+			f.qtypes = append(f.qtypes, t)
+		case "rcode":
+			r, ok := dns.StringToRcode[strings.ToUpper(v)]
+			if !ok {
+				return f, c.Errf("invalid rcode '%s'", v)
+			}
+			f.rcodes = append(f.rcodes, r)
+		case "client":
+			if !strings.Contains(v, "/") {
+				if net.ParseIP(v).To4() != nil {
+					v += "/32"
+				} else {
+					v += "/128"
+				}
+			}
+			_, n, err := net.ParseCIDR(v)
+			if err != nil {
+				return f, c.Errf("invalid client network '%s'", v)
+			}
+			f.nets = append(f.nets, n)
+		default:
+			return f, c.Errf("unknown filter '%s'", f.kind)
+		}
+	}
+	return f, nil
+}
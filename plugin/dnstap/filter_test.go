@@ -0,0 +1,166 @@
+package dnstap
+
+import (
+	"context"
+	"testing"
+
+	"github.com/coredns/caddy"
+	"github.com/coredns/coredns/plugin/pkg/replacer"
+	test "github.com/coredns/coredns/plugin/test"
+	"github.com/coredns/coredns/request"
+
+	tap "github.com/dnstap/golang-dnstap"
+	"github.com/miekg/dns"
+)
+
+// recorder records the dnstap messages.
+type recorder struct {
+	msgs []*tap.Dnstap
+}
+
+func (r *recorder) Dnstap(d *tap.Dnstap) { r.msgs = append(r.msgs, d) }
+
+func (r *recorder) types() []tap.Message_Type {
+	types := make([]tap.Message_Type, len(r.msgs))
+	for i, m := range r.msgs {
+		types[i] = m.Message.GetType()
+	}
+	return types
+}
+
+func parseFilters(t *testing.T, lines ...string) filters {
+	fs := filters{}
+	for _, l := range lines {
+		c := caddy.NewTestController("dns", l)
+		c.Next() // filter
+		f, err := parseFilter(c)
+		if err != nil {
+			t.Fatalf("Failed to parse %q: %s", l, err)
+		}
+		fs = append(fs, f)
+	}
+	return fs
+}
+
+func TestFilterMatch(t *testing.T) {
+	tests := []struct {
+		filters []string
+		qname   string
+		qtype   uint16
+		rcode   int
+		match   bool
+	}{
+		{nil, "example.org.", dns.TypeA, dns.RcodeSuccess, true},
+		{[]string{"filter zone example.org"}, "www.example.org.", dns.TypeA, dns.RcodeSuccess, true},
+		{[]string{"filter zone example.org"}, "www.example.net.", dns.TypeA, dns.RcodeSuccess, false},
+		{[]string{"filter not zone example.org"}, "www.example.org.", dns.TypeA, dns.RcodeSuccess, false},
+		{[]string{"filter qtype AAAA mx"}, "example.org.", dns.TypeMX, dns.RcodeSuccess, true},
+		{[]string{"filter qtype AAAA mx"}, "example.org.", dns.TypeA, dns.RcodeSuccess, false},
+		{[]string{"filter rcode NXDOMAIN SERVFAIL"}, "example.org.", dns.TypeA, dns.RcodeNameError, true},
+		{[]string{"filter rcode NXDOMAIN SERVFAIL"}, "example.org.", dns.TypeA, dns.RcodeSuccess, false},
+		{[]string{"filter client 10.240.0.0/16"}, "example.org.", dns.TypeA, dns.RcodeSuccess, true},
+		{[]string{"filter client 10.0.0.1 ::1"}, "example.org.", dns.TypeA, dns.RcodeSuccess, false},
+		{[]string{"filter zone example.org", "filter qtype AAAA"}, "example.org.", dns.TypeA, dns.RcodeSuccess, false},
+		{[]string{"filter zone example.org", "filter not qtype AAAA"}, "example.org.", dns.TypeA, dns.RcodeSuccess, true},
+	}
+	for i, tc := range tests {
+		fs := parseFilters(t, tc.filters...)
+		m := new(dns.Msg)
+		m.SetQuestion(tc.qname, tc.qtype)
+		state := request.Request{W: &test.ResponseWriter{}, Req: m}
+		if x := fs.match(state, tc.rcode); x != tc.match {
+			t.Errorf("Test %d: expected match %t, got %t", i, tc.match, x)
+		}
+	}
+}
+
+func TestParseFilterErrors(t *testing.T) {
+	tests := []string{
+		"filter",
+		"filter zone",
+		"filter not",
+		"filter name example.org",
+		"filter qtype BOGUS",
+		"filter rcode BOGUS",
+		"filter client 10.0.0.0/33",
+	}
+	for _, tc := range tests {
+		c := caddy.NewTestController("dns", tc)
+		c.Next()
+		if _, err := parseFilter(c); err == nil {
+			t.Errorf("Expected error for %q", tc)
+		}
+	}
+}
+
+func TestServeDNSFilter(t *testing.T) {
+	tests := []struct {
+		filters []string
+		rcode   int
+		write   bool
+		expect  []tap.Message_Type
+	}{
+		{nil, dns.RcodeSuccess, true, []tap.Message_Type{tap.Message_CLIENT_QUERY, tap.Message_CLIENT_RESPONSE}},
+		{[]string{"filter zone example.net"}, dns.RcodeSuccess, true, []tap.Message_Type{}},
+		{[]string{"filter rcode NXDOMAIN"}, dns.RcodeSuccess, true, []tap.Message_Type{}},
+		{[]string{"filter rcode NXDOMAIN"}, dns.RcodeNameError, true, []tap.Message_Type{tap.Message_CLIENT_QUERY, tap.Message_CLIENT_RESPONSE}},
+		// Not written by the handler, only the query is tapped.
+		{[]string{"filter rcode SERVFAIL"}, dns.RcodeServerFailure, false, []tap.Message_Type{tap.Message_CLIENT_QUERY}},
+		{[]string{"filter rcode NXDOMAIN"}, dns.RcodeServerFailure, false, []tap.Message_Type{}},
+	}
+	for i, tc := range tests {
+		rec := &recorder{}
+		h := Dnstap{
+			Next: test.HandlerFunc(func(_ context.Context, w dns.ResponseWriter, r *dns.Msg) (int, error) {
+				if !tc.write {
+					return tc.rcode, nil
+				}
+				m := new(dns.Msg)
+				m.SetRcode(r, tc.rcode)
+				return tc.rcode, w.WriteMsg(m)
+			}),
+			io:      rec,
+			filters: parseFilters(t, tc.filters...),
+		}
+		q := new(dns.Msg)
+		q.SetQuestion("example.org.", dns.TypeA)
+		h.ServeDNS(context.TODO(), &test.ResponseWriter{}, q)
+
+		types := rec.types()
+		if len(types) != len(tc.expect) {
+			t.Fatalf("Test %d: expected %v, got %v", i, tc.expect, types)
+		}
+		for j := range types {
+			if types[j] != tc.expect[j] {
+				t.Errorf("Test %d: expected %v, got %v", i, tc.expect, types)
+			}
+		}
+	}
+}
+
+func TestServeDNSExtra(t *testing.T) {
+	rec := &recorder{}
+	h := Dnstap{
+		Next: test.HandlerFunc(func(_ context.Context, w dns.ResponseWriter, r *dns.Msg) (int, error) {
+			m := new(dns.Msg)
+			m.SetRcode(r, dns.RcodeNameError)
+			return 0, w.WriteMsg(m)
+		}),
+		io:          rec,
+		repl:        replacer.New(),
+		ExtraFormat: "{type} {rcode}",
+	}
+	q := new(dns.Msg)
+	q.SetQuestion("example.org.", dns.TypeMX)
+	h.ServeDNS(context.TODO(), &test.ResponseWriter{}, q)
+
+	if len(rec.msgs) != 2 {
+		t.Fatalf("Expected 2 messages, got %d", len(rec.msgs))
+	}
+	if x := string(rec.msgs[0].Extra); x != "MX -" {
+		t.Errorf("Expected query extra %q, got %q", "MX -", x)
+	}
+	if x := string(rec.msgs[1].Extra); x != "MX NXDOMAIN" {
+		t.Errorf("Expected response extra %q, got %q", "MX NXDOMAIN", x)
+	}
+}
@@ -6,6 +6,9 @@ import (
 
 	"github.com/coredns/coredns/plugin"
 	"github.com/coredns/coredns/plugin/dnstap/msg"
+	"github.com/coredns/coredns/plugin/pkg/dnstest"
+	"github.com/coredns/coredns/plugin/pkg/replacer"
+	"github.com/coredns/coredns/request"
 
 	tap "github.com/dnstap/golang-dnstap"
 	"github.com/miekg/dns"
@@ -15,11 +18,16 @@ import (
 type Dnstap struct {
 	Next plugin.Handler
 	io   tapper
+	repl replacer.Replacer
 
 	// IncludeRawMessage will include the raw DNS message into the dnstap messages if true.
 	IncludeRawMessage bool
 	Identity          []byte
 	Version           []byte
+	// ExtraFormat is the format of the "extra" field of the dnstap messages, metadata labels can be used.
+	ExtraFormat string
+
+	filters filters
 }
 
 // TapMessage sends the message m to the dnstap interface.
@@ -28,7 +36,23 @@ func (h Dnstap) TapMessage(m *tap.Message) {
 	h.io.Dnstap(&tap.Dnstap{Type: &t, Message: m, Identity: h.Identity, Version: h.Version})
 }
 
-func (h Dnstap) tapQuery(w dns.ResponseWriter, query *dns.Msg, queryTime time.Time) {
+// TapMessageWithMetadata sends the message m to the dnstap interface, with the "extra" field set to
+// ExtraFormat with the labels replaced for the query in state. rr, if not nil, holds the response.
+func (h Dnstap) TapMessageWithMetadata(ctx context.Context, m *tap.Message, state request.Request, rr *dnstest.Recorder) {
+	if h.ExtraFormat == "" {
+		h.TapMessage(m)
+		return
+	}
+	t := tap.Dnstap_MESSAGE
+	extra := h.repl.Replace(ctx, state, rr, h.ExtraFormat)
+	h.io.Dnstap(&tap.Dnstap{Type: &t, Message: m, Identity: h.Identity, Version: h.Version, Extra: []byte(extra)})
+}
+
+// Match returns true if the query in state, answered with rcode, passes the filters of this dnstap
+// plugin. Plugins that send their own messages should only tap queries that match.
+func (h Dnstap) Match(state request.Request, rcode int) bool { return h.filters.match(state, rcode) }
+
+func (h Dnstap) tapQuery(ctx context.Context, w dns.ResponseWriter, query *dns.Msg, queryTime time.Time) {
 	q := new(tap.Message)
 	msg.SetQueryTime(q, queryTime)
 	msg.SetQueryAddress(q, w.RemoteAddr())
@@ -38,7 +62,7 @@ func (h Dnstap) tapQuery(w dns.ResponseWriter, query *dns.Msg, queryTime time.Ti
 		q.QueryMessage = buf
 	}
 	msg.SetType(q, tap.Message_CLIENT_QUERY)
-	h.TapMessage(q)
+	h.TapMessageWithMetadata(ctx, q, request.Request{W: w, Req: query}, nil)
 }
 
 // ServeDNS logs the client query and response to dnstap and passes the dnstap Context.
@@ -46,15 +70,31 @@ func (h Dnstap) ServeDNS(ctx context.Context, w dns.ResponseWriter, r *dns.Msg)
 	rw := &ResponseWriter{
 		ResponseWriter: w,
 		Dnstap:         h,
+		ctx:            ctx,
 		query:          r,
 		queryTime:      time.Now(),
 	}
 
 	// The query tap message should be sent before sending the query to the
 	// forwarder. Otherwise, the tap messages will come out out of order.
-	h.tapQuery(w, r, rw.queryTime)
+	// When filtering on the rcode, that can't be done and the query is
+	// tapped together with the response.
+	state := request.Request{W: w, Req: r}
+	switch {
+	case h.filters.needRcode():
+		rw.held = true
+	case h.filters.match(state, -1):
+		h.tapQuery(ctx, w, r, rw.queryTime)
+	default:
+		rw.skip = true
+	}
 
-	return plugin.NextOrFailure(h.Name(), h.Next, ctx, rw, r)
+	rcode, err := plugin.NextOrFailure(h.Name(), h.Next, ctx, rw, r)
+	if rw.held && !plugin.ClientWrite(rcode) && h.filters.match(state, rcode) {
+		// No response was written, the server replies with rcode.
+		h.tapQuery(ctx, w, r, rw.queryTime)
+	}
+	return rcode, err
 }
 
 // Name implements the plugin.Plugin interface.
@@ -1,11 +1,13 @@
 package dnstap
 
 import (
+	"errors"
 	"net"
 	"sync/atomic"
 	"time"
 
 	tap "github.com/dnstap/golang-dnstap"
+	"google.golang.org/protobuf/proto"
 )
 
 const (
@@ -14,6 +16,9 @@ const (
 
 	tcpTimeout   = 4 * time.Second
 	flushTimeout = 1 * time.Second
+
+	minBackoff = 500 * time.Millisecond
+	maxBackoff = 30 * time.Second
 )
 
 // tapper interface is used in testing to mock the Dnstap method.
@@ -25,12 +30,20 @@ type tapper interface {
 type dio struct {
 	endpoint     string
 	proto        string
-	enc          *encoder
+	sink         Sink
+	spoolPath    string // if not empty, messages are spooled here while the sink is down
+	spoolMax     int64
+	spool        *spool
+	spoolLocked  bool // spool is still open in another instance, retry opening it
 	queue        chan *tap.Dnstap
 	dropped      uint32
 	quit         chan struct{}
 	flushTimeout time.Duration
 	tcpTimeout   time.Duration
+
+	up      bool          // sink is open
+	retry   time.Time     // when to try to open the sink again
+	backoff time.Duration // how long to wait before the next retry if that fails
 }
 
 // newIO returns a new and initialized pointer to a dio.
@@ -42,26 +55,19 @@ func newIO(proto, endpoint string) *dio {
 		quit:         make(chan struct{}),
 		flushTimeout: flushTimeout,
 		tcpTimeout:   tcpTimeout,
+		backoff:      minBackoff,
 	}
 }
 
-func (d *dio) dial() error {
-	conn, err := net.DialTimeout(d.proto, d.endpoint, d.tcpTimeout)
-	if err != nil {
-		return err
-	}
-	if tcpConn, ok := conn.(*net.TCPConn); ok {
-		tcpConn.SetWriteBuffer(tcpWriteBufSize)
-		tcpConn.SetNoDelay(false)
-	}
-
-	d.enc, err = newEncoder(conn, d.tcpTimeout)
-	return err
-}
-
 // Connect connects to the dnstap endpoint.
 func (d *dio) connect() error {
-	err := d.dial()
+	if d.sink == nil {
+		d.sink = &socket{proto: d.proto, addr: d.endpoint, timeout: d.tcpTimeout}
+	}
+	if d.spoolPath != "" {
+		d.openSpool()
+	}
+	err := d.open()
 	go d.serve()
 	return err
 }
@@ -75,19 +81,81 @@ func (d *dio) Dnstap(payload *tap.Dnstap) {
 	}
 }
 
+// openSpool opens the spool. During a reload the previous instance may still have it open, then it is
+// retried on the next flush.
+func (d *dio) openSpool() {
+	s, err := newSpool(d.spoolPath, d.spoolMax)
+	d.spoolLocked = errors.Is(err, errLocked)
+	if err != nil && !d.spoolLocked {
+		log.Errorf("Failed to open dnstap spool, messages will be dropped when the endpoint is down: %s", err)
+	}
+	d.spool = s
+}
+
 // close waits until the I/O routine is finished to return.
 func (d *dio) close() { close(d.quit) }
 
-func (d *dio) write(payload *tap.Dnstap) error {
-	if d.enc == nil {
-		atomic.AddUint32(&d.dropped, 1)
+// open opens the sink, unless the backoff since the last failure hasn't passed yet. When the sink is
+// opened, the spooled messages are written first.
+func (d *dio) open() error {
+	now := time.Now()
+	if now.Before(d.retry) {
 		return nil
 	}
-	if err := d.enc.writeMsg(payload); err != nil {
-		atomic.AddUint32(&d.dropped, 1)
+	if err := d.sink.Open(); err != nil {
+		d.retry = now.Add(d.backoff)
+		if d.backoff *= 2; d.backoff > maxBackoff {
+			d.backoff = maxBackoff
+		}
 		return err
 	}
-	return nil
+	d.up = true
+	d.backoff = minBackoff
+	return d.replay()
+}
+
+// replay writes the spooled messages to the sink.
+func (d *dio) replay() error {
+	if d.spool == nil {
+		return nil
+	}
+	n, err := d.spool.replay(d.sink.Write)
+	if n > 0 {
+		log.Infof("Wrote %d spooled dnstap messages", n)
+	}
+	if err != nil {
+		d.down(err)
+	}
+	return err
+}
+
+// down closes the sink after it failed with err. The next message retries to open it right away.
+func (d *dio) down(err error) {
+	log.Warningf("Failed to write to dnstap endpoint: %s", err)
+	d.sink.Close()
+	d.up = false
+	d.retry = time.Time{}
+}
+
+func (d *dio) write(payload *tap.Dnstap) {
+	frame, err := proto.Marshal(payload)
+	if err != nil {
+		atomic.AddUint32(&d.dropped, 1)
+		return
+	}
+	if !d.up {
+		d.open()
+	}
+	if d.up {
+		err := d.sink.Write(frame)
+		if err == nil {
+			return
+		}
+		d.down(err)
+	}
+	if d.spool == nil || !d.spool.add(frame) {
+		atomic.AddUint32(&d.dropped, 1)
+	}
 }
 
 func (d *dio) serve() {
@@ -97,25 +165,72 @@ func (d *dio) serve() {
 		timeout.Reset(d.flushTimeout)
 		select {
 		case <-d.quit:
-			if d.enc == nil {
-				return
+			if d.up {
+				d.sink.Flush()
+				d.sink.Close()
+			}
+			if d.spool != nil {
+				d.spool.close()
 			}
-			d.enc.flush()
-			d.enc.close()
 			return
 		case payload := <-d.queue:
-			if err := d.write(payload); err != nil {
-				d.dial()
-			}
+			d.write(payload)
 		case <-timeout.C:
 			if dropped := atomic.SwapUint32(&d.dropped, 0); dropped > 0 {
 				log.Warningf("Dropped dnstap messages: %d", dropped)
 			}
-			if d.enc == nil {
-				d.dial()
-			} else {
-				d.enc.flush()
+			if d.spoolLocked {
+				d.openSpool()
+				if d.up {
+					d.replay()
+				}
+			}
+			if !d.up {
+				d.open()
+			} else if err := d.sink.Flush(); err != nil {
+				d.down(err)
 			}
 		}
 	}
 }
+
+// socket is a Sink that writes Frame Streams to a unix or tcp socket.
+type socket struct {
+	proto   string
+	addr    string
+	timeout time.Duration
+
+	conn net.Conn
+	enc  *encoder
+}
+
+func (s *socket) Open() error {
+	conn, err := net.DialTimeout(s.proto, s.addr, s.timeout)
+	if err != nil {
+		return err
+	}
+	if tcpConn, ok := conn.(*net.TCPConn); ok {
+		tcpConn.SetWriteBuffer(tcpWriteBufSize)
+		tcpConn.SetNoDelay(false)
+	}
+
+	s.enc, err = newEncoder(conn, s.timeout, true)
+	if err != nil {
+		conn.Close()
+		return err
+	}
+	s.conn = conn
+	return nil
+}
+
+func (s *socket) Write(frame []byte) error { return s.enc.write(frame) }
+func (s *socket) Flush() error             { return s.enc.flush() }
+
+func (s *socket) Close() error {
+	if s.enc == nil {
+		return nil
+	}
+	s.enc.close()
+	s.enc = nil
+	return s.conn.Close()
+}
@@ -1,8 +1,12 @@
 package dnstap
 
 import (
+	"errors"
 	"net"
+	"path/filepath"
+	"strings"
 	"sync"
+	"sync/atomic"
 	"testing"
 	"time"
 
@@ -10,6 +14,7 @@ import (
 
 	tap "github.com/dnstap/golang-dnstap"
 	fs "github.com/farsightsec/golang-framestream"
+	"google.golang.org/protobuf/proto"
 )
 
 var (
@@ -153,3 +158,75 @@ func TestReconnect(t *testing.T) {
 	}
 	wg.Wait()
 }
+
+// memSink is a Sink that keeps the frames written to it and fails while down is set.
+type memSink struct {
+	down   bool
+	opens  int
+	frames []string
+}
+
+func (m *memSink) Open() error {
+	m.opens++
+	if m.down {
+		return errors.New("down")
+	}
+	return nil
+}
+
+func (m *memSink) Write(frame []byte) error {
+	if m.down {
+		return errors.New("down")
+	}
+	m.frames = append(m.frames, string(frame))
+	return nil
+}
+
+func (m *memSink) Flush() error { return nil }
+func (m *memSink) Close() error { return nil }
+
+func TestSpoolWhileDown(t *testing.T) {
+	sink := &memSink{}
+	d := newIO("mem", "")
+	d.sink = sink
+	s, err := newSpool(filepath.Join(t.TempDir(), "spool"), megabyte)
+	if err != nil {
+		t.Fatal(err)
+	}
+	d.spool = s
+	defer s.close()
+
+	msg := func(id string) *tap.Dnstap { return &tap.Dnstap{Type: &msgType, Identity: []byte(id)} }
+	if err := d.open(); err != nil {
+		t.Fatal(err)
+	}
+	d.write(msg("1"))
+
+	sink.down = true
+	d.write(msg("2")) // fails, sink is closed and the message spooled
+	d.write(msg("3")) // open fails and starts the backoff
+	opens := sink.opens
+	d.write(msg("4")) // backoff hasn't passed, open isn't tried
+	if sink.opens != opens {
+		t.Errorf("Expected no open during the backoff, got %d opens", sink.opens-opens)
+	}
+
+	sink.down = false
+	d.retry = time.Time{}
+	d.write(msg("5")) // opens, replays the spool and writes
+
+	got := []string{}
+	for _, f := range sink.frames {
+		m := &tap.Dnstap{}
+		if err := proto.Unmarshal([]byte(f), m); err != nil {
+			t.Fatal(err)
+		}
+		got = append(got, string(m.Identity))
+	}
+	if strings.Join(got, ",") != "1,2,3,4,5" {
+		t.Errorf("Expected messages 1,2,3,4,5 in order, got %v", got)
+	}
+	if dropped := atomic.LoadUint32(&d.dropped); dropped != 0 {
+		t.Errorf("Expected no dropped messages, got %d", dropped)
+	}
+}
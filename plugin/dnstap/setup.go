@@ -3,12 +3,16 @@ package dnstap
 import (
 	"net/url"
 	"os"
+	"path/filepath"
+	"strconv"
 	"strings"
+	"time"
 
 	"github.com/coredns/caddy"
 	"github.com/coredns/coredns/core/dnsserver"
 	"github.com/coredns/coredns/plugin"
 	clog "github.com/coredns/coredns/plugin/pkg/log"
+	"github.com/coredns/coredns/plugin/pkg/replacer"
 )
 
 var log = clog.NewWithPlugin("dnstap")
@@ -30,7 +34,9 @@ func parseConfig(c *caddy.Controller) ([]*Dnstap, error) {
 
 		endpoint = args[0]
 
-		if strings.HasPrefix(endpoint, "tcp://") {
+		var fsink *file
+		switch {
+		case strings.HasPrefix(endpoint, "tcp://"):
 			// remote network endpoint
 			endpointURL, err := url.Parse(endpoint)
 			if err != nil {
@@ -38,7 +44,35 @@ func parseConfig(c *caddy.Controller) ([]*Dnstap, error) {
 			}
 			dio := newIO("tcp", endpointURL.Host)
 			d = Dnstap{io: dio}
-		} else {
+		case strings.HasPrefix(endpoint, "file://"):
+			path := strings.TrimPrefix(endpoint, "file://")
+			if path == "" {
+				return nil, c.ArgErr()
+			}
+			if !filepath.IsAbs(path) && dnsserver.GetConfig(c).Root != "" {
+				path = filepath.Join(dnsserver.GetConfig(c).Root, path)
+			}
+			fsink = &file{path: path, keep: defaultKeep}
+			dio := newIO("file", path)
+			dio.sink = fsink
+			d = Dnstap{io: dio}
+		case strings.Contains(endpoint, "://") && !strings.HasPrefix(endpoint, "unix://"):
+			endpointURL, err := url.Parse(endpoint)
+			if err != nil {
+				return nil, c.ArgErr()
+			}
+			f, ok := sinkFunc(endpointURL.Scheme)
+			if !ok {
+				return nil, c.Errf("unknown dnstap endpoint scheme '%s'", endpointURL.Scheme)
+			}
+			sink, err := f(endpointURL)
+			if err != nil {
+				return nil, c.Errf("dnstap endpoint '%s': %s", endpoint, err)
+			}
+			dio := newIO(endpointURL.Scheme, endpoint)
+			dio.sink = sink
+			d = Dnstap{io: dio}
+		default:
 			endpoint = strings.TrimPrefix(endpoint, "unix://")
 			dio := newIO("unix", endpoint)
 			d = Dnstap{io: dio}
@@ -66,6 +100,48 @@ func parseConfig(c *caddy.Controller) ([]*Dnstap, error) {
 					}
 					d.Version = []byte(c.Val())
 				}
+			case "extra":
+				if !c.NextArg() {
+					return nil, c.ArgErr()
+				}
+				d.ExtraFormat = c.Val()
+				d.repl = replacer.New()
+				if c.NextArg() {
+					return nil, c.ArgErr()
+				}
+			case "filter":
+				f, err := parseFilter(c)
+				if err != nil {
+					return nil, err
+				}
+				d.filters = append(d.filters, f)
+			case "rotate_size", "rotate_interval", "rotate_keep", "compress":
+				if fsink == nil {
+					return nil, c.Errf("%s can only be used with a file endpoint", c.Val())
+				}
+				if err := parseRotate(c, fsink); err != nil {
+					return nil, err
+				}
+			case "spool":
+				args := c.RemainingArgs()
+				if len(args) == 0 || len(args) > 2 {
+					return nil, c.ArgErr()
+				}
+				dio := d.io.(*dio)
+				dio.spoolPath = args[0]
+				if !filepath.IsAbs(dio.spoolPath) && dnsserver.GetConfig(c).Root != "" {
+					dio.spoolPath = filepath.Join(dnsserver.GetConfig(c).Root, dio.spoolPath)
+				}
+				dio.spoolMax = defaultSpoolSize * megabyte
+				if len(args) == 2 {
+					n, err := strconv.Atoi(args[1])
+					if err != nil || n <= 0 {
+						return nil, c.Errf("invalid spool size '%s'", args[1])
+					}
+					dio.spoolMax = int64(n) * megabyte
+				}
+			default:
+				return nil, c.Errf("unknown property '%s'", c.Val())
 			}
 		}
 		dnstaps = append(dnstaps, &d)
@@ -73,6 +149,51 @@ func parseConfig(c *caddy.Controller) ([]*Dnstap, error) {
 	return dnstaps, nil
 }
 
+// parseRotate parses the rotation options of a file endpoint.
+func parseRotate(c *caddy.Controller, f *file) error {
+	switch c.Val() {
+	case "rotate_size":
+		if !c.NextArg() {
+			return c.ArgErr()
+		}
+		n, err := strconv.Atoi(c.Val())
+		if err != nil || n < 0 {
+			return c.Errf("invalid rotate_size '%s'", c.Val())
+		}
+		f.maxSize = int64(n) * megabyte
+	case "rotate_interval":
+		if !c.NextArg() {
+			return c.ArgErr()
+		}
+		d, err := time.ParseDuration(c.Val())
+		if err != nil || d < 0 {
+			return c.Errf("invalid rotate_interval '%s'", c.Val())
+		}
+		f.interval = d
+	case "rotate_keep":
+		if !c.NextArg() {
+			return c.ArgErr()
+		}
+		n, err := strconv.Atoi(c.Val())
+		if err != nil || n < 0 {
+			return c.Errf("invalid rotate_keep '%s'", c.Val())
+		}
+		f.keep = n
+	case "compress":
+		f.compress = true
+	}
+	if c.NextArg() {
+		return c.ArgErr()
+	}
+	return nil
+}
+
+const (
+	megabyte         = 1024 * 1024
+	defaultKeep      = 5
+	defaultSpoolSize = 100 // megabytes
+)
+
 func setup(c *caddy.Controller) error {
 	dnstaps, err := parseConfig(c)
 	if err != nil {
@@ -1,9 +1,12 @@
 package dnstap
 
 import (
+	"errors"
+	"net/url"
 	"os"
 	"reflect"
 	"testing"
+	"time"
 
 	"github.com/coredns/caddy"
 	"github.com/coredns/coredns/core/dnsserver"
@@ -124,3 +127,106 @@ func TestMultiDnstap(t *testing.T) {
 		t.Error("expected third plugin to be last, but Next is not nil")
 	}
 }
+
+func TestConfigSinks(t *testing.T) {
+	RegisterSink("mem", func(u *url.URL) (Sink, error) {
+		if u.Host == "" {
+			return nil, errors.New("missing host")
+		}
+		return &memSink{}, nil
+	})
+
+	tests := []struct {
+		in       string
+		fail     bool
+		proto    string
+		endpoint string
+		file     *file
+		spool    int64
+	}{
+		{"dnstap file:///var/log/dnstap.fstrm", false, "file", "/var/log/dnstap.fstrm", &file{path: "/var/log/dnstap.fstrm", keep: defaultKeep}, 0},
+		{`dnstap file:///var/log/dnstap.fstrm full {
+			rotate_size 10
+			rotate_interval 1h
+			rotate_keep 24
+			compress
+		}`, false, "file", "/var/log/dnstap.fstrm", &file{path: "/var/log/dnstap.fstrm", maxSize: 10 * megabyte, interval: time.Hour, keep: 24, compress: true}, 0},
+		{"dnstap tcp://127.0.0.1:6000 {\nspool /var/spool/dnstap\n}", false, "tcp", "127.0.0.1:6000", nil, defaultSpoolSize * megabyte},
+		{"dnstap mem://collector {\nspool /var/spool/dnstap 10\n}", false, "mem", "mem://collector", nil, 10 * megabyte},
+
+		{"dnstap file://", true, "", "", nil, 0},
+		{"dnstap kafka://broker:9092", true, "", "", nil, 0},
+		{"dnstap mem://", true, "", "", nil, 0},
+		{"dnstap tcp://127.0.0.1:6000 {\nrotate_size 10\n}", true, "", "", nil, 0},
+		{"dnstap file:///tmp/dnstap {\nrotate_size big\n}", true, "", "", nil, 0},
+		{"dnstap file:///tmp/dnstap {\nrotate_interval\n}", true, "", "", nil, 0},
+		{"dnstap file:///tmp/dnstap {\ncompress gzip\n}", true, "", "", nil, 0},
+		{"dnstap dnstap.sock {\nspool\n}", true, "", "", nil, 0},
+		{"dnstap dnstap.sock {\nspool /tmp/spool 0\n}", true, "", "", nil, 0},
+		{"dnstap dnstap.sock {\nbuffer 10\n}", true, "", "", nil, 0},
+	}
+	for i, tc := range tests {
+		taps, err := parseConfig(caddy.NewTestController("dns", tc.in))
+		if tc.fail {
+			if err == nil {
+				t.Errorf("Test %d: expected error, got none", i)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("Test %d: expected no error, got %s", i, err)
+			continue
+		}
+		d := taps[0].io.(*dio)
+		if d.proto != tc.proto || d.endpoint != tc.endpoint {
+			t.Errorf("Test %d: expected %s %s, got %s %s", i, tc.proto, tc.endpoint, d.proto, d.endpoint)
+		}
+		if tc.file != nil && !reflect.DeepEqual(d.sink, tc.file) {
+			t.Errorf("Test %d: expected file sink %+v, got %+v", i, tc.file, d.sink)
+		}
+		if tc.proto == "mem" {
+			if _, ok := d.sink.(*memSink); !ok {
+				t.Errorf("Test %d: expected registered sink, got %T", i, d.sink)
+			}
+		}
+		if d.spoolMax != tc.spool {
+			t.Errorf("Test %d: expected spool size %d, got %d", i, tc.spool, d.spoolMax)
+		}
+	}
+}
+
+func TestConfigFilterExtra(t *testing.T) {
+	tests := []struct {
+		in      string
+		fail    bool
+		filters int
+		extra   string
+	}{
+		{"dnstap dnstap.sock {\nfilter zone example.org\nfilter not client 10.0.0.0/8\n}", false, 2, ""},
+		{"dnstap dnstap.sock {\nextra \"{/kubernetes/client-namespace} {type}\"\n}", false, 0, "{/kubernetes/client-namespace} {type}"},
+		{"dnstap dnstap.sock {\nfilter rcode NXDOMAIN\nextra {type}\n}", false, 1, "{type}"},
+		{"dnstap dnstap.sock {\nfilter zone\n}", true, 0, ""},
+		{"dnstap dnstap.sock {\nfilter rcode NOPE\n}", true, 0, ""},
+		{"dnstap dnstap.sock {\nextra\n}", true, 0, ""},
+		{"dnstap dnstap.sock {\nextra a b\n}", true, 0, ""},
+	}
+	for i, tc := range tests {
+		c := caddy.NewTestController("dns", tc.in)
+		taps, err := parseConfig(c)
+		if tc.fail {
+			if err == nil {
+				t.Errorf("Test %d: expected error", i)
+			}
+			continue
+		}
+		if err != nil {
+			t.Fatalf("Test %d: expected no error, got %s", i, err)
+		}
+		if x := len(taps[0].filters); x != tc.filters {
+			t.Errorf("Test %d: expected %d filters, got %d", i, tc.filters, x)
+		}
+		if x := taps[0].ExtraFormat; x != tc.extra {
+			t.Errorf("Test %d: expected extra %q, got %q", i, tc.extra, x)
+		}
+	}
+}
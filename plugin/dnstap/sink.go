@@ -0,0 +1,50 @@
+package dnstap
+
+import (
+	"fmt"
+	"net/url"
+	"sync"
+)
+
+// Sink is the destination a dnstap plugin writes its messages to. The built-in sinks write Frame
+// Streams to a unix or tcp socket or to a file; other sinks, like a message queue producer, can be added
+// with RegisterSink. The methods of a Sink are called from a single goroutine.
+type Sink interface {
+	// Open opens the sink. When Write or Flush fail, the sink is closed and Open is called again
+	// to reconnect, with an exponential backoff.
+	Open() error
+	// Write writes frame, a protobuf encoded dnstap message. The sink may buffer it until Flush is called.
+	Write(frame []byte) error
+	// Flush writes buffered messages. It is called at least every second.
+	Flush() error
+	// Close closes the sink.
+	Close() error
+}
+
+// SinkFunc returns a new Sink for the endpoint u.
+type SinkFunc func(u *url.URL) (Sink, error)
+
+var (
+	sinksMu sync.RWMutex
+	sinks   = map[string]SinkFunc{}
+)
+
+// RegisterSink makes the sink returned by f available to endpoints that use scheme, so dnstap
+// can be configured as "dnstap SCHEME://...". It is meant to be called from the init function of
+// the package implementing the sink. The schemes unix, tcp and file can't be registered.
+func RegisterSink(scheme string, f SinkFunc) {
+	switch scheme {
+	case "unix", "tcp", "file":
+		panic(fmt.Sprintf("dnstap: can't register built-in sink %q", scheme))
+	}
+	sinksMu.Lock()
+	defer sinksMu.Unlock()
+	sinks[scheme] = f
+}
+
+func sinkFunc(scheme string) (SinkFunc, bool) {
+	sinksMu.RLock()
+	defer sinksMu.RUnlock()
+	f, ok := sinks[scheme]
+	return f, ok
+}
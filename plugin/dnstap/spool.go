@@ -0,0 +1,100 @@
+package dnstap
+
+import (
+	"bufio"
+	"encoding/binary"
+	"errors"
+	"io"
+	"os"
+)
+
+// spool keeps dnstap messages on disk while the sink is down. Each message is stored as its length,
+// a 32 bit big endian integer, followed by the message. The spool doesn't grow beyond max bytes,
+// messages that don't fit are dropped.
+type spool struct {
+	path string
+	max  int64
+
+	f    *os.File
+	size int64 // size of the file
+	off  int64 // offset of the first message that hasn't been replayed
+}
+
+// newSpool opens the spool at path. Messages left from a previous run are kept and replayed. The spool
+// is locked while it's open, errLocked is returned if another instance has it open.
+func newSpool(path string, max int64) (*spool, error) {
+	f, err := os.OpenFile(path, os.O_RDWR|os.O_CREATE, 0600)
+	if err != nil {
+		return nil, err
+	}
+	if err := flock(f); err != nil {
+		f.Close()
+		return nil, err
+	}
+	fi, err := f.Stat()
+	if err != nil {
+		f.Close()
+		return nil, err
+	}
+	return &spool{path: path, max: max, f: f, size: fi.Size()}, nil
+}
+
+// add appends frame to the spool and returns false if the spool is full.
+func (s *spool) add(frame []byte) bool {
+	n := int64(4 + len(frame))
+	if s.size+n > s.max {
+		return false
+	}
+	buf := make([]byte, n)
+	binary.BigEndian.PutUint32(buf, uint32(len(frame)))
+	copy(buf[4:], frame)
+	if _, err := s.f.WriteAt(buf, s.size); err != nil {
+		log.Warningf("Failed to spool dnstap message: %s", err)
+		return false
+	}
+	s.size += n
+	return true
+}
+
+// replay calls write for each spooled message, in the order they were added, and returns the number
+// of messages written. If write fails, replay stops and the next replay starts with the message that
+// failed. When all messages are written the spool is emptied.
+func (s *spool) replay(write func([]byte) error) (int, error) {
+	if s.off == s.size {
+		return 0, nil
+	}
+	r := bufio.NewReader(io.NewSectionReader(s.f, s.off, s.size-s.off))
+	n := 0
+	for s.off < s.size {
+		var l uint32
+		if err := binary.Read(r, binary.BigEndian, &l); err != nil {
+			return n, s.corrupt(err)
+		}
+		frame := make([]byte, l)
+		if _, err := io.ReadFull(r, frame); err != nil {
+			return n, s.corrupt(err)
+		}
+		if err := write(frame); err != nil {
+			return n, err
+		}
+		s.off += int64(4 + l)
+		n++
+	}
+	return n, s.truncate()
+}
+
+// corrupt empties the spool, which has a truncated message.
+func (s *spool) corrupt(err error) error {
+	if errors.Is(err, io.EOF) || errors.Is(err, io.ErrUnexpectedEOF) {
+		log.Warningf("Dropping truncated dnstap spool %s", s.path)
+		return s.truncate()
+	}
+	return err
+}
+
+func (s *spool) truncate() error {
+	s.off, s.size = 0, 0
+	return s.f.Truncate(0)
+}
+
+func (s *spool) close() error { return s.f.Close() }
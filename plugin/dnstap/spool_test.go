@@ -0,0 +1,72 @@
+package dnstap
+
+import (
+	"errors"
+	"path/filepath"
+	"testing"
+)
+
+func TestSpool(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "spool")
+	s, err := newSpool(path, 25)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, m := range []string{"one", "two", "three"} {
+		if !s.add([]byte(m)) {
+			t.Fatalf("Expected %q to be spooled", m)
+		}
+	}
+	if s.add([]byte("four")) {
+		t.Fatal("Expected spool to be full")
+	}
+
+	// Fail on the second message, the next replay starts there.
+	got := []string{}
+	fail := errors.New("down")
+	n, err := s.replay(func(b []byte) error {
+		if string(b) == "two" {
+			return fail
+		}
+		got = append(got, string(b))
+		return nil
+	})
+	if n != 1 || err != fail {
+		t.Fatalf("Expected 1 message and an error, got %d, %v", n, err)
+	}
+	s.close()
+
+	// Spooled messages survive a restart.
+	if s, err = newSpool(path, 25); err != nil {
+		t.Fatal(err)
+	}
+	got = []string{}
+	n, err = s.replay(func(b []byte) error { got = append(got, string(b)); return nil })
+	if err != nil {
+		t.Fatal(err)
+	}
+	// The restart lost the offset, so "one" is replayed again.
+	if n != 3 || len(got) != 3 || got[0] != "one" || got[2] != "three" {
+		t.Errorf("Expected 3 messages, got %d: %q", n, got)
+	}
+	if s.size != 0 || !s.add([]byte("four")) {
+		t.Errorf("Expected empty spool after replay, got size %d", s.size)
+	}
+	s.close()
+}
+
+func TestSpoolLocked(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "spool")
+	s, err := newSpool(path, 25)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := newSpool(path, 25); !errors.Is(err, errLocked) {
+		t.Fatalf("Expected %s, got %v", errLocked, err)
+	}
+	s.close()
+	if s, err = newSpool(path, 25); err != nil {
+		t.Fatal(err)
+	}
+	s.close()
+}
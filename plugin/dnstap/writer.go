@@ -1,9 +1,12 @@
 package dnstap
 
 import (
+	"context"
 	"time"
 
 	"github.com/coredns/coredns/plugin/dnstap/msg"
+	"github.com/coredns/coredns/plugin/pkg/dnstest"
+	"github.com/coredns/coredns/request"
 
 	tap "github.com/dnstap/golang-dnstap"
 	"github.com/miekg/dns"
@@ -13,6 +16,9 @@ import (
 type ResponseWriter struct {
 	queryTime time.Time
 	query     *dns.Msg
+	ctx       context.Context
+	held      bool // the query isn't tapped yet, because the filters need the rcode
+	skip      bool // the query didn't match the filters
 	dns.ResponseWriter
 	Dnstap
 }
@@ -24,6 +30,19 @@ func (w *ResponseWriter) WriteMsg(resp *dns.Msg) error {
 		return err
 	}
 
+	state := request.Request{W: w.ResponseWriter, Req: w.query}
+	if w.held {
+		w.held = false
+		if !w.filters.match(state, resp.Rcode) {
+			w.skip = true
+		} else {
+			w.tapQuery(w.ctx, w.ResponseWriter, w.query, w.queryTime)
+		}
+	}
+	if w.skip {
+		return nil
+	}
+
 	r := new(tap.Message)
 	msg.SetQueryTime(r, w.queryTime)
 	msg.SetResponseTime(r, time.Now())
@@ -35,6 +54,11 @@ func (w *ResponseWriter) WriteMsg(resp *dns.Msg) error {
 	}
 
 	msg.SetType(r, tap.Message_CLIENT_RESPONSE)
-	w.TapMessage(r)
+	var rr *dnstest.Recorder
+	if w.ExtraFormat != "" {
+		// Makes the response labels, like {rcode}, available in the extra format.
+		rr = &dnstest.Recorder{ResponseWriter: w.ResponseWriter, Rcode: resp.Rcode, Len: resp.Len(), Msg: resp, Start: w.queryTime}
+	}
+	w.TapMessageWithMetadata(w.ctx, r, state, rr)
 	return nil
 }
@@ -7,6 +7,7 @@ import (
 	"errors"
 	"fmt"
 	"strings"
+	"sync"
 	"time"
 
 	"github.com/coredns/coredns/plugin"
@@ -38,6 +39,9 @@ type Etcd struct {
 	Client     *etcdcv3.Client
 
 	endpoints []string // Stored here as well, to aid in testing.
+
+	readyMu  sync.Mutex
+	readyErr error // result of the last readiness check
 }
 
 // Services implements the ServiceBackend interface.
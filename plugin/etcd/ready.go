@@ -0,0 +1,38 @@
+package etcd
+
+import (
+	"context"
+	"time"
+
+	etcdcv3 "go.etcd.io/etcd/client/v3"
+)
+
+const readyTimeout = 2 * time.Second
+
+// Ready implements the ready.Readiness interface. Etcd is ready when the etcd cluster can be queried.
+// The result is kept for NotReady, so a probe only queries etcd once.
+func (e *Etcd) Ready() bool {
+	err := e.ping()
+	e.readyMu.Lock()
+	e.readyErr = err
+	e.readyMu.Unlock()
+	return err == nil
+}
+
+// NotReady implements the ready.Reporter interface. It returns the error of the last Ready call.
+func (e *Etcd) NotReady() string {
+	e.readyMu.Lock()
+	defer e.readyMu.Unlock()
+	if e.readyErr != nil {
+		return e.readyErr.Error()
+	}
+	return ""
+}
+
+// ping queries the number of keys under the path prefix, to see if the etcd cluster is reachable.
+func (e *Etcd) ping() error {
+	ctx, cancel := context.WithTimeout(context.Background(), readyTimeout)
+	defer cancel()
+	_, err := e.Client.Get(ctx, "/"+e.PathPrefix, etcdcv3.WithPrefix(), etcdcv3.WithCountOnly())
+	return err
+}
@@ -0,0 +1,50 @@
+package etcd
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	etcdcv3 "go.etcd.io/etcd/client/v3"
+)
+
+// fakeKV counts the Get calls and fails them with err.
+type fakeKV struct {
+	etcdcv3.KV
+	gets int
+	err  error
+}
+
+func (f *fakeKV) Get(ctx context.Context, key string, opts ...etcdcv3.OpOption) (*etcdcv3.GetResponse, error) {
+	f.gets++
+	if f.err != nil {
+		return nil, f.err
+	}
+	return &etcdcv3.GetResponse{}, nil
+}
+
+func TestReady(t *testing.T) {
+	kv := &fakeKV{err: errors.New("etcdserver: request timed out")}
+	e := &Etcd{PathPrefix: "skydns", Client: &etcdcv3.Client{KV: kv}}
+
+	if e.Ready() {
+		t.Error("Expected not ready")
+	}
+	if reason := e.NotReady(); reason != kv.err.Error() {
+		t.Errorf("Expected reason %q, got %q", kv.err, reason)
+	}
+	if kv.gets != 1 {
+		t.Errorf("Expected 1 query to etcd, got %d", kv.gets)
+	}
+
+	kv.err = nil
+	if !e.Ready() {
+		t.Error("Expected ready")
+	}
+	if reason := e.NotReady(); reason != "" {
+		t.Errorf("Expected no reason, got %q", reason)
+	}
+	if kv.gets != 2 {
+		t.Errorf("Expected 2 queries to etcd, got %d", kv.gets)
+	}
+}
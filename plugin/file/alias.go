@@ -0,0 +1,149 @@
+package file
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/coredns/coredns/core/dnsserver"
+	"github.com/coredns/coredns/plugin/file/tree"
+	"github.com/coredns/coredns/plugin/pkg/cache"
+	"github.com/coredns/coredns/request"
+
+	"github.com/miekg/dns"
+)
+
+// TypeALIAS is the private use type code of the ALIAS record, the same one PowerDNS uses.
+const TypeALIAS = 65401
+
+// ALIAS is the rdata of an ALIAS record. An ALIAS record points to another name, like a CNAME, but it can
+// coexist with other records, so it can be used at the apex of a zone. A and AAAA queries for its owner
+// are answered with the A and AAAA records of the target, looked up when the query arrives.
+type ALIAS struct {
+	Target string
+}
+
+func init() {
+	dns.PrivateHandle("ALIAS", TypeALIAS, func() dns.PrivateRdata { return new(ALIAS) })
+}
+
+// String implements dns.PrivateRdata.
+func (a *ALIAS) String() string { return a.Target }
+
+// Parse implements dns.PrivateRdata.
+func (a *ALIAS) Parse(txt []string) error {
+	if len(txt) != 1 {
+		return fmt.Errorf("ALIAS needs a single target")
+	}
+	if _, ok := dns.IsDomainName(txt[0]); !ok {
+		return fmt.Errorf("invalid ALIAS target %q", txt[0])
+	}
+	a.Target = txt[0]
+	return nil
+}
+
+// Pack implements dns.PrivateRdata.
+func (a *ALIAS) Pack(buf []byte) (int, error) {
+	return dns.PackDomainName(dns.Fqdn(a.Target), buf, 0, nil, false)
+}
+
+// Unpack implements dns.PrivateRdata.
+func (a *ALIAS) Unpack(buf []byte) (int, error) {
+	target, off, err := dns.UnpackDomainName(buf, 0)
+	if err != nil {
+		return off, err
+	}
+	a.Target = target
+	return off, nil
+}
+
+// Copy implements dns.PrivateRdata.
+func (a *ALIAS) Copy(dest dns.PrivateRdata) error {
+	d, ok := dest.(*ALIAS)
+	if !ok {
+		return dns.ErrRdata
+	}
+	d.Target = a.Target
+	return nil
+}
+
+// Len implements dns.PrivateRdata.
+func (a *ALIAS) Len() int { return len(dns.Fqdn(a.Target)) + 1 }
+
+// aliasCacheSize is the number of ALIAS targets of which the addresses are cached per zone.
+const aliasCacheSize = 1024
+
+// aliasItem holds the cached addresses of an ALIAS target.
+type aliasItem struct {
+	rrs     []dns.RR
+	result  Result
+	expires time.Time
+}
+
+// aliasTarget returns the ALIAS record of elem, if it has one.
+func aliasTarget(elem *tree.Elem) *dns.PrivateRR {
+	rrs := elem.Type(TypeALIAS)
+	if len(rrs) == 0 {
+		return nil
+	}
+	return rrs[0].(*dns.PrivateRR)
+}
+
+// aliasLookup answers the A or AAAA query in state for the owner of alias, with the records of its target.
+// The records are cached for their TTL, but at most for the TTL of the ALIAS record.
+func (z *Zone) aliasLookup(ctx context.Context, state request.Request, alias *dns.PrivateRR) ([]dns.RR, Result) {
+	qtype := state.QType()
+	target := strings.ToLower(alias.Data.(*ALIAS).Target)
+	key := cache.Hash([]byte(target + "/" + dns.TypeToString[qtype]))
+
+	var item *aliasItem
+	if i, ok := z.aliases.Get(key); ok && time.Now().Before(i.(*aliasItem).expires) {
+		item = i.(*aliasItem)
+	} else {
+		item = z.resolveAlias(ctx, state, target, alias.Hdr.Ttl)
+		if item.result != ServerFailure {
+			z.aliases.Add(key, item)
+		}
+	}
+
+	ttl := uint32(time.Until(item.expires).Seconds())
+	rrs := make([]dns.RR, len(item.rrs))
+	for i, rr := range item.rrs {
+		rrs[i] = dns.Copy(rr)
+		rrs[i].Header().Name = alias.Hdr.Name
+		rrs[i].Header().Ttl = ttl
+	}
+	return rrs, item.result
+}
+
+// resolveAlias looks up the addresses of target, ttl is the maximum TTL of the returned records.
+func (z *Zone) resolveAlias(ctx context.Context, state request.Request, target string, ttl uint32) *aliasItem {
+	loop, _ := ctx.Value(dnsserver.LoopKey{}).(int)
+	ctx = context.WithValue(ctx, dnsserver.LoopKey{}, loop+1)
+
+	qtype := state.QType()
+	lookupRRs, result := z.doLookup(ctx, state, target, qtype)
+	switch result {
+	case ServerFailure:
+		return &aliasItem{result: ServerFailure}
+	case NameError:
+		// The owner of the ALIAS exists, so a target that doesn't is NODATA.
+		result = NoData
+	}
+
+	rrs := []dns.RR{}
+	for _, rr := range lookupRRs {
+		if rr.Header().Rrtype != qtype {
+			continue
+		}
+		if rr.Header().Ttl < ttl {
+			ttl = rr.Header().Ttl
+		}
+		rrs = append(rrs, rr)
+	}
+	if len(rrs) == 0 {
+		result = NoData
+	}
+	return &aliasItem{rrs: rrs, result: result, expires: time.Now().Add(time.Duration(ttl) * time.Second)}
+}
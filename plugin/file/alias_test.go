@@ -0,0 +1,139 @@
+package file
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/coredns/coredns/plugin/pkg/cache"
+	"github.com/coredns/coredns/plugin/pkg/dnstest"
+	"github.com/coredns/coredns/plugin/test"
+
+	"github.com/miekg/dns"
+)
+
+const dbAliasExampleOrg = `
+$TTL    1M
+$ORIGIN example.org.
+@       IN      SOA     ns1.example.org. hostmaster.example.org. (
+                             2017042745 ; serial
+                             7200       ; refresh (2 hours)
+                             3600       ; retry (1 hour)
+                             1209600    ; expire (2 weeks)
+                             3600       ; minimum (1 hour)
+                             )
+
+        3600 IN NS      ns1.example.org.
+        300  IN ALIAS   cdn.example.net.
+        300  IN MX      10 mx.example.org.
+ns1     3600 IN A       127.0.0.1
+www     300  IN ALIAS   web
+self    300  IN ALIAS   @
+`
+
+func TestAliasInsert(t *testing.T) {
+	zone, err := Parse(strings.NewReader(dbAliasExampleOrg), "example.org.", "stdin", 0)
+	if err != nil {
+		t.Fatalf("Expected no error when reading zone, got %q", err)
+	}
+
+	tests := []struct {
+		owner  string
+		target string
+	}{
+		{"example.org.", "cdn.example.net."},
+		{"www.example.org.", "web.example.org."},
+		{"self.example.org.", "example.org."},
+	}
+	for _, tc := range tests {
+		elem, _ := zone.Tree.Search(tc.owner)
+		if elem == nil {
+			t.Fatalf("Expected %s to exist", tc.owner)
+		}
+		alias := aliasTarget(elem)
+		if alias == nil {
+			t.Fatalf("Expected an ALIAS record at %s", tc.owner)
+		}
+		if x := alias.Data.(*ALIAS).Target; x != tc.target {
+			t.Errorf("Expected ALIAS target %s for %s, got %s", tc.target, tc.owner, x)
+		}
+	}
+}
+
+func TestAliasPackUnpack(t *testing.T) {
+	rr, err := dns.NewRR("example.org. 300 IN ALIAS cdn.example.net.")
+	if err != nil {
+		t.Fatalf("Expected no error parsing ALIAS record, got %q", err)
+	}
+	buf := make([]byte, 512)
+	off, err := dns.PackRR(rr, buf, 0, nil, false)
+	if err != nil {
+		t.Fatalf("Expected no error packing ALIAS record, got %q", err)
+	}
+	rr1, _, err := dns.UnpackRR(buf[:off], 0)
+	if err != nil {
+		t.Fatalf("Expected no error unpacking ALIAS record, got %q", err)
+	}
+	if rr.String() != rr1.String() {
+		t.Errorf("Expected %q, got %q", rr.String(), rr1.String())
+	}
+}
+
+func TestLookupAlias(t *testing.T) {
+	zone, err := Parse(strings.NewReader(dbAliasExampleOrg), "example.org.", "stdin", 0)
+	if err != nil {
+		t.Fatalf("Expected no error when reading zone, got %q", err)
+	}
+	fm := File{Next: test.ErrorHandler(), Zones: Zones{Z: map[string]*Zone{"example.org.": zone}, Names: []string{"example.org."}}}
+	ctx := context.TODO()
+
+	// Without a running server the target can't be looked up.
+	m := new(dns.Msg)
+	m.SetQuestion("example.org.", dns.TypeA)
+	rec := dnstest.NewRecorder(&test.ResponseWriter{})
+	if code, _ := fm.ServeDNS(ctx, rec, m); code != dns.RcodeServerFailure {
+		t.Errorf("Expected %s without an upstream, got %s", dns.RcodeToString[dns.RcodeServerFailure], dns.RcodeToString[code])
+	}
+
+	// Other types than A and AAAA are answered from the zone.
+	m.SetQuestion("example.org.", dns.TypeMX)
+	rec = dnstest.NewRecorder(&test.ResponseWriter{})
+	if _, err := fm.ServeDNS(ctx, rec, m); err != nil {
+		t.Fatalf("Expected no error, got %q", err)
+	}
+	if len(rec.Msg.Answer) != 1 || rec.Msg.Answer[0].Header().Rrtype != dns.TypeMX {
+		t.Errorf("Expected a single MX record, got %v", rec.Msg.Answer)
+	}
+
+	// Seed the cache as if the target was looked up.
+	item := &aliasItem{
+		rrs:     []dns.RR{test.A("cdn.example.net. 60 IN A 192.0.2.1")},
+		result:  Success,
+		expires: time.Now().Add(60 * time.Second),
+	}
+	zone.aliases.Add(cache.Hash([]byte("cdn.example.net./A")), item)
+
+	m.SetQuestion("example.org.", dns.TypeA)
+	rec = dnstest.NewRecorder(&test.ResponseWriter{})
+	if _, err := fm.ServeDNS(ctx, rec, m); err != nil {
+		t.Fatalf("Expected no error, got %q", err)
+	}
+	if len(rec.Msg.Answer) != 1 {
+		t.Fatalf("Expected 1 answer, got %v", rec.Msg.Answer)
+	}
+	a := rec.Msg.Answer[0]
+	if a.Header().Name != "example.org." || a.(*dns.A).A.String() != "192.0.2.1" {
+		t.Errorf("Expected A record for example.org. with 192.0.2.1, got %s", a)
+	}
+	if a.Header().Ttl > 60 {
+		t.Errorf("Expected TTL of at most 60, got %d", a.Header().Ttl)
+	}
+	if !rec.Msg.Authoritative {
+		t.Errorf("Expected authoritative answer")
+	}
+	// The cached records are not modified.
+	if item.rrs[0].Header().Name != "cdn.example.net." {
+		t.Errorf("Expected cached record to be left alone, got %s", item.rrs[0])
+	}
+}
@@ -5,8 +5,10 @@ import (
 	"context"
 	"fmt"
 	"io"
+	"time"
 
 	"github.com/coredns/coredns/plugin"
+	"github.com/coredns/coredns/plugin/dnstap"
 	clog "github.com/coredns/coredns/plugin/pkg/log"
 	"github.com/coredns/coredns/plugin/transfer"
 	"github.com/coredns/coredns/request"
@@ -22,6 +24,7 @@ type (
 		Next plugin.Handler
 		Zones
 		transfer *transfer.Transfer
+		tap      *dnstap.Taps
 	}
 
 	// Zones maps zone names to a *Zone.
@@ -34,6 +37,7 @@ type (
 // ServeDNS implements the plugin.Handle interface.
 func (f File) ServeDNS(ctx context.Context, w dns.ResponseWriter, r *dns.Msg) (int, error) {
 	state := request.Request{W: w, Req: r}
+	start := time.Now()
 
 	qname := state.Name()
 	// TODO(miek): match the qname better in the map
@@ -54,26 +58,7 @@ func (f File) ServeDNS(ctx context.Context, w dns.ResponseWriter, r *dns.Msg) (i
 
 	// This is only for when we are a secondary zones.
 	if r.Opcode == dns.OpcodeNotify {
-		if z.isNotify(state) {
-			m := new(dns.Msg)
-			m.SetReply(r)
-			m.Authoritative = true
-			w.WriteMsg(m)
-
-			log.Infof("Notify from %s for %s: checking transfer", state.IP(), zone)
-			ok, err := z.shouldTransfer()
-			if ok {
-				z.TransferIn()
-			} else {
-				log.Infof("Notify from %s for %s: no SOA serial increase seen", state.IP(), zone)
-			}
-			if err != nil {
-				log.Warningf("Notify from %s for %s: failed primary check: %s", state.IP(), zone, err)
-			}
-			return dns.RcodeSuccess, nil
-		}
-		log.Infof("Dropping notify from %s for %s", state.IP(), zone)
-		return dns.RcodeSuccess, nil
+		return z.serveNotify(state)
 	}
 
 	z.RLock()
@@ -109,6 +94,7 @@ func (f File) ServeDNS(ctx context.Context, w dns.ResponseWriter, r *dns.Msg) (i
 		m.Rcode = dns.RcodeServerFailure
 	}
 
+	f.tap.TapAuth(ctx, state, m, start)
 	w.WriteMsg(m)
 	return dns.RcodeSuccess, nil
 }
@@ -116,6 +102,13 @@ func (f File) ServeDNS(ctx context.Context, w dns.ResponseWriter, r *dns.Msg) (i
 // Name implements the Handler interface.
 func (f File) Name() string { return "file" }
 
+// Watch implements the watch.Watchable interface.
+func (f File) Watch(fn func(name string)) {
+	for _, z := range f.Zones.Z {
+		z.Watch(fn)
+	}
+}
+
 type serialErr struct {
 	err    string
 	zone   string
@@ -185,6 +185,17 @@ func (z *Zone) Lookup(ctx context.Context, state request.Request, qname string)
 
 		rrs := elem.Type(qtype)
 
+		if alias := aliasTarget(elem); alias != nil && len(rrs) == 0 && (qtype == dns.TypeA || qtype == dns.TypeAAAA) {
+			rrs, result := z.aliasLookup(ctx, state, alias)
+			switch result {
+			case ServerFailure:
+				return nil, nil, nil, ServerFailure
+			case NoData:
+				return nil, ap.soa(do), nil, NoData
+			}
+			return rrs, ap.ns(do), nil, Success
+		}
+
 		// NODATA
 		if len(rrs) == 0 {
 			ret := ap.soa(do)
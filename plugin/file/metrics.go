@@ -0,0 +1,47 @@
+package file
+
+import (
+	"github.com/coredns/coredns/plugin"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+)
+
+// These metrics are for the zones of the secondary plugin, which are transferred by the code in this package.
+var (
+	// transferCount is the number of successful transfers of each secondary zone.
+	transferCount = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: plugin.Namespace,
+		Subsystem: "secondary",
+		Name:      "transfers_total",
+		Help:      "Counter of successful zone transfers, by primary and type (axfr or ixfr).",
+	}, []string{"zone", "primary", "type"})
+	// failureCount is the number of failed SOA queries and transfers of each secondary zone.
+	failureCount = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: plugin.Namespace,
+		Subsystem: "secondary",
+		Name:      "failures_total",
+		Help:      "Counter of failed SOA queries and zone transfers, by primary.",
+	}, []string{"zone", "primary"})
+	// transferTimestamp is the time of the last successful transfer of each secondary zone.
+	transferTimestamp = promauto.NewGaugeVec(prometheus.GaugeOpts{
+		Namespace: plugin.Namespace,
+		Subsystem: "secondary",
+		Name:      "last_transfer_timestamp_seconds",
+		Help:      "Unix time of the last successful zone transfer.",
+	}, []string{"zone"})
+	// refreshTimestamp is the time each secondary zone was last found to be up to date.
+	refreshTimestamp = promauto.NewGaugeVec(prometheus.GaugeOpts{
+		Namespace: plugin.Namespace,
+		Subsystem: "secondary",
+		Name:      "last_refresh_timestamp_seconds",
+		Help:      "Unix time the zone was last found to be up to date with a primary.",
+	}, []string{"zone"})
+	// expiredGauge tells if a secondary zone is expired.
+	expiredGauge = promauto.NewGaugeVec(prometheus.GaugeOpts{
+		Namespace: plugin.Namespace,
+		Subsystem: "secondary",
+		Name:      "zone_expired",
+		Help:      "Gauge that is 1 if the zone is expired, 0 if not.",
+	}, []string{"zone"})
+)
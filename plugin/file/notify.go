@@ -2,15 +2,20 @@ package file
 
 import (
 	"net"
+	"time"
 
 	"github.com/coredns/coredns/request"
 
 	"github.com/miekg/dns"
 )
 
+// notifyInterval is the minimum time between the checks of the primaries triggered by NOTIFY messages.
+const notifyInterval = 5 * time.Second
+
 // isNotify checks if state is a notify message and if so, will *also* check if it
 // is from one of the configured masters. If not it will not be a valid notify
 // message. If the zone z is not a secondary zone the message will also be ignored.
+// If z has a TSIG key, the message must be signed with it.
 func (z *Zone) isNotify(state request.Request) bool {
 	if state.Req.Opcode != dns.OpcodeNotify {
 		return false
@@ -18,6 +23,12 @@ func (z *Zone) isNotify(state request.Request) bool {
 	if len(z.TransferFrom) == 0 {
 		return false
 	}
+	if z.TransferKey != nil {
+		t := state.Req.IsTsig()
+		if t == nil || dns.CanonicalName(t.Hdr.Name) != z.TransferKey.Name || state.W.TsigStatus() != nil {
+			return false
+		}
+	}
 	// If remote IP matches we accept.
 	remote := state.IP()
 	for _, f := range z.TransferFrom {
@@ -31,3 +42,33 @@ func (z *Zone) isNotify(state request.Request) bool {
 	}
 	return false
 }
+
+// serveNotify answers the NOTIFY in state. A valid NOTIFY makes the zone check its primaries for a newer
+// version right away, instead of at the next refresh; others are refused.
+func (z *Zone) serveNotify(state request.Request) (int, error) {
+	if !z.isNotify(state) {
+		log.Infof("Refusing notify from %s for %s", state.IP(), z.origin)
+		return dns.RcodeRefused, nil
+	}
+
+	m := new(dns.Msg)
+	m.SetReply(state.Req)
+	m.Authoritative = true
+	if t := state.Req.IsTsig(); t != nil {
+		m.SetTsig(t.Hdr.Name, t.Algorithm, 300, time.Now().Unix())
+	}
+	state.W.WriteMsg(m)
+
+	log.Infof("Notify from %s for %s: checking transfer", state.IP(), z.origin)
+	z.notified()
+	return dns.RcodeSuccess, nil
+}
+
+// notified makes Update check the primaries. Notifications that arrive while a check is pending are
+// merged with it.
+func (z *Zone) notified() {
+	select {
+	case z.notify <- struct{}{}:
+	default:
+	}
+}
@@ -0,0 +1,21 @@
+package file
+
+import "strings"
+
+// Ready implements the ready.Readiness interface. File is ready when all its zones have been loaded.
+func (f File) Ready() bool { return len(f.notLoaded()) == 0 }
+
+// NotReady implements the ready.Reporter interface.
+func (f File) NotReady() string { return "zones not loaded: " + strings.Join(f.notLoaded(), ", ") }
+
+// notLoaded returns the zones that don't have a SOA record yet, i.e. haven't been read from disk
+// or transferred from a primary.
+func (f File) notLoaded() []string {
+	var zones []string
+	for _, name := range f.Zones.Names {
+		if f.Zones.Z[name].SOASerialIfDefined() == -1 {
+			zones = append(zones, name)
+		}
+	}
+	return zones
+}
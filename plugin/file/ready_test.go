@@ -0,0 +1,29 @@
+package file
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestReady(t *testing.T) {
+	zone, err := Parse(strings.NewReader(dbMiekNL), "miek.nl.", "stdin", 0)
+	if err != nil {
+		t.Fatalf("Expected no error when reading zone, got %q", err)
+	}
+	f := File{Zones: Zones{
+		Z:     map[string]*Zone{"miek.nl.": zone, "example.org.": NewZone("example.org.", "db.example.org")},
+		Names: []string{"miek.nl.", "example.org."},
+	}}
+
+	if f.Ready() {
+		t.Errorf("Expected file not to be ready with an empty zone")
+	}
+	if reason := f.NotReady(); reason != "zones not loaded: example.org." {
+		t.Errorf("Unexpected reason %q", reason)
+	}
+
+	f.Zones.Z["example.org."] = zone
+	if !f.Ready() {
+		t.Errorf("Expected file to be ready, got %q", f.NotReady())
+	}
+}
@@ -5,6 +5,7 @@ import (
 	"path/filepath"
 	"time"
 
+	"github.com/coredns/coredns/plugin"
 	"github.com/coredns/coredns/plugin/transfer"
 )
 
@@ -19,35 +20,7 @@ func (z *Zone) Reload(t *transfer.Transfer) error {
 		for {
 			select {
 			case <-tick.C:
-				zFile := z.File()
-				reader, err := os.Open(filepath.Clean(zFile))
-				if err != nil {
-					log.Errorf("Failed to open zone %q in %q: %v", z.origin, zFile, err)
-					continue
-				}
-
-				serial := z.SOASerialIfDefined()
-				zone, err := Parse(reader, z.origin, zFile, serial)
-				reader.Close()
-				if err != nil {
-					if _, ok := err.(*serialErr); !ok {
-						log.Errorf("Parsing zone %q: %v", z.origin, err)
-					}
-					continue
-				}
-
-				// copy elements we need
-				z.Lock()
-				z.Apex = zone.Apex
-				z.Tree = zone.Tree
-				z.Unlock()
-
-				log.Infof("Successfully reloaded zone %q in %q with %d SOA serial", z.origin, zFile, z.Apex.SOA.Serial)
-				if t != nil {
-					if err := t.Notify(z.origin); err != nil {
-						log.Warningf("Failed sending notifies: %s", err)
-					}
-				}
+				z.reloadFile(t)
 
 			case <-z.reloadShutdown:
 				tick.Stop()
@@ -58,6 +31,50 @@ func (z *Zone) Reload(t *transfer.Transfer) error {
 	return nil
 }
 
+// reloadFile reads the zone from disk, or from its source if it has one, and sets it live when its SOA
+// serial has changed. It returns true when the zone was reloaded.
+func (z *Zone) reloadFile(t *transfer.Transfer) (bool, error) {
+	if z.src != nil {
+		return z.reloadSource(t)
+	}
+	zFile := z.File()
+	reader, err := os.Open(filepath.Clean(zFile))
+	if err != nil {
+		log.Errorf("Failed to open zone %q in %q: %v", z.origin, zFile, err)
+		return false, err
+	}
+
+	serial := z.SOASerialIfDefined()
+	zone, err := Parse(reader, z.origin, zFile, serial)
+	reader.Close()
+	if err != nil {
+		if _, ok := err.(*serialErr); !ok {
+			log.Errorf("Parsing zone %q: %v", z.origin, err)
+			return false, err
+		}
+		return false, nil
+	}
+	if err := zone.Validate(z.Validation, serial); err != nil {
+		log.Errorf("Not reloading zone %q: %v", z.origin, err)
+		return false, err
+	}
+
+	// copy elements we need
+	z.Lock()
+	z.Apex = zone.Apex
+	z.Tree = zone.Tree
+	z.Unlock()
+	z.Changed(z.origin)
+
+	log.Infof("Successfully reloaded zone %q in %q with %d SOA serial", z.origin, zFile, z.Apex.SOA.Serial)
+	if t != nil {
+		if err := t.Notify(z.origin); err != nil {
+			log.Warningf("Failed sending notifies: %s", err)
+		}
+	}
+	return true, nil
+}
+
 // SOASerialIfDefined returns the SOA's serial if the zone has a SOA record in the Apex, or -1 otherwise.
 func (z *Zone) SOASerialIfDefined() int64 {
 	z.RLock()
@@ -67,3 +84,40 @@ func (z *Zone) SOASerialIfDefined() int64 {
 	}
 	return -1
 }
+
+// Reload reloads zone, or all zones if zone is empty, right away. Zones read from disk are reloaded when
+// their SOA serial has changed, secondary zones are transferred when the primary has a newer SOA serial.
+// It returns the zones that were reloaded.
+func (f File) Reload(zone string) ([]string, error) {
+	var reloaded []string
+	for _, name := range f.Zones.Names {
+		if zone != "" && plugin.Name(zone).Normalize() != name {
+			continue
+		}
+		z := f.Zones.Z[name]
+
+		if len(z.TransferFrom) > 0 {
+			ok, err := z.shouldTransfer()
+			if err != nil {
+				return reloaded, err
+			}
+			if !ok {
+				continue
+			}
+			if err := z.TransferIn(); err != nil {
+				return reloaded, err
+			}
+			reloaded = append(reloaded, name)
+			continue
+		}
+
+		ok, err := z.reloadFile(f.transfer)
+		if err != nil {
+			return reloaded, err
+		}
+		if ok {
+			reloaded = append(reloaded, name)
+		}
+	}
+	return reloaded, nil
+}
@@ -77,6 +77,54 @@ func TestZoneReloadSOAChange(t *testing.T) {
 	}
 }
 
+func TestFileReload(t *testing.T) {
+	fileName, rm, err := test.TempFile(".", reloadZoneTest)
+	if err != nil {
+		t.Fatalf("Failed to create zone: %s", err)
+	}
+	defer rm()
+	reader, err := os.Open(fileName)
+	if err != nil {
+		t.Fatalf("Failed to open zone: %s", err)
+	}
+	z, err := Parse(reader, "miek.nl.", fileName, 0)
+	reader.Close()
+	if err != nil {
+		t.Fatalf("Failed to parse zone: %s", err)
+	}
+	f := File{Zones: Zones{Z: map[string]*Zone{"miek.nl.": z}, Names: []string{"miek.nl."}}}
+	var changed []string
+	f.Watch(func(name string) { changed = append(changed, name) })
+
+	// Unchanged serial, nothing is reloaded.
+	if zones, err := f.Reload(""); err != nil || len(zones) != 0 {
+		t.Fatalf("Expected no reloaded zones, got %v (%v)", zones, err)
+	}
+	if len(changed) != 0 {
+		t.Fatalf("Expected no changes to be signalled, got %v", changed)
+	}
+
+	if err := os.WriteFile(fileName, []byte(reloadZone2Test), 0644); err != nil {
+		t.Fatalf("Failed to write new zone data: %s", err)
+	}
+	if zones, err := f.Reload("example.org."); err != nil || len(zones) != 0 {
+		t.Fatalf("Expected no reloaded zones, got %v (%v)", zones, err)
+	}
+	zones, err := f.Reload("MIEK.nl")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(zones) != 1 || zones[0] != "miek.nl." {
+		t.Fatalf("Expected miek.nl. to be reloaded, got %v", zones)
+	}
+	if serial := z.SOASerialIfDefined(); serial != 1460175182 {
+		t.Errorf("Expected serial 1460175182, got %d", serial)
+	}
+	if len(changed) != 1 || changed[0] != "miek.nl." {
+		t.Errorf("Expected a change of miek.nl. to be signalled, got %v", changed)
+	}
+}
+
 const reloadZoneTest = `miek.nl.		1627	IN	SOA	linode.atoom.net. miek.miek.nl. 1460175181 14400 3600 604800 14400
 miek.nl.		1627	IN	NS	ext.ns.whyscream.net.
 miek.nl.		1627	IN	NS	omval.tednet.nl.
@@ -1,63 +1,216 @@
 package file
 
 import (
+	"bufio"
+	"errors"
+	"fmt"
 	"math/rand"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
 	"time"
 
+	"github.com/coredns/coredns/plugin/file/tree"
+
 	"github.com/miekg/dns"
 )
 
-// TransferIn retrieves the zone from the masters, parses it and sets it live.
+// TransferIn retrieves the zone from the primaries, parses it and sets it live. When the zone already has
+// an SOA record an IXFR is requested, falling back to AXFR if that fails.
 func (z *Zone) TransferIn() error {
 	if len(z.TransferFrom) == 0 {
 		return nil
 	}
+
+	var Err error
+	for _, tr := range z.primaries.order(z.TransferFrom) {
+		z1, xfr, err := z.transferFrom(tr)
+		if err != nil {
+			log.Errorf("Failed to transfer `%s' from %q: %v", z.origin, tr, err)
+			z.primaries.failed(tr)
+			failureCount.WithLabelValues(z.origin, tr).Inc()
+			Err = err
+			continue
+		}
+		z.primaries.ok(tr)
+
+		if z1 == nil { // IXFR says we're up to date
+			z.refreshed()
+			return nil
+		}
+
+		z.Lock()
+		z.Tree = z1.Tree
+		z.Apex = z1.Apex
+		z.Expired = false
+		z.Unlock()
+		z.refreshed()
+		z.Changed(z.origin)
+
+		transferCount.WithLabelValues(z.origin, tr, xfr).Inc()
+		transferTimestamp.WithLabelValues(z.origin).Set(float64(time.Now().Unix()))
+		log.Infof("Transferred: %s from %s", z.origin, tr)
+		z.persist()
+		return nil
+	}
+	return Err
+}
+
+// transferFrom transfers the zone from primary tr. It returns the new zone and the kind of transfer
+// used, or a nil zone if an IXFR showed the zone is up to date.
+func (z *Zone) transferFrom(tr string) (*Zone, string, error) {
+	z.RLock()
+	soa := z.Apex.SOA
+	z.RUnlock()
+
+	if soa != nil {
+		z1, err := z.ixfr(tr, soa)
+		if err == nil {
+			return z1, "ixfr", nil
+		}
+		log.Warningf("Failed IXFR of `%s' from %q, falling back to AXFR: %v", z.origin, tr, err)
+	}
+	z1, err := z.axfr(tr)
+	return z1, "axfr", err
+}
+
+// axfr transfers the complete zone from primary tr.
+func (z *Zone) axfr(tr string) (*Zone, error) {
 	m := new(dns.Msg)
 	m.SetAxfr(z.origin)
+	rrs, err := z.in(m, tr)
+	if err != nil {
+		return nil, err
+	}
 
 	z1 := z.CopyWithoutApex()
-	var (
-		Err error
-		tr  string
-	)
+	for _, rr := range rrs {
+		if err := z1.Insert(rr); err != nil {
+			return nil, err
+		}
+	}
+	if z1.Apex.SOA == nil {
+		return nil, errors.New("no SOA record in transfer")
+	}
+	return z1, nil
+}
 
-Transfer:
-	for _, tr = range z.TransferFrom {
-		t := new(dns.Transfer)
-		c, err := t.In(m, tr)
-		if err != nil {
-			log.Errorf("Failed to setup transfer `%s' with `%q': %v", z.origin, tr, err)
-			Err = err
-			continue Transfer
+// ixfr requests the changes since soa from primary tr (RFC 1995), and returns the zone with those
+// applied. It returns nil if the zone is up to date.
+func (z *Zone) ixfr(tr string, soa *dns.SOA) (*Zone, error) {
+	m := new(dns.Msg)
+	m.SetIxfr(z.origin, soa.Serial, soa.Ns, soa.Mbox)
+	rrs, err := z.in(m, tr)
+	if err != nil {
+		return nil, err
+	}
+	if len(rrs) == 0 {
+		return nil, errors.New("empty IXFR response")
+	}
+	last, ok := rrs[0].(*dns.SOA)
+	if !ok {
+		return nil, errors.New("IXFR response does not start with an SOA record")
+	}
+
+	z1 := z.CopyWithoutApex()
+	switch {
+	case len(rrs) == 1:
+		if !less(soa.Serial, last.Serial) {
+			return nil, nil
 		}
-		for env := range c {
-			if env.Error != nil {
-				log.Errorf("Failed to transfer `%s' from %q: %v", z.origin, tr, env.Error)
-				Err = env.Error
-				continue Transfer
-			}
-			for _, rr := range env.RR {
-				if err := z1.Insert(rr); err != nil {
-					log.Errorf("Failed to parse transfer `%s' from: %q: %v", z.origin, tr, err)
-					Err = err
-					continue Transfer
-				}
+		return nil, errors.New("IXFR response has only an SOA record with a newer serial")
+
+	case rrs[1].Header().Rrtype != dns.TypeSOA: // full zone, as with AXFR
+		for _, rr := range rrs {
+			if err := z1.Insert(rr); err != nil {
+				return nil, err
 			}
 		}
-		Err = nil
-		break
+		return z1, nil
 	}
-	if Err != nil {
-		return Err
+
+	if first := rrs[1].(*dns.SOA); first.Serial != soa.Serial {
+		return nil, fmt.Errorf("IXFR starts at serial %d, not at %d", first.Serial, soa.Serial)
 	}
 
-	z.Lock()
-	z.Tree = z1.Tree
-	z.Apex = z1.Apex
-	z.Expired = false
-	z.Unlock()
-	log.Infof("Transferred: %s from %s", z.origin, tr)
-	return nil
+	// The response is SOA(new), followed by sequences of SOA(old), deletions, SOA(newer), additions
+	// and ends with SOA(new).
+	records := make(map[string]dns.RR)
+	for _, rr := range z.all() {
+		if rr.Header().Rrtype != dns.TypeSOA {
+			records[rrKey(rr)] = rr
+		}
+	}
+	del := false
+	for _, rr := range rrs[1 : len(rrs)-1] {
+		if rr.Header().Rrtype == dns.TypeSOA {
+			del = !del
+			continue
+		}
+		if del {
+			delete(records, rrKey(rr))
+		} else {
+			records[rrKey(rr)] = rr
+		}
+	}
+
+	if err := z1.Insert(last); err != nil {
+		return nil, err
+	}
+	for _, rr := range records {
+		if err := z1.Insert(rr); err != nil {
+			return nil, err
+		}
+	}
+	return z1, nil
+}
+
+// in sends the transfer request m to tr and returns all records received.
+func (z *Zone) in(m *dns.Msg, tr string) ([]dns.RR, error) {
+	t := new(dns.Transfer)
+	var v *tsigVerifier
+	if z.TransferKey != nil {
+		v = z.TransferKey.sign(m)
+		t.TsigProvider = v
+	}
+	c, err := t.In(m, tr)
+	if err != nil {
+		return nil, err
+	}
+
+	var rrs []dns.RR
+	for env := range c {
+		if env.Error != nil {
+			return nil, env.Error
+		}
+		rrs = append(rrs, env.RR...)
+	}
+	return rrs, v.check()
+}
+
+// all returns all records of z, starting with the SOA record.
+func (z *Zone) all() []dns.RR {
+	z.RLock()
+	defer z.RUnlock()
+
+	if z.Apex.SOA == nil {
+		return nil
+	}
+	rrs := []dns.RR{z.Apex.SOA}
+	rrs = append(rrs, z.Apex.SIGSOA...)
+	rrs = append(rrs, z.Apex.NS...)
+	rrs = append(rrs, z.Apex.SIGNS...)
+	z.Walk(func(e *tree.Elem, _ map[uint16][]dns.RR) error { rrs = append(rrs, e.All()...); return nil })
+	return rrs
+}
+
+// rrKey returns a key for rr that is the same for equal records, regardless of their TTL and case.
+func rrKey(rr dns.RR) string {
+	rr = dns.Copy(rr)
+	rr.Header().Ttl = 0
+	return strings.ToLower(rr.String())
 }
 
 // shouldTransfer checks the primaries of zone, retrieves the SOA record, checks the current serial
@@ -65,23 +218,37 @@ Transfer:
 func (z *Zone) shouldTransfer() (bool, error) {
 	c := new(dns.Client)
 	c.Net = "tcp" // do this query over TCP to minimize spoofing
-	m := new(dns.Msg)
-	m.SetQuestion(z.origin, dns.TypeSOA)
 
 	var Err error
 	serial := -1
 
 Transfer:
-	for _, tr := range z.TransferFrom {
-		Err = nil
+	for _, tr := range z.primaries.order(z.TransferFrom) {
+		m := new(dns.Msg)
+		m.SetQuestion(z.origin, dns.TypeSOA)
+		var v *tsigVerifier
+		if z.TransferKey != nil {
+			v = z.TransferKey.sign(m)
+			c.TsigProvider = v
+		}
 		ret, _, err := c.Exchange(m, tr)
-		if err != nil || ret.Rcode != dns.RcodeSuccess {
+		if err == nil && ret.Rcode != dns.RcodeSuccess {
+			err = fmt.Errorf("rcode %s from %q", dns.RcodeToString[ret.Rcode], tr)
+		}
+		if err == nil {
+			err = v.check()
+		}
+		if err != nil {
+			z.primaries.failed(tr)
+			failureCount.WithLabelValues(z.origin, tr).Inc()
 			Err = err
 			continue
 		}
+		z.primaries.ok(tr)
 		for _, a := range ret.Answer {
 			if a.Header().Rrtype == dns.TypeSOA {
 				serial = int(a.(*dns.SOA).Serial)
+				Err = nil
 				break Transfer
 			}
 		}
@@ -89,10 +256,18 @@ Transfer:
 	if serial == -1 {
 		return false, Err
 	}
-	if z.Apex.SOA == nil {
+
+	z.RLock()
+	soa := z.Apex.SOA
+	z.RUnlock()
+	if soa == nil {
 		return true, Err
 	}
-	return less(z.Apex.SOA.Serial, uint32(serial)), Err
+	if !less(soa.Serial, uint32(serial)) {
+		z.refreshed()
+		return false, Err
+	}
+	return true, Err
 }
 
 // less returns true of a is smaller than b when taking RFC 1982 serial arithmetic into account.
@@ -103,94 +278,235 @@ func less(a, b uint32) bool {
 	return (a - b) > MaxSerialIncrement
 }
 
+// refreshed records that the zone is up to date with a primary, which resets its expire timer.
+func (z *Zone) refreshed() {
+	now := time.Now()
+	z.Lock()
+	z.lastRefresh = now
+	z.Expired = false
+	z.Unlock()
+
+	refreshTimestamp.WithLabelValues(z.origin).Set(float64(now.Unix()))
+	expiredGauge.WithLabelValues(z.origin).Set(0)
+	if z.Persist != "" {
+		// The modification time of the persisted zone is the time of the last refresh.
+		os.Chtimes(z.Persist, now, now)
+	}
+}
+
 // Update updates the secondary zone according to its SOA. It will run for the life time of the server
-// and uses the SOA parameters. Every refresh it will check for a new SOA number. If that fails (for all
-// server) it will retry every retry interval. If the zone failed to transfer before the expire, the zone
-// will be marked expired.
+// and uses the SOA parameters. Every refresh, or when a NOTIFY is received, it will check for a new SOA
+// number. If that fails (for all server) it will retry every retry interval. If the zone could not be
+// refreshed before the expire, the zone will be marked expired.
 func (z *Zone) Update() error {
 	// If we don't have a SOA, we don't have a zone, wait for it to appear.
-	for z.Apex.SOA == nil {
+	for z.SOASerialIfDefined() == -1 {
 		time.Sleep(1 * time.Second)
 	}
 	retryActive := false
+	var lastNotify time.Time
 
-Restart:
-	refresh := time.Second * time.Duration(z.Apex.SOA.Refresh)
-	retry := time.Second * time.Duration(z.Apex.SOA.Retry)
-	expire := time.Second * time.Duration(z.Apex.SOA.Expire)
+	for {
+		z.RLock()
+		refresh := time.Second * time.Duration(z.Apex.SOA.Refresh)
+		retry := time.Second * time.Duration(z.Apex.SOA.Retry)
+		expire := time.Second * time.Duration(z.Apex.SOA.Expire)
+		last := z.lastRefresh
+		z.RUnlock()
 
-	refreshTicker := time.NewTicker(refresh)
-	retryTicker := time.NewTicker(retry)
-	expireTicker := time.NewTicker(expire)
+		var wait time.Duration
+		if retryActive {
+			wait = maxDuration(retry, time.Second) + jitter(2000) // 2s randomize
+		} else {
+			// A zone read from disk may be due sooner than a full refresh interval.
+			wait = maxDuration(refresh-time.Since(last), time.Second) + jitter(5000) // 5s randomize
+		}
 
-	for {
+		timer := time.NewTimer(wait)
 		select {
-		case <-expireTicker.C:
-			if !retryActive {
-				break
+		case <-timer.C:
+		case <-z.notify:
+			timer.Stop()
+			if d := notifyInterval - time.Since(lastNotify); d > 0 {
+				time.Sleep(d)
 			}
+			lastNotify = time.Now()
+		}
+
+		ok, err := z.shouldTransfer()
+		if err == nil && ok {
+			err = z.TransferIn()
+		}
+		if err == nil {
+			retryActive = false
+			continue
+		}
+
+		if retryActive {
+			log.Warningf("Failed retry check %s", err)
+		} else {
+			log.Warningf("Failed refresh check %s", err)
+		}
+		retryActive = true
+
+		z.RLock()
+		expired := !z.Expired && time.Since(z.lastRefresh) >= expire
+		z.RUnlock()
+		if expired {
+			log.Errorf("Zone %s expired, no primary could be reached for %s", z.origin, expire)
+			z.Lock()
 			z.Expired = true
+			z.Unlock()
+			expiredGauge.WithLabelValues(z.origin).Set(1)
+			z.Changed(z.origin)
+		}
+	}
+}
 
-		case <-retryTicker.C:
-			if !retryActive {
-				break
-			}
+// jitter returns a random duration between [0,n) * time.Millisecond
+func jitter(n int) time.Duration {
+	r := rand.Intn(n)
+	return time.Duration(r) * time.Millisecond
+}
 
-			time.Sleep(jitter(2000)) // 2s randomize
+// maxDuration returns the longest of a and b.
+func maxDuration(a, b time.Duration) time.Duration {
+	if a > b {
+		return a
+	}
+	return b
+}
 
-			ok, err := z.shouldTransfer()
-			if err != nil {
-				log.Warningf("Failed retry check %s", err)
-				continue
-			}
+// persist writes the zone to z.Persist, if set, so it can be read after a restart.
+func (z *Zone) persist() {
+	if z.Persist == "" {
+		return
+	}
+	if err := z.writeFile(z.Persist); err != nil {
+		log.Errorf("Failed to write `%s' to %q: %v", z.origin, z.Persist, err)
+	}
+}
 
-			if ok {
-				if err := z.TransferIn(); err != nil {
-					// transfer failed, leave retryActive true
-					break
-				}
-			}
+// writeFile writes the zone to path in the zone file format. It writes to a temporary file first, so
+// path is never left half written.
+func (z *Zone) writeFile(path string) error {
+	tmp, err := os.CreateTemp(filepath.Dir(path), filepath.Base(path)+".*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
 
-			// no errors, stop timers and restart
-			retryActive = false
-			refreshTicker.Stop()
-			retryTicker.Stop()
-			expireTicker.Stop()
-			goto Restart
+	w := bufio.NewWriter(tmp)
+	for _, rr := range z.all() {
+		fmt.Fprintln(w, rr.String())
+	}
+	if err := w.Flush(); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	return os.Rename(tmp.Name(), path)
+}
 
-		case <-refreshTicker.C:
+// ReadPersisted loads the zone from z.Persist, where it was written after an earlier transfer. The zone is
+// marked expired if it wasn't refreshed within the expire time of its SOA record.
+func (z *Zone) ReadPersisted() error {
+	f, err := os.Open(filepath.Clean(z.Persist))
+	if err != nil {
+		return err
+	}
+	defer f.Close()
 
-			time.Sleep(jitter(5000)) // 5s randomize
+	fi, err := f.Stat()
+	if err != nil {
+		return err
+	}
+	z1, err := Parse(f, z.origin, z.Persist, 0)
+	if err != nil {
+		return err
+	}
 
-			ok, err := z.shouldTransfer()
-			if err != nil {
-				log.Warningf("Failed refresh check %s", err)
-				retryActive = true
-				continue
-			}
+	expired := time.Since(fi.ModTime()) >= time.Second*time.Duration(z1.Apex.SOA.Expire)
+	z.Lock()
+	z.Tree = z1.Tree
+	z.Apex = z1.Apex
+	z.lastRefresh = fi.ModTime()
+	z.Expired = expired
+	z.Unlock()
 
-			if ok {
-				if err := z.TransferIn(); err != nil {
-					// transfer failed
-					retryActive = true
-					break
-				}
-			}
+	refreshTimestamp.WithLabelValues(z.origin).Set(float64(fi.ModTime().Unix()))
+	if expired {
+		expiredGauge.WithLabelValues(z.origin).Set(1)
+	}
+	return nil
+}
 
-			// no errors, stop timers and restart
-			retryActive = false
-			refreshTicker.Stop()
-			retryTicker.Stop()
-			expireTicker.Stop()
-			goto Restart
+// primaryBackoffMin and primaryBackoffMax bound the time a failing primary isn't used.
+const (
+	primaryBackoffMin = 5 * time.Second
+	primaryBackoffMax = 5 * time.Minute
+)
+
+// primaries keeps track of the primaries of a secondary zone that fail, so they can be skipped for a
+// while, doubling that time on each consecutive failure.
+type primaries struct {
+	sync.Mutex
+	fails map[string]int
+	until map[string]time.Time
+}
+
+// order returns the primaries of addrs that are not backing off, in the configured order. If all of
+// them are, all are returned, the one that is done backing off first, first.
+func (p *primaries) order(addrs []string) []string {
+	p.Lock()
+	defer p.Unlock()
+
+	now := time.Now()
+	up := []string{}
+	down := []string{}
+	for _, a := range addrs {
+		if now.Before(p.until[a]) {
+			down = append(down, a)
+			continue
 		}
+		up = append(up, a)
+	}
+	if len(up) > 0 {
+		return up
 	}
+	sort.SliceStable(down, func(i, j int) bool { return p.until[down[i]].Before(p.until[down[j]]) })
+	return down
 }
 
-// jitter returns a random duration between [0,n) * time.Millisecond
-func jitter(n int) time.Duration {
-	r := rand.Intn(n)
-	return time.Duration(r) * time.Millisecond
+// failed records a failure of primary addr.
+func (p *primaries) failed(addr string) {
+	p.Lock()
+	defer p.Unlock()
+
+	if p.fails == nil {
+		p.fails = make(map[string]int)
+		p.until = make(map[string]time.Time)
+	}
+	p.fails[addr]++
+	d := primaryBackoffMax
+	if n := p.fails[addr]; n < 10 {
+		d = primaryBackoffMin << (n - 1)
+		if d > primaryBackoffMax {
+			d = primaryBackoffMax
+		}
+	}
+	p.until[addr] = time.Now().Add(d)
+}
+
+// ok records a success of primary addr, which ends its backoff.
+func (p *primaries) ok(addr string) {
+	p.Lock()
+	defer p.Unlock()
+
+	delete(p.fails, addr)
+	delete(p.until, addr)
 }
 
 // MaxSerialIncrement is the maximum difference between two serial numbers. If the difference between
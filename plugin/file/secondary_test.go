@@ -2,7 +2,11 @@ package file
 
 import (
 	"fmt"
+	"net"
+	"path/filepath"
+	"strings"
 	"testing"
+	"time"
 
 	"github.com/coredns/coredns/plugin/pkg/dnstest"
 	"github.com/coredns/coredns/plugin/test"
@@ -138,9 +142,216 @@ func TestIsNotify(t *testing.T) {
 	}
 }
 
+func TestIsNotifyTSIG(t *testing.T) {
+	key, err := NewTSIGKey("xfr", "hmac-sha256", "c2VjcmV0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	z := NewZone(testZone, "stdin")
+	z.TransferFrom = []string{"10.240.0.1:53"}
+	z.TransferKey = key
+
+	state := newRequest(testZone, dns.TypeSOA)
+	state.Req.Opcode = dns.OpcodeNotify
+	if z.isNotify(state) {
+		t.Fatal("Unsigned notify should have been invalid")
+	}
+	state.Req.SetTsig("other.", dns.HmacSHA256, 300, time.Now().Unix())
+	if z.isNotify(state) {
+		t.Fatal("Notify signed with another key should have been invalid")
+	}
+	state.Req.Extra = nil
+	state.Req.SetTsig("xfr.", dns.HmacSHA256, 300, time.Now().Unix())
+	if !z.isNotify(state) {
+		t.Fatal("Signed notify should have been valid")
+	}
+}
+
+func TestServeNotify(t *testing.T) {
+	z := NewZone(testZone, "stdin")
+	z.TransferFrom = []string{"10.240.0.1:53"}
+
+	m := new(dns.Msg)
+	m.SetNotify(testZone)
+	rec := dnstest.NewRecorder(&test.ResponseWriter{})
+	state := request.Request{W: rec, Req: m}
+
+	if rcode, _ := z.serveNotify(state); rcode != dns.RcodeSuccess {
+		t.Fatalf("Expected success, got %s", dns.RcodeToString[rcode])
+	}
+	if rec.Msg == nil || rec.Msg.Opcode != dns.OpcodeNotify || !rec.Msg.Authoritative || !rec.Msg.Response {
+		t.Fatalf("Expected an authoritative notify response, got %v", rec.Msg)
+	}
+	// A second notify is merged with the pending one.
+	z.serveNotify(state)
+	if len(z.notify) != 1 {
+		t.Errorf("Expected 1 pending notify, got %d", len(z.notify))
+	}
+
+	z.TransferFrom = []string{"10.240.0.2:53"}
+	<-z.notify
+	if rcode, _ := z.serveNotify(state); rcode != dns.RcodeRefused {
+		t.Errorf("Expected refused, got %s", dns.RcodeToString[rcode])
+	}
+	if len(z.notify) != 0 {
+		t.Errorf("Expected no pending notify, got %d", len(z.notify))
+	}
+}
+
 func newRequest(zone string, qtype uint16) request.Request {
 	m := new(dns.Msg)
 	m.SetQuestion("example.com.", dns.TypeA)
 	m.SetEdns0(4097, true)
 	return request.Request{W: &test.ResponseWriter{}, Req: m}
 }
+
+// ixfrPrimary serves secondary.miek.nl. with serial 251, and the difference with serial 250 as IXFR.
+func ixfrPrimary(w dns.ResponseWriter, req *dns.Msg) {
+	m := new(dns.Msg)
+	m.SetReply(req)
+	soa := func(serial int) dns.RR {
+		return test.SOA(fmt.Sprintf("%s IN SOA bla. bla. %d 0 0 0 0", testZone, serial))
+	}
+	switch req.Question[0].Qtype {
+	case dns.TypeIXFR:
+		m.Answer = []dns.RR{
+			soa(251),
+			soa(250), test.A(fmt.Sprintf("a.%s IN A 127.0.0.1", testZone)),
+			soa(251), test.A(fmt.Sprintf("b.%s IN A 127.0.0.2", testZone)),
+			soa(251),
+		}
+	case dns.TypeAXFR:
+		m.Answer = []dns.RR{soa(251), test.A(fmt.Sprintf("b.%s IN A 127.0.0.2", testZone)), soa(251)}
+	}
+	w.WriteMsg(m)
+}
+
+func TestTransferInIXFR(t *testing.T) {
+	s := dnstest.NewServer(ixfrPrimary)
+	defer s.Close()
+
+	z := NewZone(testZone, "stdin")
+	z.TransferFrom = []string{s.Addr}
+	z.Insert(test.SOA(fmt.Sprintf("%s IN SOA bla. bla. 250 0 0 0 0", testZone)))
+	z.Insert(test.A(fmt.Sprintf("a.%s IN A 127.0.0.1", testZone)))
+	z.Insert(test.A(fmt.Sprintf("c.%s IN A 127.0.0.3", testZone)))
+
+	if err := z.TransferIn(); err != nil {
+		t.Fatalf("Unable to run TransferIn: %v", err)
+	}
+	if z.Apex.SOA.Serial != 251 {
+		t.Errorf("Expected serial 251, got %d", z.Apex.SOA.Serial)
+	}
+	for name, present := range map[string]bool{"a.": false, "b.": true, "c.": true} {
+		e, _ := z.Search(name + testZone)
+		if (e != nil) != present {
+			t.Errorf("Expected %s%s to be present: %t", name, testZone, present)
+		}
+	}
+}
+
+func TestTransferInTSIG(t *testing.T) {
+	const secret = "c2VjcmV0LWtleS1mb3ItdGVzdGluZw=="
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	srv := &dns.Server{Listener: l, TsigSecret: map[string]string{"xfr.": secret}}
+	srv.Handler = dns.HandlerFunc(func(w dns.ResponseWriter, req *dns.Msg) {
+		m := new(dns.Msg)
+		m.SetReply(req)
+		if req.IsTsig() == nil || w.TsigStatus() != nil {
+			m.Rcode = dns.RcodeRefused
+			w.WriteMsg(m)
+			return
+		}
+		m.Answer = []dns.RR{
+			test.SOA(fmt.Sprintf("%s IN SOA bla. bla. 250 0 0 0 0", testZone)),
+			test.A(fmt.Sprintf("%s IN A 127.0.0.1", testZone)),
+			test.SOA(fmt.Sprintf("%s IN SOA bla. bla. 250 0 0 0 0", testZone)),
+		}
+		m.SetTsig("xfr.", dns.HmacSHA256, 300, time.Now().Unix())
+		w.WriteMsg(m)
+	})
+	go srv.ActivateAndServe()
+	defer srv.Shutdown()
+
+	tests := []struct {
+		secret    string
+		shouldErr bool
+	}{
+		{secret, false},
+		{"d3Jvbmcta2V5", true},
+	}
+	for i, tc := range tests {
+		key, err := NewTSIGKey("xfr", "hmac-sha256", tc.secret)
+		if err != nil {
+			t.Fatal(err)
+		}
+		z := NewZone(testZone, "stdin")
+		z.TransferFrom = []string{l.Addr().String()}
+		z.TransferKey = key
+
+		err = z.TransferIn()
+		if tc.shouldErr {
+			if err == nil {
+				t.Errorf("Test %d: expected error, got none", i)
+			}
+			continue
+		}
+		if err != nil {
+			t.Fatalf("Test %d: unable to run TransferIn: %v", i, err)
+		}
+		if z.Apex.SOA == nil || z.Apex.SOA.Serial != 250 {
+			t.Errorf("Test %d: expected the zone to be transferred", i)
+		}
+		if should, err := z.shouldTransfer(); err != nil || should {
+			t.Errorf("Test %d: expected signed SOA query without transfer, got %t, %v", i, should, err)
+		}
+	}
+}
+
+func TestPrimariesBackoff(t *testing.T) {
+	p := primaries{}
+	addrs := []string{"10.0.0.1:53", "10.0.0.2:53"}
+
+	p.failed(addrs[0])
+	if got := p.order(addrs); len(got) != 1 || got[0] != addrs[1] {
+		t.Errorf("Expected only %s, got %v", addrs[1], got)
+	}
+	p.failed(addrs[1])
+	p.failed(addrs[1])
+	if got := p.order(addrs); len(got) != 2 || got[0] != addrs[0] {
+		t.Errorf("Expected all primaries with %s first, got %v", addrs[0], got)
+	}
+	p.ok(addrs[1])
+	if got := p.order(addrs); len(got) != 1 || got[0] != addrs[1] {
+		t.Errorf("Expected only %s, got %v", addrs[1], got)
+	}
+}
+
+func TestPersist(t *testing.T) {
+	z, err := Parse(strings.NewReader(dbMiekNL), testzone, "stdin", 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	path := filepath.Join(t.TempDir(), "db.miek.nl")
+	if err := z.writeFile(path); err != nil {
+		t.Fatalf("Failed to write zone: %s", err)
+	}
+
+	z1 := NewZone(testzone, "stdin")
+	z1.Persist = path
+	if err := z1.ReadPersisted(); err != nil {
+		t.Fatalf("Failed to read zone: %s", err)
+	}
+	if z1.Apex.SOA.Serial != z.Apex.SOA.Serial {
+		t.Errorf("Expected serial %d, got %d", z.Apex.SOA.Serial, z1.Apex.SOA.Serial)
+	}
+	if len(z1.all()) != len(z.all()) {
+		t.Errorf("Expected %d records, got %d", len(z.all()), len(z1.all()))
+	}
+	if z1.Expired {
+		t.Errorf("Expected zone not to be expired")
+	}
+}
@@ -1,14 +1,18 @@
 package file
 
 import (
+	"crypto/ed25519"
 	"errors"
+	"net/http"
 	"os"
 	"path/filepath"
+	"strings"
 	"time"
 
 	"github.com/coredns/caddy"
 	"github.com/coredns/coredns/core/dnsserver"
 	"github.com/coredns/coredns/plugin"
+	"github.com/coredns/coredns/plugin/dnstap"
 	"github.com/coredns/coredns/plugin/pkg/upstream"
 	"github.com/coredns/coredns/plugin/transfer"
 )
@@ -21,7 +25,7 @@ func setup(c *caddy.Controller) error {
 		return plugin.Error("file", err)
 	}
 
-	f := File{Zones: zones}
+	f := File{Zones: zones, tap: dnstap.NewTaps(c)}
 	// get the transfer plugin, so we can send notifies and send notifies on startup as well.
 	c.OnStartup(func() error {
 		t := dnsserver.GetConfig(c).Handler("transfer")
@@ -84,35 +88,14 @@ func fileParse(c *caddy.Controller) (Zones, error) {
 		fileName := c.Val()
 
 		origins := plugin.OriginsFromArgsOrServerBlock(c.RemainingArgs(), c.ServerBlockKeys)
-		if !filepath.IsAbs(fileName) && config.Root != "" {
-			fileName = filepath.Join(config.Root, fileName)
-		}
 
-		reader, err := os.Open(filepath.Clean(fileName))
-		if err != nil {
-			openErr = err
-		}
-
-		err = func() error {
-			defer reader.Close()
-
-			for i := range origins {
-				z[origins[i]] = NewZone(origins[i], fileName)
-				if openErr == nil {
-					reader.Seek(0, 0)
-					zone, err := Parse(reader, origins[i], fileName, 0)
-					if err != nil {
-						return err
-					}
-					z[origins[i]] = zone
-				}
-				names = append(names, origins[i])
-			}
-			return nil
-		}()
-
-		if err != nil {
-			return Zones{}, err
+		var (
+			src        source
+			srcKey     ed25519.PublicKey
+			validation Strictness
+		)
+		if strings.HasPrefix(fileName, "http://") || strings.HasPrefix(fileName, "https://") {
+			src = &httpSource{url: fileName, client: &http.Client{}}
 		}
 
 		for c.NextBlock() {
@@ -130,14 +113,107 @@ func fileParse(c *caddy.Controller) (Zones, error) {
 			case "upstream":
 				// remove soon
 				c.RemainingArgs()
+			case "git":
+				t := c.RemainingArgs()
+				if len(t) < 1 || len(t) > 2 {
+					return Zones{}, c.ArgErr()
+				}
+				if src != nil {
+					return Zones{}, c.Errf("zone is already read from %s", src)
+				}
+				repo := t[0]
+				if !filepath.IsAbs(repo) && config.Root != "" {
+					repo = filepath.Join(config.Root, repo)
+				}
+				ref := "HEAD"
+				if len(t) == 2 {
+					ref = t[1]
+				}
+				src = &gitSource{repo: repo, ref: ref, path: fileName}
+			case "signature":
+				t := c.RemainingArgs()
+				if len(t) != 1 {
+					return Zones{}, c.ArgErr()
+				}
+				keyFile := t[0]
+				if !filepath.IsAbs(keyFile) && config.Root != "" {
+					keyFile = filepath.Join(config.Root, keyFile)
+				}
+				key, err := readPublicKey(keyFile)
+				if err != nil {
+					return Zones{}, c.Errf("failed to read signature key: %v", err)
+				}
+				srcKey = key
+
+			case "validate":
+				t := c.RemainingArgs()
+				if len(t) != 1 {
+					return Zones{}, c.ArgErr()
+				}
+				v, err := ParseStrictness(t[0])
+				if err != nil {
+					return Zones{}, c.Err(err.Error())
+				}
+				validation = v
 
 			default:
 				return Zones{}, c.Errf("unknown property '%s'", c.Val())
 			}
 		}
+		if srcKey != nil && src == nil {
+			return Zones{}, c.Errf("signature is only supported for zones read over HTTP or from Git")
+		}
+
+		if src != nil {
+			for i := range origins {
+				zone := NewZone(origins[i], fileName)
+				zone.src, zone.srcKey = src, srcKey
+				zone.Validation = validation
+				if _, err := zone.reloadSource(nil); err != nil {
+					openErr = err
+				}
+				z[origins[i]] = zone
+				names = append(names, origins[i])
+			}
+		} else {
+			if !filepath.IsAbs(fileName) && config.Root != "" {
+				fileName = filepath.Join(config.Root, fileName)
+			}
+
+			reader, err := os.Open(filepath.Clean(fileName))
+			if err != nil {
+				openErr = err
+			}
+
+			err = func() error {
+				defer reader.Close()
+
+				for i := range origins {
+					z[origins[i]] = NewZone(origins[i], fileName)
+					if openErr == nil {
+						reader.Seek(0, 0)
+						zone, err := Parse(reader, origins[i], fileName, 0)
+						if err != nil {
+							return err
+						}
+						if err := zone.Validate(validation, -1); err != nil {
+							return err
+						}
+						z[origins[i]] = zone
+					}
+					names = append(names, origins[i])
+				}
+				return nil
+			}()
+
+			if err != nil {
+				return Zones{}, err
+			}
+		}
 
 		for i := range origins {
 			z[origins[i]].ReloadInterval = reload
+			z[origins[i]].Validation = validation
 			z[origins[i]].Upstream = upstream.New()
 		}
 	}
@@ -41,7 +41,21 @@ func TestFileParse(t *testing.T) {
 			false,
 			Zones{Names: []string{"10.in-addr.arpa."}},
 		},
+		{
+			`file ` + zoneFileName1 + ` miek.nl. {
+				validate reject
+			}`,
+			false,
+			Zones{Names: []string{"miek.nl."}},
+		},
 		// errors.
+		{
+			`file ` + zoneFileName1 + ` miek.nl. {
+				validate strict
+			}`,
+			true,
+			Zones{},
+		},
 		{
 			`file ` + zoneFileName1 + ` miek.nl {
 				transfer from 127.0.0.1
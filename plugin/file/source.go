@@ -0,0 +1,234 @@
+package file
+
+import (
+	"bytes"
+	"context"
+	"crypto/ed25519"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"os/exec"
+	"strings"
+	"time"
+
+	"github.com/coredns/coredns/plugin/transfer"
+)
+
+// source is a location, other than a file on the local disk, a zone is read from.
+type source interface {
+	// Fetch returns the zone data, and its detached signature if sig is true. When version, as returned
+	// by an earlier fetch, is still current, it returns errNotModified.
+	Fetch(ctx context.Context, version string, sig bool) (*fetched, error)
+	String() string
+}
+
+// fetched is the zone data read from a source.
+type fetched struct {
+	data    []byte
+	sig     []byte
+	version string // ETag or commit hash
+}
+
+var errNotModified = errors.New("not modified")
+
+// fetchTimeout is the maximum time a fetch from a source may take.
+var fetchTimeout = 30 * time.Second
+
+// sigSuffix is appended to the location of the zone data to get the location of its signature.
+const sigSuffix = ".sig"
+
+// httpSource reads a zone from an HTTP(S) URL.
+type httpSource struct {
+	url    string
+	client *http.Client
+}
+
+func (h *httpSource) String() string { return h.url }
+
+func (h *httpSource) Fetch(ctx context.Context, version string, sig bool) (*fetched, error) {
+	data, etag, err := h.get(ctx, h.url, version)
+	if err != nil {
+		return nil, err
+	}
+	f := &fetched{data: data, version: etag}
+	if sig {
+		if f.sig, _, err = h.get(ctx, h.url+sigSuffix, ""); err != nil {
+			return nil, err
+		}
+	}
+	return f, nil
+}
+
+// get gets url, it returns errNotModified when etag is not empty and still matches.
+func (h *httpSource) get(ctx context.Context, url, etag string) ([]byte, string, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return nil, "", err
+	}
+	if etag != "" {
+		req.Header.Set("If-None-Match", etag)
+	}
+	resp, err := h.client.Do(req)
+	if err != nil {
+		return nil, "", err
+	}
+	defer resp.Body.Close()
+
+	switch resp.StatusCode {
+	case http.StatusOK:
+	case http.StatusNotModified:
+		return nil, "", errNotModified
+	default:
+		return nil, "", fmt.Errorf("unexpected status %d from %s", resp.StatusCode, url)
+	}
+	data, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, "", err
+	}
+	return data, resp.Header.Get("ETag"), nil
+}
+
+// gitSource reads a zone from a file in a Git checkout or bare repository, at a ref.
+type gitSource struct {
+	repo string
+	ref  string
+	path string
+}
+
+func (g *gitSource) String() string { return g.repo + "@" + g.ref + ":" + g.path }
+
+func (g *gitSource) Fetch(ctx context.Context, version string, sig bool) (*fetched, error) {
+	hash, err := g.git(ctx, "rev-parse", "--verify", "--quiet", g.ref+"^{commit}")
+	if err != nil {
+		return nil, fmt.Errorf("failed to resolve %q: %v", g.ref, err)
+	}
+	commit := strings.TrimSpace(string(hash))
+	if commit == version {
+		return nil, errNotModified
+	}
+
+	f := &fetched{version: commit}
+	if f.data, err = g.git(ctx, "show", commit+":"+g.path); err != nil {
+		return nil, err
+	}
+	if sig {
+		if f.sig, err = g.git(ctx, "show", commit+":"+g.path+sigSuffix); err != nil {
+			return nil, err
+		}
+	}
+	return f, nil
+}
+
+// git runs git with args in the repository.
+func (g *gitSource) git(ctx context.Context, args ...string) ([]byte, error) {
+	cmd := exec.CommandContext(ctx, "git", append([]string{"-C", g.repo}, args...)...)
+	var stderr bytes.Buffer
+	cmd.Stderr = &stderr
+	out, err := cmd.Output()
+	if err != nil {
+		if msg := strings.TrimSpace(stderr.String()); msg != "" {
+			return nil, fmt.Errorf("git %s: %s", args[0], msg)
+		}
+		return nil, fmt.Errorf("git %s: %v", args[0], err)
+	}
+	return out, nil
+}
+
+// readPublicKey reads the PEM encoded Ed25519 public key in path.
+func readPublicKey(path string) (ed25519.PublicKey, error) {
+	buf, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	block, _ := pem.Decode(buf)
+	if block == nil {
+		return nil, fmt.Errorf("no PEM data in %q", path)
+	}
+	key, err := x509.ParsePKIXPublicKey(block.Bytes)
+	if err != nil {
+		return nil, err
+	}
+	pub, ok := key.(ed25519.PublicKey)
+	if !ok {
+		return nil, fmt.Errorf("key in %q is not an Ed25519 public key", path)
+	}
+	return pub, nil
+}
+
+// verify checks the signature sig of data, which is either raw or base64 encoded.
+func verify(key ed25519.PublicKey, data, sig []byte) error {
+	if len(sig) != ed25519.SignatureSize {
+		dec, err := base64.StdEncoding.DecodeString(strings.TrimSpace(string(sig)))
+		if err != nil {
+			return fmt.Errorf("malformed signature")
+		}
+		sig = dec
+	}
+	if !ed25519.Verify(key, data, sig) {
+		return fmt.Errorf("invalid signature")
+	}
+	return nil
+}
+
+// reloadSource fetches the zone from its source and sets it live when the fetched data is new, has a valid
+// signature and a changed SOA serial. It returns true when the zone was reloaded. On any failure the zone
+// keeps serving the data it has.
+func (z *Zone) reloadSource(t *transfer.Transfer) (bool, error) {
+	z.RLock()
+	version := z.srcVersion
+	z.RUnlock()
+
+	ctx, cancel := context.WithTimeout(context.Background(), fetchTimeout)
+	defer cancel()
+	f, err := z.src.Fetch(ctx, version, z.srcKey != nil)
+	if err == errNotModified {
+		return false, nil
+	}
+	if err != nil {
+		log.Errorf("Failed to fetch zone %q from %s: %v", z.origin, z.src, err)
+		return false, err
+	}
+	if z.srcKey != nil {
+		if err := verify(z.srcKey, f.data, f.sig); err != nil {
+			log.Errorf("Failed to verify zone %q from %s: %v", z.origin, z.src, err)
+			return false, err
+		}
+	}
+
+	serial := z.SOASerialIfDefined()
+	zone, err := Parse(bytes.NewReader(f.data), z.origin, z.src.String(), serial)
+	if err != nil {
+		if _, ok := err.(*serialErr); !ok {
+			log.Errorf("Parsing zone %q: %v", z.origin, err)
+			return false, err
+		}
+		z.Lock()
+		z.srcVersion = f.version
+		z.Unlock()
+		return false, nil
+	}
+	if err := zone.Validate(z.Validation, serial); err != nil {
+		log.Errorf("Not loading zone %q from %s: %v", z.origin, z.src, err)
+		return false, err
+	}
+
+	z.Lock()
+	z.Apex = zone.Apex
+	z.Tree = zone.Tree
+	z.srcVersion = f.version
+	z.Unlock()
+	z.Changed(z.origin)
+
+	log.Infof("Successfully reloaded zone %q from %s with %d SOA serial", z.origin, z.src, z.Apex.SOA.Serial)
+	if t != nil {
+		if err := t.Notify(z.origin); err != nil {
+			log.Warningf("Failed sending notifies: %s", err)
+		}
+	}
+	return true, nil
+}
@@ -0,0 +1,251 @@
+package file
+
+import (
+	"context"
+	"crypto/ed25519"
+	"crypto/rand"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/pem"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"sync"
+	"testing"
+
+	"github.com/coredns/caddy"
+)
+
+// zoneServer serves a zone and its signature over HTTP, with an ETag.
+type zoneServer struct {
+	sync.Mutex
+	data, sig string
+	etag      string
+}
+
+func (z *zoneServer) set(data, sig, etag string) {
+	z.Lock()
+	defer z.Unlock()
+	z.data, z.sig, z.etag = data, sig, etag
+}
+
+func (z *zoneServer) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	z.Lock()
+	defer z.Unlock()
+	switch r.URL.Path {
+	case "/db.miek.nl":
+		if r.Header.Get("If-None-Match") == z.etag {
+			w.WriteHeader(http.StatusNotModified)
+			return
+		}
+		w.Header().Set("ETag", z.etag)
+		w.Write([]byte(z.data))
+	case "/db.miek.nl" + sigSuffix:
+		w.Write([]byte(z.sig))
+	default:
+		w.WriteHeader(http.StatusNotFound)
+	}
+}
+
+func sign(key ed25519.PrivateKey, data string) string {
+	return base64.StdEncoding.EncodeToString(ed25519.Sign(key, []byte(data)))
+}
+
+func writePublicKey(t *testing.T, pub ed25519.PublicKey) string {
+	der, err := x509.MarshalPKIXPublicKey(pub)
+	if err != nil {
+		t.Fatal(err)
+	}
+	path := filepath.Join(t.TempDir(), "zone.pub")
+	if err := os.WriteFile(path, pem.EncodeToMemory(&pem.Block{Type: "PUBLIC KEY", Bytes: der}), 0644); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+func serial(z *Zone) int64 { return z.SOASerialIfDefined() }
+
+func TestReloadHTTPSource(t *testing.T) {
+	pub, priv, _ := ed25519.GenerateKey(rand.Reader)
+	zs := &zoneServer{}
+	zs.set(reloadZoneTest, sign(priv, reloadZoneTest), `"1"`)
+	ts := httptest.NewServer(zs)
+	defer ts.Close()
+
+	c := caddy.NewTestController("dns", `file `+ts.URL+`/db.miek.nl miek.nl {
+		signature `+writePublicKey(t, pub)+`
+	}`)
+	zones, err := fileParse(c)
+	if err != nil {
+		t.Fatalf("Expected no error, got %s", err)
+	}
+	z := zones.Z["miek.nl."]
+	if serial(z) != 1460175181 {
+		t.Fatalf("Expected zone to be loaded at setup, got serial %d", serial(z))
+	}
+
+	// Same ETag, nothing is loaded.
+	if ok, err := z.reloadFile(nil); ok || err != nil {
+		t.Errorf("Expected no reload for an unchanged ETag, got %t, %v", ok, err)
+	}
+
+	// Bad signature, the old zone is kept.
+	zs.set(reloadZone2Test, sign(priv, reloadZoneTest), `"2"`)
+	if ok, err := z.reloadFile(nil); ok || err == nil {
+		t.Errorf("Expected failed reload for a bad signature, got %t, %v", ok, err)
+	}
+	if serial(z) != 1460175181 {
+		t.Errorf("Expected old zone to be kept, got serial %d", serial(z))
+	}
+
+	// Parse error, the old zone is kept.
+	zs.set("miek.nl. IN BOGUS", sign(priv, "miek.nl. IN BOGUS"), `"3"`)
+	if ok, err := z.reloadFile(nil); ok || err == nil {
+		t.Errorf("Expected failed reload for a bad zone, got %t, %v", ok, err)
+	}
+	if serial(z) != 1460175181 {
+		t.Errorf("Expected old zone to be kept, got serial %d", serial(z))
+	}
+
+	zs.set(reloadZone2Test, sign(priv, reloadZone2Test), `"4"`)
+	if ok, err := z.reloadFile(nil); !ok || err != nil {
+		t.Errorf("Expected reload, got %t, %v", ok, err)
+	}
+	if serial(z) != 1460175182 {
+		t.Errorf("Expected new zone, got serial %d", serial(z))
+	}
+	if z.srcVersion != `"4"` {
+		t.Errorf("Expected version %q, got %q", `"4"`, z.srcVersion)
+	}
+}
+
+func TestReloadHTTPSourceUnavailable(t *testing.T) {
+	ts := httptest.NewServer(http.NotFoundHandler())
+	defer ts.Close()
+
+	// Without reloading, a zone that can't be fetched is an error.
+	c := caddy.NewTestController("dns", `file `+ts.URL+`/db.miek.nl miek.nl {
+		reload 0
+	}`)
+	if _, err := fileParse(c); err == nil {
+		t.Errorf("Expected error for a zone that can't be fetched, got none")
+	}
+
+	c = caddy.NewTestController("dns", `file `+ts.URL+`/db.miek.nl miek.nl`)
+	zones, err := fileParse(c)
+	if err != nil {
+		t.Fatalf("Expected no error, got %s", err)
+	}
+	if serial(zones.Z["miek.nl."]) != -1 {
+		t.Errorf("Expected empty zone")
+	}
+}
+
+func TestReloadGitSource(t *testing.T) {
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git not found")
+	}
+	repo := t.TempDir()
+	git := func(args ...string) {
+		cmd := exec.Command("git", append([]string{"-C", repo, "-c", "user.name=test", "-c", "user.email=test@example.org"}, args...)...)
+		if out, err := cmd.CombinedOutput(); err != nil {
+			t.Fatalf("git %v: %s: %s", args, err, out)
+		}
+	}
+	commit := func(data string) {
+		if err := os.MkdirAll(filepath.Join(repo, "zones"), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(filepath.Join(repo, "zones", "db.miek.nl"), []byte(data), 0644); err != nil {
+			t.Fatal(err)
+		}
+		git("add", "-A")
+		git("commit", "-q", "-m", "update")
+	}
+	git("init", "-q")
+	commit(reloadZoneTest)
+
+	c := caddy.NewTestController("dns", `file zones/db.miek.nl miek.nl {
+		git `+repo+`
+	}`)
+	zones, err := fileParse(c)
+	if err != nil {
+		t.Fatalf("Expected no error, got %s", err)
+	}
+	z := zones.Z["miek.nl."]
+	if serial(z) != 1460175181 {
+		t.Fatalf("Expected zone to be loaded at setup, got serial %d", serial(z))
+	}
+	if ok, err := z.reloadFile(nil); ok || err != nil {
+		t.Errorf("Expected no reload for the same commit, got %t, %v", ok, err)
+	}
+
+	// Only committed changes are read.
+	if err := os.WriteFile(filepath.Join(repo, "zones", "db.miek.nl"), []byte(reloadZone2Test), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if ok, _ := z.reloadFile(nil); ok {
+		t.Errorf("Expected no reload for an uncommitted change")
+	}
+	commit(reloadZone2Test)
+	if ok, err := z.reloadFile(nil); !ok || err != nil {
+		t.Errorf("Expected reload, got %t, %v", ok, err)
+	}
+	if serial(z) != 1460175182 {
+		t.Errorf("Expected new zone, got serial %d", serial(z))
+	}
+
+	// A ref that doesn't exist.
+	g := &gitSource{repo: repo, ref: "nosuchbranch", path: "zones/db.miek.nl"}
+	if _, err := g.Fetch(context.TODO(), "", false); err == nil {
+		t.Errorf("Expected error for an unknown ref, got none")
+	}
+}
+
+func TestVerify(t *testing.T) {
+	pub, priv, _ := ed25519.GenerateKey(rand.Reader)
+	data := []byte(reloadZoneTest)
+	raw := ed25519.Sign(priv, data)
+
+	if err := verify(pub, data, raw); err != nil {
+		t.Errorf("Expected raw signature to verify, got %s", err)
+	}
+	if err := verify(pub, data, []byte(base64.StdEncoding.EncodeToString(raw)+"\n")); err != nil {
+		t.Errorf("Expected base64 signature to verify, got %s", err)
+	}
+	if err := verify(pub, []byte(reloadZone2Test), raw); err == nil {
+		t.Errorf("Expected signature of other data to fail")
+	}
+	if err := verify(pub, data, []byte("not a signature")); err == nil {
+		t.Errorf("Expected malformed signature to fail")
+	}
+}
+
+func TestSourceParse(t *testing.T) {
+	pub, _, _ := ed25519.GenerateKey(rand.Reader)
+	keyFile := writePublicKey(t, pub)
+
+	tests := []string{
+		`file db.miek.nl miek.nl {
+			signature ` + keyFile + `
+		}`,
+		`file db.miek.nl miek.nl {
+			git
+		}`,
+		`file https://example.org/db.miek.nl miek.nl {
+			git /srv/zones.git
+			reload 0
+		}`,
+		`file https://example.org/db.miek.nl miek.nl {
+			signature /does/not/exist
+		}`,
+	}
+	for i, tc := range tests {
+		c := caddy.NewTestController("dns", tc)
+		if _, err := fileParse(c); err == nil {
+			t.Errorf("Test %d: expected error, got none", i)
+		}
+	}
+}
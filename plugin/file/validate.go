@@ -0,0 +1,116 @@
+package file
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/miekg/dns"
+)
+
+// Strictness says what happens with the problems found when validating a zone.
+type Strictness int
+
+const (
+	// ValidateIgnore doesn't validate zones.
+	ValidateIgnore Strictness = iota
+	// ValidateWarn logs the problems found, but loads the zone.
+	ValidateWarn
+	// ValidateReject refuses to load a zone with problems.
+	ValidateReject
+)
+
+// ParseStrictness parses the strictness s, which is one of ignore, warn or reject.
+func ParseStrictness(s string) (Strictness, error) {
+	switch s {
+	case "ignore":
+		return ValidateIgnore, nil
+	case "warn":
+		return ValidateWarn, nil
+	case "reject":
+		return ValidateReject, nil
+	}
+	return ValidateIgnore, fmt.Errorf("unknown validation strictness %q", s)
+}
+
+// Problems checks z for problems that don't stop it from loading, but make it behave oddly: records that are
+// out of zone, CNAMEs with other data, NS records without addresses or missing glue. It returns a description
+// of each problem found.
+func (z *Zone) Problems() []string {
+	problems := []string{}
+	if z.Apex.SOA == nil {
+		problems = append(problems, "no SOA record")
+	}
+	if len(z.Apex.NS) == 0 {
+		problems = append(problems, "no NS records at the apex")
+	}
+	for _, ns := range z.Apex.NS {
+		problems = append(problems, z.validateNS(z.origin, ns.(*dns.NS).Ns)...)
+	}
+
+	for _, e := range z.Tree.All() {
+		name := e.Name()
+		if !dns.IsSubDomain(z.origin, name) {
+			problems = append(problems, fmt.Sprintf("%s: out of zone", name))
+			continue
+		}
+
+		if cname := e.Type(dns.TypeCNAME); len(cname) > 0 {
+			if len(cname) > 1 {
+				problems = append(problems, fmt.Sprintf("%s: more than one CNAME record", name))
+			}
+			for _, t := range e.Types() {
+				if t != dns.TypeCNAME && t != dns.TypeRRSIG && t != dns.TypeNSEC {
+					problems = append(problems, fmt.Sprintf("%s: CNAME and other data (%s)", name, dns.TypeToString[t]))
+					break
+				}
+			}
+		}
+
+		for _, ns := range e.Type(dns.TypeNS) {
+			problems = append(problems, z.validateNS(name, ns.(*dns.NS).Ns)...)
+		}
+	}
+	return problems
+}
+
+// validateNS checks that the target of an NS record for name has addresses, when they should be in this zone.
+func (z *Zone) validateNS(name, target string) []string {
+	if !dns.IsSubDomain(z.origin, target) {
+		return nil
+	}
+	if e, _ := z.Tree.Search(target); e != nil && (len(e.Type(dns.TypeA)) > 0 || len(e.Type(dns.TypeAAAA)) > 0) {
+		return nil
+	}
+	if name != z.origin && dns.IsSubDomain(name, target) {
+		return []string{fmt.Sprintf("%s: missing glue for NS %s", name, target)}
+	}
+	return []string{fmt.Sprintf("%s: NS %s has no address records", name, target)}
+}
+
+// Validate validates z according to s and returns an error when z should not be loaded. The problems are
+// logged if s is ValidateWarn. Serial is the SOA serial of the version of the zone z replaces, or -1 if there
+// is none.
+func (z *Zone) Validate(s Strictness, serial int64) error {
+	if s == ValidateIgnore {
+		return nil
+	}
+	problems := z.Problems()
+	if serial >= 0 && z.Apex.SOA != nil && serialLess(z.Apex.SOA.Serial, uint32(serial)) {
+		problems = append(problems, fmt.Sprintf("SOA serial %d is lower than the previous serial %d", z.Apex.SOA.Serial, serial))
+	}
+	if len(problems) == 0 {
+		return nil
+	}
+	if s == ValidateWarn {
+		for _, p := range problems {
+			log.Warningf("Zone %q: %s", z.origin, p)
+		}
+		return nil
+	}
+	return fmt.Errorf("zone %q is invalid: %s", z.origin, strings.Join(problems, "; "))
+}
+
+// serialLess returns true if serial a is lower than b, using serial number arithmetic (RFC 1982).
+func serialLess(a, b uint32) bool {
+	return a != b && int32(a-b) < 0
+}
@@ -0,0 +1,114 @@
+package file
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestProblems(t *testing.T) {
+	zone, err := Parse(strings.NewReader(dbBrokenExampleOrg), "example.org.", "stdin", 0)
+	if err != nil {
+		t.Fatalf("Expected no error when parsing, got %s", err)
+	}
+	expected := []string{
+		"example.org.: NS ns2.example.org. has no address records",
+		"www.example.org.: CNAME and other data (TXT)",
+		"sub.example.org.: missing glue for NS ns.sub.example.org.",
+		"www.example.net.: out of zone",
+	}
+	problems := zone.Problems()
+	for _, e := range expected {
+		found := false
+		for _, p := range problems {
+			if p == e {
+				found = true
+			}
+		}
+		if !found {
+			t.Errorf("Expected problem %q, got %v", e, problems)
+		}
+	}
+	if len(problems) != len(expected) {
+		t.Errorf("Expected %d problems, got %d: %v", len(expected), len(problems), problems)
+	}
+
+	zone, err = Parse(strings.NewReader(dbMiekNL), testzone, "stdin", 0)
+	if err != nil {
+		t.Fatalf("Expected no error when parsing, got %s", err)
+	}
+	if problems := zone.Problems(); len(problems) != 0 {
+		t.Errorf("Expected no problems, got %v", problems)
+	}
+}
+
+func TestValidate(t *testing.T) {
+	zone, err := Parse(strings.NewReader(dbBrokenExampleOrg), "example.org.", "stdin", 0)
+	if err != nil {
+		t.Fatalf("Expected no error when parsing, got %s", err)
+	}
+	if err := zone.Validate(ValidateIgnore, -1); err != nil {
+		t.Errorf("Expected no error with ignore, got %s", err)
+	}
+	if err := zone.Validate(ValidateWarn, -1); err != nil {
+		t.Errorf("Expected no error with warn, got %s", err)
+	}
+	if err := zone.Validate(ValidateReject, -1); err == nil {
+		t.Errorf("Expected error with reject, got none")
+	}
+
+	zone, err = Parse(strings.NewReader(dbMiekNL), testzone, "stdin", 0)
+	if err != nil {
+		t.Fatalf("Expected no error when parsing, got %s", err)
+	}
+	serial := int64(zone.Apex.SOA.Serial)
+	if err := zone.Validate(ValidateReject, serial); err != nil {
+		t.Errorf("Expected no error for the same serial, got %s", err)
+	}
+	if err := zone.Validate(ValidateReject, serial+1); err == nil {
+		t.Errorf("Expected error for a lower serial, got none")
+	}
+}
+
+func TestSerialLess(t *testing.T) {
+	tests := []struct {
+		a, b     uint32
+		expected bool
+	}{
+		{1, 2, true},
+		{2, 1, false},
+		{2, 2, false},
+		{4294967295, 1, true},
+		{1, 4294967295, false},
+	}
+	for i, tc := range tests {
+		if got := serialLess(tc.a, tc.b); got != tc.expected {
+			t.Errorf("Test %d: expected %t for %d < %d, got %t", i, tc.expected, tc.a, tc.b, got)
+		}
+	}
+}
+
+func TestParseStrictness(t *testing.T) {
+	for _, s := range []string{"ignore", "warn", "reject"} {
+		if _, err := ParseStrictness(s); err != nil {
+			t.Errorf("Expected no error for %q, got %s", s, err)
+		}
+	}
+	if _, err := ParseStrictness("strict"); err == nil {
+		t.Errorf("Expected error for %q, got none", "strict")
+	}
+}
+
+const dbBrokenExampleOrg = `
+$TTL    1M
+$ORIGIN example.org.
+
+@       IN  SOA  ns1 hostmaster 2023010101 7200 3600 1209600 3600
+        IN  NS   ns1
+        IN  NS   ns2
+ns1     IN  A    192.0.2.53
+www     IN  CNAME web
+        IN  TXT  "other data"
+web     IN  A    192.0.2.1
+sub     IN  NS   ns.sub
+www.example.net. IN A 192.0.2.2
+`
@@ -1,6 +1,7 @@
 package file
 
 import (
+	"crypto/ed25519"
 	"fmt"
 	"path/filepath"
 	"strings"
@@ -8,7 +9,9 @@ import (
 	"time"
 
 	"github.com/coredns/coredns/plugin/file/tree"
+	"github.com/coredns/coredns/plugin/pkg/cache"
 	"github.com/coredns/coredns/plugin/pkg/upstream"
+	"github.com/coredns/coredns/plugin/pkg/watch"
 
 	"github.com/miekg/dns"
 )
@@ -23,14 +26,28 @@ type Zone struct {
 	Expired bool
 
 	sync.RWMutex
+	watch.Notifier // signals the zone changed, with its origin
 
 	StartupOnce  sync.Once
 	TransferFrom []string
+	TransferKey  *TSIGKey // key to sign the SOA queries and transfers to the primaries with, if set
+	Persist      string   // file to write transferred zones to and read them from at startup, if set
+
+	primaries   primaries     // backoff state of the primaries in TransferFrom
+	lastRefresh time.Time     // last time the zone was found to be up to date with a primary
+	notify      chan struct{} // a NOTIFY was received, check the primaries now
 
 	ReloadInterval time.Duration
 	reloadShutdown chan bool
+	Validation     Strictness // what to do with the problems found in a newly loaded version
 
 	Upstream *upstream.Upstream // Upstream for looking up external names during the resolution process.
+
+	aliases *cache.Cache // addresses of ALIAS targets
+
+	src        source            // where the zone is read from, if not from file
+	srcKey     ed25519.PublicKey // key to verify the data from src with, if set
+	srcVersion string            // version of the data last read from src
 }
 
 // Apex contains the apex records of a zone: SOA, NS and their potential signatures.
@@ -49,6 +66,8 @@ func NewZone(name, file string) *Zone {
 		file:           filepath.Clean(file),
 		Tree:           &tree.Tree{},
 		reloadShutdown: make(chan bool),
+		notify:         make(chan struct{}, 1),
+		aliases:        cache.New(aliasCacheSize),
 	}
 }
 
@@ -56,6 +75,7 @@ func NewZone(name, file string) *Zone {
 func (z *Zone) Copy() *Zone {
 	z1 := NewZone(z.origin, z.file)
 	z1.TransferFrom = z.TransferFrom
+	z1.TransferKey = z.TransferKey
 	z1.Expired = z.Expired
 
 	z1.Apex = z.Apex
@@ -66,6 +86,7 @@ func (z *Zone) Copy() *Zone {
 func (z *Zone) CopyWithoutApex() *Zone {
 	z1 := NewZone(z.origin, z.file)
 	z1.TransferFrom = z.TransferFrom
+	z1.TransferKey = z.TransferKey
 	z1.Expired = z.Expired
 
 	return z1
@@ -109,6 +130,15 @@ func (z *Zone) Insert(r dns.RR) error {
 		r.(*dns.MX).Mx = strings.ToLower(r.(*dns.MX).Mx)
 	case dns.TypeSRV:
 		r.(*dns.SRV).Target = strings.ToLower(r.(*dns.SRV).Target)
+	case TypeALIAS:
+		a := r.(*dns.PrivateRR).Data.(*ALIAS)
+		a.Target = strings.ToLower(a.Target)
+		switch {
+		case a.Target == "@":
+			a.Target = z.origin
+		case !dns.IsFqdn(a.Target):
+			a.Target = dns.Fqdn(a.Target + "." + z.origin)
+		}
 	}
 
 	z.Tree.Insert(r)
@@ -38,8 +38,10 @@ type Forward struct {
 	ignored []string
 
 	tlsConfig     *tls.Config
+	tlsArgs       []string // as given to the tls option, to see if the TLS config changed on reload
 	tlsServerName string
 	maxfails      uint32
+	minHealthy    int
 	expire        time.Duration
 	maxConcurrent int64
 
@@ -56,7 +58,7 @@ type Forward struct {
 
 // New returns a new Forward.
 func New() *Forward {
-	f := &Forward{maxfails: 2, tlsConfig: new(tls.Config), expire: defaultExpire, p: new(random), from: ".", hcInterval: hcInterval, opts: options{forceTCP: false, preferUDP: false, hcRecursionDesired: true, hcDomain: "."}}
+	f := &Forward{maxfails: 2, minHealthy: 1, tlsConfig: new(tls.Config), expire: defaultExpire, p: new(random), from: ".", hcInterval: hcInterval, opts: options{forceTCP: false, preferUDP: false, hcRecursionDesired: true, hcDomain: "."}}
 	return f
 }
 
@@ -77,6 +79,15 @@ func (f *Forward) SetTapPlugin(tapPlugin *dnstap.Dnstap) {
 // Len returns the number of configured proxies.
 func (f *Forward) Len() int { return len(f.proxies) }
 
+// Proxies returns the configured proxies in the order they were configured.
+func (f *Forward) Proxies() []*Proxy { return f.proxies }
+
+// MaxFails returns the number of failed health checks after which an upstream is considered down.
+func (f *Forward) MaxFails() uint32 { return f.maxfails }
+
+// From returns the domain whose queries are forwarded.
+func (f *Forward) From() string { return f.from }
+
 // Name implements plugin.Handler.
 func (f *Forward) Name() string { return "forward" }
 
@@ -127,7 +138,7 @@ func (f *Forward) ServeDNS(ctx context.Context, w dns.ResponseWriter, r *dns.Msg
 		}
 
 		if span != nil {
-			child = span.Tracer().StartSpan("connect", ot.ChildOf(span.Context()))
+			child = span.Tracer().StartSpan("connect", ot.ChildOf(span.Context()), otext.SpanKindRPCClient)
 			otext.PeerAddress.Set(child, proxy.addr)
 			ctx = ot.ContextWithSpan(ctx, child)
 		}
@@ -155,11 +166,14 @@ func (f *Forward) ServeDNS(ctx context.Context, w dns.ResponseWriter, r *dns.Msg
 		}
 
 		if child != nil {
+			if err != nil {
+				otext.Error.Set(child, true)
+			}
 			child.Finish()
 		}
 
 		if len(f.tapPlugins) != 0 {
-			toDnstap(f, proxy.addr, state, opts, ret, start)
+			toDnstap(ctx, f, proxy.addr, state, opts, ret, start)
 		}
 
 		upstreamErr = err
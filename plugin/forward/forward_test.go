@@ -2,6 +2,7 @@ package forward
 
 import (
 	"strings"
+	"sync/atomic"
 	"testing"
 
 	"github.com/coredns/caddy"
@@ -64,3 +65,27 @@ func TestSetTapPlugin(t *testing.T) {
 		t.Error("Unexpected order of dnstap plugins")
 	}
 }
+
+func TestReady(t *testing.T) {
+	f := New()
+	f.SetProxy(NewProxy("127.0.0.1:53", "dns"))
+	f.SetProxy(NewProxy("127.0.0.2:53", "dns"))
+	f.minHealthy = 2
+
+	if !f.Ready() {
+		t.Errorf("Expected forward to be ready, got %q", f.NotReady())
+	}
+
+	atomic.StoreUint32(&f.proxies[0].fails, f.maxfails+1)
+	if f.Ready() {
+		t.Error("Expected forward not to be ready with one upstream down")
+	}
+	if reason := f.NotReady(); reason != "1 of 2 upstreams healthy, 2 required" {
+		t.Errorf("Unexpected reason %q", reason)
+	}
+
+	f.minHealthy = 1
+	if !f.Ready() {
+		t.Errorf("Expected forward to be ready, got %q", f.NotReady())
+	}
+}
@@ -0,0 +1,44 @@
+package forward
+
+import (
+	"reflect"
+
+	"github.com/coredns/coredns/plugin"
+)
+
+// Handoff implements the dnsserver.Handoff interface. When the upstreams and options are unchanged, f takes
+// over the proxies of the previous instance, keeping their connections and health state.
+func (f *Forward) Handoff(previous plugin.Handler) {
+	prev, ok := previous.(*Forward)
+	if !ok || prev == f || !f.sameConfig(prev) {
+		return
+	}
+	proxies := make([]*Proxy, len(prev.proxies))
+	copy(proxies, prev.proxies)
+	f.proxies = proxies
+	log.Debugf("Took over %d upstreams for %q from the previous configuration", len(proxies), f.from)
+}
+
+// sameConfig returns true if f and prev forward to the same upstreams with the same options.
+func (f *Forward) sameConfig(prev *Forward) bool {
+	if len(f.proxies) != len(prev.proxies) {
+		return false
+	}
+	for i := range f.proxies {
+		if f.proxies[i].addr != prev.proxies[i].addr {
+			return false
+		}
+		if (f.proxies[i].transport.tlsConfig == nil) != (prev.proxies[i].transport.tlsConfig == nil) {
+			return false
+		}
+	}
+	return f.from == prev.from &&
+		reflect.DeepEqual(f.ignored, prev.ignored) &&
+		reflect.DeepEqual(f.tlsArgs, prev.tlsArgs) &&
+		f.tlsServerName == prev.tlsServerName &&
+		f.p.String() == prev.p.String() &&
+		f.hcInterval == prev.hcInterval &&
+		f.maxfails == prev.maxfails &&
+		f.expire == prev.expire &&
+		f.opts == prev.opts
+}
@@ -0,0 +1,56 @@
+package forward
+
+import (
+	"testing"
+
+	"github.com/coredns/caddy"
+)
+
+func TestHandoff(t *testing.T) {
+	tests := []struct {
+		previous string
+		current  string
+		takeOver bool
+	}{
+		{"forward . 127.0.0.1 127.0.0.2", "forward . 127.0.0.1 127.0.0.2", true},
+		{"forward . 127.0.0.1 127.0.0.2", "forward . 127.0.0.1 127.0.0.2 {\nmin_healthy 2\n}", true},
+		{"forward . 127.0.0.1 127.0.0.2", "forward . 127.0.0.2 127.0.0.1", false},
+		{"forward . 127.0.0.1", "forward example.org 127.0.0.1", false},
+		{"forward . 127.0.0.1", "forward . 127.0.0.1 {\nmax_fails 3\n}", false},
+		{"forward . 127.0.0.1", "forward . 127.0.0.1 {\npolicy sequential\n}", false},
+		{"forward . 127.0.0.1:853", "forward . tls://127.0.0.1:853", false},
+	}
+	for i, tc := range tests {
+		prev, err := parseForward(caddy.NewTestController("dns", tc.previous))
+		if err != nil {
+			t.Fatalf("Test %d: %s", i, err)
+		}
+		f, err := parseForward(caddy.NewTestController("dns", tc.current))
+		if err != nil {
+			t.Fatalf("Test %d: %s", i, err)
+		}
+		f[0].Handoff(prev[0])
+		if took := f[0].proxies[0] == prev[0].proxies[0]; took != tc.takeOver {
+			t.Errorf("Test %d: expected take over to be %t, got %t", i, tc.takeOver, took)
+		}
+	}
+}
+
+func TestHandoffProxyRefs(t *testing.T) {
+	prev, _ := parseForward(caddy.NewTestController("dns", "forward . 127.0.0.1"))
+	f, _ := parseForward(caddy.NewTestController("dns", "forward . 127.0.0.1"))
+
+	prev[0].OnStartup()
+	f[0].Handoff(prev[0])
+	f[0].OnStartup()
+	defer f[0].OnShutdown()
+
+	p := f[0].proxies[0]
+	if refs := p.refs; refs != 2 {
+		t.Fatalf("Expected proxy to be used by 2 instances, got %d", refs)
+	}
+	prev[0].OnShutdown()
+	if refs := p.refs; refs != 1 {
+		t.Errorf("Expected proxy to be used by 1 instance after shutting down the previous one, got %d", refs)
+	}
+}
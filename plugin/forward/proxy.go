@@ -12,6 +12,7 @@ import (
 // Proxy defines an upstream host.
 type Proxy struct {
 	fails uint32
+	refs  int32 // number of Forward instances using this proxy, see Forward.Handoff
 	addr  string
 
 	transport *Transport
@@ -43,6 +44,12 @@ func (p *Proxy) SetTLSConfig(cfg *tls.Config) {
 // SetExpire sets the expire duration in the lower p.transport.
 func (p *Proxy) SetExpire(expire time.Duration) { p.transport.SetExpire(expire) }
 
+// Addr returns the address of the upstream.
+func (p *Proxy) Addr() string { return p.addr }
+
+// Fails returns the number of failed health checks since the upstream was last seen healthy.
+func (p *Proxy) Fails() uint32 { return atomic.LoadUint32(&p.fails) }
+
 // Healthcheck kicks of a round of health checks for this proxy.
 func (p *Proxy) Healthcheck() {
 	if p.health == nil {
@@ -65,12 +72,21 @@ func (p *Proxy) Down(maxfails uint32) bool {
 	return fails > maxfails
 }
 
-// close stops the health checking goroutine.
-func (p *Proxy) stop()      { p.probe.Stop() }
+// stop stops the health checking goroutine, once no Forward instance uses the proxy anymore.
+func (p *Proxy) stop() {
+	if atomic.AddInt32(&p.refs, -1) > 0 {
+		return
+	}
+	p.probe.Stop()
+}
+
 func (p *Proxy) finalizer() { p.transport.Stop() }
 
-// start starts the proxy's healthchecking.
+// start starts the proxy's healthchecking, if it isn't running already.
 func (p *Proxy) start(duration time.Duration) {
+	if atomic.AddInt32(&p.refs, 1) > 1 {
+		return
+	}
 	p.probe.Start(duration)
 	p.transport.Start()
 }
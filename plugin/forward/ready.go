@@ -0,0 +1,23 @@
+package forward
+
+import "fmt"
+
+// Ready implements the ready.Readiness interface. Forward is ready when at least min_healthy upstreams
+// are healthy.
+func (f *Forward) Ready() bool { return f.healthy() >= f.minHealthy }
+
+// NotReady implements the ready.Reporter interface.
+func (f *Forward) NotReady() string {
+	return fmt.Sprintf("%d of %d upstreams healthy, %d required", f.healthy(), len(f.proxies), f.minHealthy)
+}
+
+// healthy returns the number of upstreams that are not down.
+func (f *Forward) healthy() int {
+	n := 0
+	for _, p := range f.proxies {
+		if !p.Down(f.maxfails) {
+			n++
+		}
+	}
+	return n
+}
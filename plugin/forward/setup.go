@@ -64,7 +64,7 @@ func setup(c *caddy.Controller) error {
 	return nil
 }
 
-// OnStartup starts a goroutines for all proxies.
+// OnStartup starts a goroutines for all proxies. Proxies taken over from a previous instance are already running.
 func (f *Forward) OnStartup() (err error) {
 	for _, p := range f.proxies {
 		p.start(f.hcInterval)
@@ -138,6 +138,10 @@ func parseStanza(c *caddy.Controller) (*Forward, error) {
 		}
 	}
 
+	if f.minHealthy > len(f.proxies) {
+		return f, fmt.Errorf("min_healthy %d is larger than the number of upstreams %d", f.minHealthy, len(f.proxies))
+	}
+
 	if f.tlsServerName != "" {
 		f.tlsConfig.ServerName = f.tlsServerName
 	}
@@ -182,6 +186,18 @@ func parseBlock(c *caddy.Controller, f *Forward) error {
 			return err
 		}
 		f.maxfails = uint32(n)
+	case "min_healthy":
+		if !c.NextArg() {
+			return c.ArgErr()
+		}
+		n, err := strconv.Atoi(c.Val())
+		if err != nil {
+			return err
+		}
+		if n < 0 {
+			return fmt.Errorf("min_healthy can't be negative: %d", n)
+		}
+		f.minHealthy = n
 	case "health_check":
 		if !c.NextArg() {
 			return c.ArgErr()
@@ -235,6 +251,7 @@ func parseBlock(c *caddy.Controller, f *Forward) error {
 			return err
 		}
 		f.tlsConfig = tlsConfig
+		f.tlsArgs = args
 	case "tls_servername":
 		if !c.NextArg() {
 			return c.ArgErr()
@@ -0,0 +1,85 @@
+// Package geodns implements a plugin that chooses answers based on the location of the client.
+package geodns
+
+import (
+	"context"
+
+	"github.com/coredns/coredns/plugin"
+	"github.com/coredns/coredns/plugin/metrics"
+	"github.com/coredns/coredns/plugin/pkg/geo"
+	"github.com/coredns/coredns/request"
+
+	"github.com/miekg/dns"
+)
+
+// GeoDNS is a plugin that answers with the variant of an RRset that best matches the client.
+type GeoDNS struct {
+	Next  plugin.Handler
+	Zones []string
+
+	edns0 bool
+	names map[string]rrsets
+}
+
+// rrsets holds the variants of the RRsets of a name, by type.
+type rrsets map[uint16][]*variant
+
+// variant is a version of an RRset that is given to the clients its selector matches.
+type variant struct {
+	sel *geo.Selector
+	rrs []dns.RR
+}
+
+// ServeDNS implements the plugin.Handler interface.
+func (g *GeoDNS) ServeDNS(ctx context.Context, w dns.ResponseWriter, r *dns.Msg) (int, error) {
+	state := request.Request{W: w, Req: r}
+	qname := state.Name()
+
+	zone := plugin.Zones(g.Zones).Matches(qname)
+	if zone == "" {
+		return plugin.NextOrFailure(g.Name(), g.Next, ctx, w, r)
+	}
+	sets, ok := g.names[qname]
+	if !ok {
+		return plugin.NextOrFailure(g.Name(), g.Next, ctx, w, r)
+	}
+	variants := sets[state.QType()]
+	if len(variants) == 0 && state.QType() != dns.TypeCNAME {
+		variants = sets[dns.TypeCNAME]
+	}
+
+	v := g.choose(ctx, state, variants)
+	if v == nil {
+		return plugin.NextOrFailure(g.Name(), g.Next, ctx, w, r)
+	}
+	selectCount.WithLabelValues(metrics.WithServer(ctx), zone, v.sel.Kind()).Inc()
+
+	m := new(dns.Msg)
+	m.SetReply(r)
+	m.Authoritative = true
+	m.Answer = v.rrs
+
+	w.WriteMsg(m)
+	return dns.RcodeSuccess, nil
+}
+
+// choose returns the variant that matches the client best, or nil when none match. Of
+// equally good matches the first one is returned.
+func (g *GeoDNS) choose(ctx context.Context, state request.Request, variants []*variant) *variant {
+	if len(variants) == 0 {
+		return nil
+	}
+	ip := geo.ClientIP(state, g.edns0)
+
+	var best *variant
+	score := -1
+	for _, v := range variants {
+		if s := v.sel.Match(ctx, ip); s > score {
+			best, score = v, s
+		}
+	}
+	return best
+}
+
+// Name implements the Handler interface.
+func (g *GeoDNS) Name() string { return "geodns" }
@@ -0,0 +1,124 @@
+package geodns
+
+import (
+	"context"
+	"net"
+	"testing"
+
+	"github.com/coredns/caddy"
+	"github.com/coredns/coredns/plugin/metadata"
+	"github.com/coredns/coredns/plugin/pkg/dnstest"
+	"github.com/coredns/coredns/plugin/test"
+
+	"github.com/miekg/dns"
+)
+
+func newTestGeoDNS(t *testing.T) *GeoDNS {
+	c := caddy.NewTestController("dns", `geodns example.org {
+		default www 300 IN A 192.0.2.1
+		continent:EU www 300 IN A 192.0.2.2
+		country:NL,BE www 300 IN A 192.0.2.3
+		region:US-CA www 300 IN A 192.0.2.4
+		asn:AS64512 www 300 IN A 192.0.2.5
+		net:10.0.0.0/8 www 300 IN A 192.0.2.6
+		net:10.240.0.0/16 www 300 IN A 192.0.2.7
+		country:US cdn 300 IN CNAME us.cdn.example.net.
+	}`)
+	g, err := geodnsParse(c)
+	if err != nil {
+		t.Fatalf("Failed to parse: %s", err)
+	}
+	g.Next = test.NextHandler(dns.RcodeRefused, nil)
+	return g
+}
+
+func TestServeDNS(t *testing.T) {
+	g := newTestGeoDNS(t)
+
+	tests := []struct {
+		qname    string
+		qtype    uint16
+		labels   map[string]string
+		remote   string
+		ecs      string
+		rcode    int
+		expected string
+	}{
+		{"www.example.org.", dns.TypeA, nil, "192.0.2.100", "", dns.RcodeSuccess, "192.0.2.1"},
+		{"www.example.org.", dns.TypeA, map[string]string{"geoip/continent/code": "EU"}, "192.0.2.100", "", dns.RcodeSuccess, "192.0.2.2"},
+		{"www.example.org.", dns.TypeA, map[string]string{"geoip/continent/code": "EU", "geoip/country/code": "BE"}, "192.0.2.100", "", dns.RcodeSuccess, "192.0.2.3"},
+		{"www.example.org.", dns.TypeA, map[string]string{"geoip/country/code": "US", "geoip/subdivisions/code": "US-CA"}, "192.0.2.100", "", dns.RcodeSuccess, "192.0.2.4"},
+		{"www.example.org.", dns.TypeA, map[string]string{"geoip/country/code": "NL", "geoip/asn/number": "64512"}, "192.0.2.100", "", dns.RcodeSuccess, "192.0.2.5"},
+		{"www.example.org.", dns.TypeA, map[string]string{"geoip/asn/number": "64512"}, "10.1.0.1", "", dns.RcodeSuccess, "192.0.2.6"},
+		// Longest prefix wins.
+		{"www.example.org.", dns.TypeA, nil, "10.240.0.1", "", dns.RcodeSuccess, "192.0.2.7"},
+		// ECS is not used unless enabled.
+		{"www.example.org.", dns.TypeA, nil, "192.0.2.100", "10.240.0.0", dns.RcodeSuccess, "192.0.2.1"},
+		// CNAME for other types.
+		{"cdn.example.org.", dns.TypeA, map[string]string{"geoip/country/code": "US"}, "192.0.2.100", "", dns.RcodeSuccess, "us.cdn.example.net."},
+		// No variant matches, no type, unknown name or other zone, handled by the next plugin.
+		{"cdn.example.org.", dns.TypeA, nil, "192.0.2.100", "", dns.RcodeRefused, ""},
+		{"www.example.org.", dns.TypeMX, nil, "192.0.2.100", "", dns.RcodeRefused, ""},
+		{"mail.example.org.", dns.TypeA, nil, "192.0.2.100", "", dns.RcodeRefused, ""},
+		{"www.example.net.", dns.TypeA, nil, "192.0.2.100", "", dns.RcodeRefused, ""},
+	}
+
+	for i, tc := range tests {
+		ctx := metadata.ContextWithMetadata(context.Background())
+		for l, v := range tc.labels {
+			v := v
+			metadata.SetValueFunc(ctx, l, func() string { return v })
+		}
+		m := new(dns.Msg)
+		m.SetQuestion(tc.qname, tc.qtype)
+		if tc.ecs != "" {
+			addECS(m, tc.ecs)
+		}
+		rec := dnstest.NewRecorder(&test.ResponseWriter{RemoteIP: tc.remote})
+		rcode, _ := g.ServeDNS(ctx, rec, m)
+		if rcode != tc.rcode {
+			t.Errorf("Test %d: expected rcode %d, got %d", i, tc.rcode, rcode)
+			continue
+		}
+		if tc.expected == "" {
+			continue
+		}
+		if len(rec.Msg.Answer) != 1 {
+			t.Errorf("Test %d: expected 1 answer, got %v", i, rec.Msg.Answer)
+			continue
+		}
+		var x string
+		switch rr := rec.Msg.Answer[0].(type) {
+		case *dns.A:
+			x = rr.A.String()
+		case *dns.CNAME:
+			x = rr.Target
+		}
+		if x != tc.expected {
+			t.Errorf("Test %d: expected %s, got %s", i, tc.expected, x)
+		}
+		if !rec.Msg.Authoritative {
+			t.Errorf("Test %d: expected authoritative answer", i)
+		}
+	}
+}
+
+func TestServeDNSECS(t *testing.T) {
+	g := newTestGeoDNS(t)
+	g.edns0 = true
+
+	m := new(dns.Msg)
+	m.SetQuestion("www.example.org.", dns.TypeA)
+	addECS(m, "10.240.0.0")
+	rec := dnstest.NewRecorder(&test.ResponseWriter{RemoteIP: "192.0.2.100"})
+	g.ServeDNS(context.Background(), rec, m)
+	if len(rec.Msg.Answer) != 1 || rec.Msg.Answer[0].(*dns.A).A.String() != "192.0.2.7" {
+		t.Errorf("Expected answer for the ECS address, got %v", rec.Msg.Answer)
+	}
+}
+
+func addECS(m *dns.Msg, addr string) {
+	m.SetEdns0(4096, false)
+	ecs := &dns.EDNS0_SUBNET{Code: dns.EDNS0SUBNET, Family: 1, SourceNetmask: 24, Address: net.ParseIP(addr).To4()}
+	m.IsEdns0().Option = append(m.IsEdns0().Option, ecs)
+}
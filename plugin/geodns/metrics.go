@@ -0,0 +1,16 @@
+package geodns
+
+import (
+	"github.com/coredns/coredns/plugin"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+)
+
+// selectCount counts the answers per zone, by the kind of selector that matched the client.
+var selectCount = promauto.NewCounterVec(prometheus.CounterOpts{
+	Namespace: plugin.Namespace,
+	Subsystem: "geodns",
+	Name:      "answers_total",
+	Help:      "Counter of answers given, by the kind of selector that matched the client.",
+}, []string{"server", "zone", "selector"})
@@ -0,0 +1,151 @@
+package geodns
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/coredns/caddy"
+	"github.com/coredns/coredns/core/dnsserver"
+	"github.com/coredns/coredns/plugin"
+	"github.com/coredns/coredns/plugin/pkg/geo"
+
+	"github.com/miekg/dns"
+)
+
+func init() { plugin.Register("geodns", setup) }
+
+func setup(c *caddy.Controller) error {
+	g, err := geodnsParse(c)
+	if err != nil {
+		return plugin.Error("geodns", err)
+	}
+
+	dnsserver.GetConfig(c).AddPlugin(func(next plugin.Handler) plugin.Handler {
+		g.Next = next
+		return g
+	})
+
+	return nil
+}
+
+func geodnsParse(c *caddy.Controller) (*GeoDNS, error) {
+	config := dnsserver.GetConfig(c)
+	g := &GeoDNS{names: map[string]rrsets{}}
+
+	i := 0
+	for c.Next() {
+		if i > 0 {
+			return nil, plugin.ErrOnce
+		}
+		i++
+
+		g.Zones = plugin.OriginsFromArgsOrServerBlock(c.RemainingArgs(), c.ServerBlockKeys)
+		// seen holds the variants by name, type and selector, records with the same selector make up one RRset.
+		seen := map[string]*variant{}
+
+		for c.NextBlock() {
+			switch c.Val() {
+			case "edns-subnet":
+				if c.NextArg() {
+					return nil, c.ArgErr()
+				}
+				g.edns0 = true
+			case "file":
+				if !c.NextArg() {
+					return nil, c.ArgErr()
+				}
+				path := c.Val()
+				if c.NextArg() {
+					return nil, c.ArgErr()
+				}
+				if !filepath.IsAbs(path) && config.Root != "" {
+					path = filepath.Join(config.Root, path)
+				}
+				if err := g.readFile(path, seen); err != nil {
+					return nil, c.Err(err.Error())
+				}
+			default:
+				sel := c.Val()
+				if sel != "default" && !strings.Contains(sel, ":") {
+					return nil, c.Errf("unknown property '%s'", sel)
+				}
+				args := c.RemainingArgs()
+				if len(args) == 0 {
+					return nil, c.ArgErr()
+				}
+				if err := g.add(sel, strings.Join(args, " "), seen); err != nil {
+					return nil, c.Err(err.Error())
+				}
+			}
+		}
+	}
+	return g, nil
+}
+
+// readFile reads the variants in path. Each line holds a selector and a resource record, like the
+// lines in the Corefile. Empty lines and lines starting with a # or ; are ignored.
+func (g *GeoDNS) readFile(path string, seen map[string]*variant) error {
+	f, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	scanner := bufio.NewScanner(f)
+	n := 0
+	for scanner.Scan() {
+		n++
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || line[0] == '#' || line[0] == ';' {
+			continue
+		}
+		sel := strings.Fields(line)[0]
+		rr := strings.TrimSpace(line[len(sel):])
+		if rr == "" {
+			return fmt.Errorf("%s:%d: missing resource record", path, n)
+		}
+		if err := g.add(sel, rr, seen); err != nil {
+			return fmt.Errorf("%s:%d: %s", path, n, err)
+		}
+	}
+	return scanner.Err()
+}
+
+// add adds the resource record in text to the variant with selector sel. Relative names are
+// relative to the first zone.
+func (g *GeoDNS) add(sel, text string, seen map[string]*variant) error {
+	zp := dns.NewZoneParser(strings.NewReader(text), g.Zones[0], "")
+	rr, ok := zp.Next()
+	if err := zp.Err(); err != nil {
+		return err
+	}
+	if !ok {
+		return fmt.Errorf("missing resource record")
+	}
+	name := strings.ToLower(rr.Header().Name)
+	if plugin.Zones(g.Zones).Matches(name) == "" {
+		return fmt.Errorf("name %q is not in zones %v", rr.Header().Name, g.Zones)
+	}
+	s, err := geo.ParseSelector(sel)
+	if err != nil {
+		return err
+	}
+
+	qtype := rr.Header().Rrtype
+	key := name + "/" + dns.TypeToString[qtype] + "/" + sel
+	if v, ok := seen[key]; ok {
+		v.rrs = append(v.rrs, rr)
+		return nil
+	}
+	v := &variant{sel: s, rrs: []dns.RR{rr}}
+	seen[key] = v
+
+	if g.names[name] == nil {
+		g.names[name] = rrsets{}
+	}
+	g.names[name][qtype] = append(g.names[name][qtype], v)
+	return nil
+}
@@ -0,0 +1,128 @@
+package geodns
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/coredns/caddy"
+
+	"github.com/miekg/dns"
+)
+
+func TestSetup(t *testing.T) {
+	tests := []struct {
+		input     string
+		shouldErr bool
+		names     int
+	}{
+		{`geodns example.org`, false, 0},
+		{`geodns example.org {
+			default www 300 IN A 192.0.2.1
+			default www 300 IN A 192.0.2.2
+			country:NL,BE www.example.org. 300 IN A 192.0.2.10
+			net:10.0.0.0/8,2001:db8::/32 www 300 IN AAAA 2001:db8::1
+			region:US-CA cdn 300 IN CNAME cdn.example.net.
+			edns-subnet
+		}`, false, 2},
+		{`geodns example.org {
+			default www.example.net. 300 IN A 192.0.2.1
+		}`, true, 0},
+		{`geodns example.org {
+			planet:earth www 300 IN A 192.0.2.1
+		}`, true, 0},
+		{`geodns example.org {
+			net:10.0.0.0/33 www 300 IN A 192.0.2.1
+		}`, true, 0},
+		{`geodns example.org {
+			default www 300 IN A 192.0.2.
+		}`, true, 0},
+		{`geodns example.org {
+			default
+		}`, true, 0},
+		{`geodns example.org {
+			bogus
+		}`, true, 0},
+		{`geodns example.org {
+			file /does/not/exist
+		}`, true, 0},
+		{`geodns example.org
+		geodns example.net`, true, 0},
+	}
+
+	for i, tc := range tests {
+		c := caddy.NewTestController("dns", tc.input)
+		g, err := geodnsParse(c)
+		if tc.shouldErr {
+			if err == nil {
+				t.Errorf("Test %d: expected error, got none", i)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("Test %d: expected no error, got %s", i, err)
+			continue
+		}
+		if len(g.names) != tc.names {
+			t.Errorf("Test %d: expected %d names, got %d", i, tc.names, len(g.names))
+		}
+	}
+}
+
+func TestSetupMerge(t *testing.T) {
+	c := caddy.NewTestController("dns", `geodns example.org {
+		default www 300 IN A 192.0.2.1
+		country:NL www 300 IN A 192.0.2.10
+		default www 300 IN A 192.0.2.2
+	}`)
+	g, err := geodnsParse(c)
+	if err != nil {
+		t.Fatalf("Expected no error, got %s", err)
+	}
+	variants := g.names["www.example.org."][dns.TypeA]
+	if len(variants) != 2 {
+		t.Fatalf("Expected 2 variants, got %d", len(variants))
+	}
+	if len(variants[0].rrs) != 2 {
+		t.Errorf("Expected 2 records in the default variant, got %d", len(variants[0].rrs))
+	}
+}
+
+func TestSetupFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "geo.db")
+	data := `# Addresses by location.
+default www 300 IN A 192.0.2.1
+
+; Europe
+continent:EU www 300 IN A 192.0.2.20
+country:GB   www 300 IN A 192.0.2.30
+asn:AS64512  www 300 IN TXT "served by AS64512"
+`
+	if err := os.WriteFile(path, []byte(data), 0644); err != nil {
+		t.Fatal(err)
+	}
+	c := caddy.NewTestController("dns", `geodns example.org {
+		file `+path+`
+	}`)
+	g, err := geodnsParse(c)
+	if err != nil {
+		t.Fatalf("Expected no error, got %s", err)
+	}
+	if x := len(g.names["www.example.org."][dns.TypeA]); x != 3 {
+		t.Errorf("Expected 3 variants, got %d", x)
+	}
+	txt := g.names["www.example.org."][dns.TypeTXT]
+	if len(txt) != 1 || txt[0].rrs[0].(*dns.TXT).Txt[0] != "served by AS64512" {
+		t.Errorf("Expected TXT variant, got %v", txt)
+	}
+
+	if err := os.WriteFile(path, []byte("default www 300 IN A 192.0.2.1\ncountry:NL\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	c = caddy.NewTestController("dns", `geodns example.org {
+		file `+path+`
+	}`)
+	if _, err := geodnsParse(c); err == nil {
+		t.Errorf("Expected error for line without resource record, got none")
+	}
+}
@@ -0,0 +1,21 @@
+package geoip
+
+import (
+	"context"
+	"strconv"
+
+	"github.com/coredns/coredns/plugin/metadata"
+
+	"github.com/oschwald/geoip2-golang"
+)
+
+func setASNMetadata(ctx context.Context, data *geoip2.ASN) {
+	number := strconv.FormatUint(uint64(data.AutonomousSystemNumber), 10)
+	metadata.SetValueFunc(ctx, pluginName+"/asn/number", func() string {
+		return number
+	})
+	org := data.AutonomousSystemOrganization
+	metadata.SetValueFunc(ctx, pluginName+"/asn/org", func() string {
+		return org
+	})
+}
@@ -3,6 +3,7 @@ package geoip
 import (
 	"context"
 	"strconv"
+	"strings"
 
 	"github.com/coredns/coredns/plugin/metadata"
 
@@ -11,7 +12,7 @@ import (
 
 const defaultLang = "en"
 
-func (g GeoIP) setCityMetadata(ctx context.Context, data *geoip2.City) {
+func setCityMetadata(ctx context.Context, data *geoip2.City) {
 	// Set labels for city, country and continent names.
 	cityName := data.City.Names[defaultLang]
 	metadata.SetValueFunc(ctx, pluginName+"/city/name", func() string {
@@ -30,6 +31,14 @@ func (g GeoIP) setCityMetadata(ctx context.Context, data *geoip2.City) {
 	metadata.SetValueFunc(ctx, pluginName+"/country/code", func() string {
 		return countryCode
 	})
+	subdivisionCodes := make([]string, len(data.Subdivisions))
+	for i, s := range data.Subdivisions {
+		subdivisionCodes[i] = countryCode + "-" + s.IsoCode
+	}
+	subdivisionCode := strings.Join(subdivisionCodes, ",")
+	metadata.SetValueFunc(ctx, pluginName+"/subdivisions/code", func() string {
+		return subdivisionCode
+	})
 	isInEurope := strconv.FormatBool(data.Country.IsInEuropeanUnion)
 	metadata.SetValueFunc(ctx, pluginName+"/country/is_in_european_union", func() string {
 		return isInEurope
@@ -0,0 +1,34 @@
+package geoip
+
+import (
+	"context"
+	"strconv"
+
+	"github.com/coredns/coredns/plugin/metadata"
+
+	"github.com/oschwald/geoip2-golang"
+)
+
+func setCountryMetadata(ctx context.Context, data *geoip2.Country) {
+	countryName := data.Country.Names[defaultLang]
+	metadata.SetValueFunc(ctx, pluginName+"/country/name", func() string {
+		return countryName
+	})
+	continentName := data.Continent.Names[defaultLang]
+	metadata.SetValueFunc(ctx, pluginName+"/continent/name", func() string {
+		return continentName
+	})
+
+	countryCode := data.Country.IsoCode
+	metadata.SetValueFunc(ctx, pluginName+"/country/code", func() string {
+		return countryCode
+	})
+	isInEurope := strconv.FormatBool(data.Country.IsInEuropeanUnion)
+	metadata.SetValueFunc(ctx, pluginName+"/country/is_in_european_union", func() string {
+		return isInEurope
+	})
+	continentCode := data.Continent.Code
+	metadata.SetValueFunc(ctx, pluginName+"/continent/code", func() string {
+		return continentCode
+	})
+}
@@ -6,6 +6,7 @@ import (
 	"fmt"
 	"net"
 	"path/filepath"
+	"strings"
 
 	"github.com/coredns/coredns/plugin"
 	clog "github.com/coredns/coredns/plugin/pkg/log"
@@ -21,7 +22,7 @@ var log = clog.NewWithPlugin(pluginName)
 // geoIP2 database, and which data can be later consumed by other middlewares.
 type GeoIP struct {
 	Next  plugin.Handler
-	db    db
+	dbs   []db
 	edns0 bool
 }
 
@@ -34,14 +35,40 @@ type db struct {
 
 const (
 	city = 1 << iota
+	country
+	asn
+	isp
+	connectionType
 )
 
 var probingIP = net.ParseIP("127.0.0.1")
 
-func newGeoIP(dbPath string, edns0 bool) (*GeoIP, error) {
+func newGeoIP(dbPaths []string, edns0 bool) (*GeoIP, error) {
+	g := &GeoIP{edns0: edns0}
+	provided := 0
+	for _, dbPath := range dbPaths {
+		db, err := openDB(dbPath)
+		if err != nil {
+			return nil, err
+		}
+		// A schema is looked up in the first database that provides it.
+		db.provides &^= provided
+		if db.provides == 0 {
+			return nil, fmt.Errorf("database %q does not provide a schema not provided by another database", filepath.Base(dbPath))
+		}
+		provided |= db.provides
+		if provided&city == city {
+			provided |= country
+		}
+		g.dbs = append(g.dbs, db)
+	}
+	return g, nil
+}
+
+func openDB(dbPath string) (db, error) {
 	reader, err := geoip2.Open(dbPath)
 	if err != nil {
-		return nil, fmt.Errorf("failed to open database file: %v", err)
+		return db{}, fmt.Errorf("failed to open database file: %v", err)
 	}
 	db := db{Reader: reader}
 	schemas := []struct {
@@ -49,25 +76,40 @@ func newGeoIP(dbPath string, edns0 bool) (*GeoIP, error) {
 		name     string
 		validate func() error
 	}{
-		{name: "city", provides: city, validate: func() error { _, err := reader.City(probingIP); return err }},
+		{name: "city", provides: city, validate: func() error {
+			// Country databases allow city lookups, but don't have city data.
+			if strings.Contains(reader.Metadata().DatabaseType, "Country") {
+				return geoip2.InvalidMethodError{Method: "City", DatabaseType: reader.Metadata().DatabaseType}
+			}
+			_, err := reader.City(probingIP)
+			return err
+		}},
+		{name: "country", provides: country, validate: func() error { _, err := reader.Country(probingIP); return err }},
+		{name: "asn", provides: asn, validate: func() error { _, err := reader.ASN(probingIP); return err }},
+		{name: "isp", provides: isp, validate: func() error { _, err := reader.ISP(probingIP); return err }},
+		{name: "connection-type", provides: connectionType, validate: func() error { _, err := reader.ConnectionType(probingIP); return err }},
 	}
 	// Query the database to figure out the database type.
 	for _, schema := range schemas {
 		if err := schema.validate(); err != nil {
 			// If we get an InvalidMethodError then we know this database does not provide that schema.
 			if _, ok := err.(geoip2.InvalidMethodError); !ok {
-				return nil, fmt.Errorf("unexpected failure looking up database %q schema %q: %v", filepath.Base(dbPath), schema.name, err)
+				return db, fmt.Errorf("unexpected failure looking up database %q schema %q: %v", filepath.Base(dbPath), schema.name, err)
 			}
 		} else {
 			db.provides |= schema.provides
 		}
 	}
+	// The city schema contains the country schema.
+	if db.provides&city == city {
+		db.provides &^= country
+	}
 
-	if db.provides&city == 0 {
-		return nil, fmt.Errorf("database does not provide city schema")
+	if db.provides == 0 {
+		return db, fmt.Errorf("database %q does not provide a supported schema", filepath.Base(dbPath))
 	}
 
-	return &GeoIP{db: db, edns0: edns0}, nil
+	return db, nil
 }
 
 // ServeDNS implements the plugin.Handler interface.
@@ -91,16 +133,54 @@ func (g GeoIP) Metadata(ctx context.Context, state request.Request) context.Cont
 		}
 	}
 
-	switch {
-	case g.db.provides&city == city:
-		data, err := g.db.City(srcIP)
+	for _, db := range g.dbs {
+		db.setMetadata(ctx, srcIP)
+	}
+	return ctx
+}
+
+// setMetadata looks up srcIP in db and sets the metadata of the schemas it provides.
+func (db db) setMetadata(ctx context.Context, srcIP net.IP) {
+	if db.provides&city == city {
+		data, err := db.City(srcIP)
 		if err != nil {
 			log.Debugf("Setting up metadata failed due to database lookup error: %v", err)
-			return ctx
+			return
 		}
-		g.setCityMetadata(ctx, data)
+		setCityMetadata(ctx, data)
+	}
+	if db.provides&country == country {
+		data, err := db.Country(srcIP)
+		if err != nil {
+			log.Debugf("Setting up metadata failed due to database lookup error: %v", err)
+			return
+		}
+		setCountryMetadata(ctx, data)
+	}
+	if db.provides&asn == asn {
+		data, err := db.ASN(srcIP)
+		if err != nil {
+			log.Debugf("Setting up metadata failed due to database lookup error: %v", err)
+			return
+		}
+		setASNMetadata(ctx, data)
+	}
+	if db.provides&isp == isp {
+		data, err := db.ISP(srcIP)
+		if err != nil {
+			log.Debugf("Setting up metadata failed due to database lookup error: %v", err)
+			return
+		}
+		setISPMetadata(ctx, data)
+	}
+	if db.provides&connectionType == connectionType {
+		data, err := db.ConnectionType(srcIP)
+		if err != nil {
+			log.Debugf("Setting up metadata failed due to database lookup error: %v", err)
+			return
+		}
+		setConnectionTypeMetadata(ctx, data)
 	}
-	return ctx
 }
 
 // Name implements the Handler interface.
@@ -22,6 +22,7 @@ func TestMetadata(t *testing.T) {
 
 		{"geoip/country/code", "GB"},
 		{"geoip/country/name", "United Kingdom"},
+		{"geoip/subdivisions/code", "GB-ENG,GB-CAM"},
 		// is_in_european_union is set to true only to work around bool zero value, and test is really being set.
 		{"geoip/country/is_in_european_union", "true"},
 
@@ -37,7 +38,7 @@ func TestMetadata(t *testing.T) {
 	knownIPAddr := "81.2.69.142" // This IP should be be part of the CDIR address range used to create the database fixtures.
 	for _, tc := range tests {
 		t.Run(fmt.Sprintf("%s/%s", tc.label, "direct"), func(t *testing.T) {
-			geoIP, err := newGeoIP(cityDBPath, false)
+			geoIP, err := newGeoIP([]string{cityDBPath}, false)
 			if err != nil {
 				t.Fatalf("unable to create geoIP plugin: %v", err)
 			}
@@ -49,7 +50,7 @@ func TestMetadata(t *testing.T) {
 		})
 
 		t.Run(fmt.Sprintf("%s/%s", tc.label, "subnet"), func(t *testing.T) {
-			geoIP, err := newGeoIP(cityDBPath, true)
+			geoIP, err := newGeoIP([]string{cityDBPath}, true)
 			if err != nil {
 				t.Fatalf("unable to create geoIP plugin: %v", err)
 			}
@@ -70,6 +71,46 @@ func TestMetadata(t *testing.T) {
 	}
 }
 
+func TestMetadataSchemas(t *testing.T) {
+	tests := []struct {
+		dbPaths       []string
+		label         string
+		expectedValue string
+	}{
+		{[]string{countryDBPath}, "geoip/country/code", "GB"},
+		{[]string{countryDBPath}, "geoip/continent/code", "EU"},
+		{[]string{countryDBPath}, "geoip/country/is_in_european_union", "true"},
+		{[]string{asnDBPath}, "geoip/asn/number", "20712"},
+		{[]string{asnDBPath}, "geoip/asn/org", "Andrews & Arnold Ltd"},
+		{[]string{ispDBPath}, "geoip/asn/number", "20712"},
+		{[]string{ispDBPath}, "geoip/isp/name", "Andrews & Arnold Ltd"},
+		{[]string{ispDBPath}, "geoip/isp/org", "STONEHOUSE office network"},
+		{[]string{ispDBPath}, "geoip/isp/mobile_country_code", "234"},
+		{[]string{ispDBPath}, "geoip/isp/mobile_network_code", "10"},
+		{[]string{connectionTypeDBPath}, "geoip/connection/type", "Corporate"},
+		{[]string{cityDBPath, asnDBPath}, "geoip/city/name", "Cambridge"},
+		{[]string{cityDBPath, asnDBPath}, "geoip/asn/number", "20712"},
+		{[]string{countryDBPath, ispDBPath, connectionTypeDBPath}, "geoip/country/code", "GB"},
+		{[]string{countryDBPath, ispDBPath, connectionTypeDBPath}, "geoip/isp/name", "Andrews & Arnold Ltd"},
+		{[]string{countryDBPath, ispDBPath, connectionTypeDBPath}, "geoip/connection/type", "Corporate"},
+	}
+
+	knownIPAddr := "81.2.69.142"
+	for _, tc := range tests {
+		t.Run(fmt.Sprintf("%v/%s", tc.dbPaths, tc.label), func(t *testing.T) {
+			geoIP, err := newGeoIP(tc.dbPaths, false)
+			if err != nil {
+				t.Fatalf("unable to create geoIP plugin: %v", err)
+			}
+			state := request.Request{
+				Req: new(dns.Msg),
+				W:   &test.ResponseWriter{RemoteIP: knownIPAddr},
+			}
+			testMetadata(t, state, geoIP, tc.label, tc.expectedValue)
+		})
+	}
+}
+
 func testMetadata(t *testing.T, state request.Request, geoIP *GeoIP, label, expectedValue string) {
 	ctx := metadata.ContextWithMetadata(context.Background())
 	rCtx := geoIP.Metadata(ctx, state)
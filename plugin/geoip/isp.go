@@ -0,0 +1,35 @@
+package geoip
+
+import (
+	"context"
+
+	"github.com/coredns/coredns/plugin/metadata"
+
+	"github.com/oschwald/geoip2-golang"
+)
+
+func setISPMetadata(ctx context.Context, data *geoip2.ISP) {
+	name := data.ISP
+	metadata.SetValueFunc(ctx, pluginName+"/isp/name", func() string {
+		return name
+	})
+	org := data.Organization
+	metadata.SetValueFunc(ctx, pluginName+"/isp/org", func() string {
+		return org
+	})
+	mcc := data.MobileCountryCode
+	metadata.SetValueFunc(ctx, pluginName+"/isp/mobile_country_code", func() string {
+		return mcc
+	})
+	mnc := data.MobileNetworkCode
+	metadata.SetValueFunc(ctx, pluginName+"/isp/mobile_network_code", func() string {
+		return mnc
+	})
+}
+
+func setConnectionTypeMetadata(ctx context.Context, data *geoip2.ConnectionType) {
+	connectionType := data.ConnectionType
+	metadata.SetValueFunc(ctx, pluginName+"/connection/type", func() string {
+		return connectionType
+	})
+}
@@ -25,21 +25,15 @@ func setup(c *caddy.Controller) error {
 }
 
 func geoipParse(c *caddy.Controller) (*GeoIP, error) {
-	var dbPath string
+	var dbPaths []string
 	var edns0 bool
 
 	for c.Next() {
-		if !c.NextArg() {
-			return nil, c.ArgErr()
-		}
-		if dbPath != "" {
-			return nil, c.Errf("configuring multiple databases is not supported")
-		}
-		dbPath = c.Val()
-		// There shouldn't be any more arguments.
-		if len(c.RemainingArgs()) != 0 {
+		args := c.RemainingArgs()
+		if len(args) == 0 {
 			return nil, c.ArgErr()
 		}
+		dbPaths = append(dbPaths, args...)
 
 		for c.NextBlock() {
 			if c.Val() != "edns-subnet" {
@@ -49,7 +43,7 @@ func geoipParse(c *caddy.Controller) (*GeoIP, error) {
 		}
 	}
 
-	geoIP, err := newGeoIP(dbPath, edns0)
+	geoIP, err := newGeoIP(dbPaths, edns0)
 	if err != nil {
 		return geoIP, c.Err(err.Error())
 	}
@@ -108,6 +108,14 @@ func newGRPC() *GRPC {
 // Name implements the Handler interface.
 func (g *GRPC) Name() string { return "grpc" }
 
+// OnShutdown closes the streams to the upstreams.
+func (g *GRPC) OnShutdown() error {
+	for _, p := range g.proxies {
+		p.stop()
+	}
+	return nil
+}
+
 // Len returns the number of configured proxies.
 func (g *GRPC) len() int { return len(g.proxies) }
 
@@ -4,6 +4,8 @@ import (
 	"context"
 	"crypto/tls"
 	"strconv"
+	"sync"
+	"sync/atomic"
 	"time"
 
 	"github.com/coredns/coredns/pb"
@@ -23,6 +25,10 @@ type Proxy struct {
 	// connection
 	client   pb.DnsServiceClient
 	dialOpts []grpc.DialOption
+
+	streamMu sync.Mutex
+	stream   *stream
+	unary    uint32 // set to 1 when the upstream doesn't support streams, accessed atomically
 }
 
 // newProxy returns a new proxy.
@@ -50,12 +56,7 @@ func newProxy(addr string, tlsConfig *tls.Config) (*Proxy, error) {
 func (p *Proxy) query(ctx context.Context, req *dns.Msg) (*dns.Msg, error) {
 	start := time.Now()
 
-	msg, err := req.Pack()
-	if err != nil {
-		return nil, err
-	}
-
-	reply, err := p.client.Query(ctx, &pb.DnsPacket{Msg: msg})
+	ret, err := p.exchange(ctx, req)
 	if err != nil {
 		// if not found message, return empty message with NXDomain code
 		if status.Code(err) == codes.NotFound {
@@ -64,10 +65,6 @@ func (p *Proxy) query(ctx context.Context, req *dns.Msg) (*dns.Msg, error) {
 		}
 		return nil, err
 	}
-	ret := new(dns.Msg)
-	if err := ret.Unpack(reply.Msg); err != nil {
-		return nil, err
-	}
 
 	rc, ok := dns.RcodeToString[ret.Rcode]
 	if !ok {
@@ -80,3 +77,60 @@ func (p *Proxy) query(ctx context.Context, req *dns.Msg) (*dns.Msg, error) {
 
 	return ret, nil
 }
+
+// exchange sends req on the stream to the upstream, or with a Query call if the upstream doesn't
+// support streams.
+func (p *Proxy) exchange(ctx context.Context, req *dns.Msg) (*dns.Msg, error) {
+	ctx, cancel := context.WithTimeout(ctx, defaultTimeout)
+	defer cancel()
+
+	if atomic.LoadUint32(&p.unary) == 0 {
+		ret, err := p.streamQuery(ctx, req)
+		if status.Code(err) != codes.Unimplemented {
+			return ret, err
+		}
+		atomic.StoreUint32(&p.unary, 1)
+	}
+
+	msg, err := req.Pack()
+	if err != nil {
+		return nil, err
+	}
+
+	reply, err := p.client.Query(ctx, &pb.DnsPacket{Msg: msg})
+	if err != nil {
+		return nil, err
+	}
+	ret := new(dns.Msg)
+	if err := ret.Unpack(reply.Msg); err != nil {
+		return nil, err
+	}
+	return ret, nil
+}
+
+// streamQuery sends req on the stream to the upstream, a new stream is opened if there is none yet or
+// the previous one broke.
+func (p *Proxy) streamQuery(ctx context.Context, req *dns.Msg) (*dns.Msg, error) {
+	p.streamMu.Lock()
+	if p.stream == nil || p.stream.broken() {
+		st, err := newStream(p.client)
+		if err != nil {
+			p.streamMu.Unlock()
+			return nil, err
+		}
+		p.stream = st
+	}
+	st := p.stream
+	p.streamMu.Unlock()
+
+	return st.query(ctx, req)
+}
+
+// stop closes the stream to the upstream.
+func (p *Proxy) stop() {
+	p.streamMu.Lock()
+	if p.stream != nil {
+		p.stream.close(errStreamClosed)
+	}
+	p.streamMu.Unlock()
+}
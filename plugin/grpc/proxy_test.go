@@ -9,7 +9,9 @@ import (
 
 	"github.com/miekg/dns"
 	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
 	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/status"
 )
 
 func TestProxy(t *testing.T) {
@@ -64,3 +66,11 @@ type testServiceClient struct {
 func (m testServiceClient) Query(ctx context.Context, in *pb.DnsPacket, opts ...grpc.CallOption) (*pb.DnsPacket, error) {
 	return m.dnsPacket, m.err
 }
+
+func (m testServiceClient) Stream(ctx context.Context, opts ...grpc.CallOption) (pb.DnsService_StreamClient, error) {
+	return nil, status.Error(codes.Unimplemented, "method Stream not implemented")
+}
+
+func (m testServiceClient) Watch(ctx context.Context, in *pb.DnsPacket, opts ...grpc.CallOption) (pb.DnsService_WatchClient, error) {
+	return nil, status.Error(codes.Unimplemented, "method Watch not implemented")
+}
@@ -23,6 +23,8 @@ func setup(c *caddy.Controller) error {
 		return plugin.Error("grpc", fmt.Errorf("more than %d TOs configured: %d", max, g.len()))
 	}
 
+	c.OnShutdown(g.OnShutdown)
+
 	dnsserver.GetConfig(c).AddPlugin(func(next plugin.Handler) plugin.Handler {
 		g.Next = next // Set the Next field, so the plugin chaining works.
 		return g
@@ -0,0 +1,154 @@
+package grpc
+
+import (
+	"context"
+	"encoding/binary"
+	"errors"
+	"sync"
+
+	"github.com/coredns/coredns/pb"
+
+	"github.com/miekg/dns"
+)
+
+// stream is a DnsService Stream to an upstream, on which many queries are in flight at the same time.
+// The upstream can reply in any order, so each query gets a message ID that is unique on the stream, and
+// replies are matched to their query on it.
+type stream struct {
+	s      pb.DnsService_StreamClient
+	cancel context.CancelFunc
+	sendMu sync.Mutex
+
+	mu      sync.Mutex
+	pending map[uint16]chan *dns.Msg // queries waiting for a reply, by the ID they were sent with, nil is a malformed reply
+	id      uint16                   // last ID used
+	err     error                    // why the stream broke, if it did
+	done    chan struct{}            // closed when the stream broke
+}
+
+var (
+	// errStreamFull is returned when all message IDs are in use on a stream.
+	errStreamFull = errors.New("too many queries in flight on stream")
+	// errStreamClosed is returned for queries on a stream that was closed on shutdown.
+	errStreamClosed = errors.New("stream closed")
+	// errMalformedReply is returned for queries of which the reply can't be unpacked.
+	errMalformedReply = errors.New("malformed reply on stream")
+)
+
+// newStream opens a stream with client.
+func newStream(client pb.DnsServiceClient) (*stream, error) {
+	ctx, cancel := context.WithCancel(context.Background())
+	s, err := client.Stream(ctx)
+	if err != nil {
+		cancel()
+		return nil, err
+	}
+	st := &stream{s: s, cancel: cancel, pending: make(map[uint16]chan *dns.Msg), done: make(chan struct{})}
+	go st.recv()
+	return st, nil
+}
+
+// query sends req on the stream and waits for its reply.
+func (st *stream) query(ctx context.Context, req *dns.Msg) (*dns.Msg, error) {
+	reply := make(chan *dns.Msg, 1)
+	st.mu.Lock()
+	if st.err != nil {
+		err := st.err
+		st.mu.Unlock()
+		return nil, err
+	}
+	if len(st.pending) > 0xFFFF {
+		st.mu.Unlock()
+		return nil, errStreamFull
+	}
+	for {
+		st.id++
+		if _, ok := st.pending[st.id]; !ok {
+			break
+		}
+	}
+	id := st.id
+	st.pending[id] = reply
+	st.mu.Unlock()
+
+	defer func() {
+		st.mu.Lock()
+		delete(st.pending, id)
+		st.mu.Unlock()
+	}()
+
+	// Copy the header, so req's ID is left alone.
+	m := *req
+	m.Id = id
+	msg, err := m.Pack()
+	if err != nil {
+		return nil, err
+	}
+	st.sendMu.Lock()
+	err = st.s.Send(&pb.DnsPacket{Msg: msg})
+	st.sendMu.Unlock()
+	if err != nil {
+		// When the stream broke Send returns io.EOF, the real error is returned by Recv.
+		<-st.done
+		return nil, st.error()
+	}
+
+	select {
+	case ret := <-reply:
+		if ret == nil {
+			return nil, errMalformedReply
+		}
+		ret.Id = req.Id
+		return ret, nil
+	case <-st.done:
+		return nil, st.error()
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+}
+
+// recv receives the replies and hands them to the queries waiting for them, until the stream breaks.
+func (st *stream) recv() {
+	for {
+		in, err := st.s.Recv()
+		if err != nil {
+			st.close(err)
+			return
+		}
+		if len(in.Msg) < 2 {
+			continue
+		}
+		id := binary.BigEndian.Uint16(in.Msg)
+		ret := new(dns.Msg)
+		if err := ret.Unpack(in.Msg); err != nil {
+			ret = nil
+		}
+		st.mu.Lock()
+		reply, ok := st.pending[id]
+		delete(st.pending, id)
+		st.mu.Unlock()
+		if ok {
+			reply <- ret
+		}
+	}
+}
+
+// close closes the stream, queries in flight and new ones fail with err.
+func (st *stream) close(err error) {
+	st.mu.Lock()
+	if st.err == nil {
+		st.err = err
+		close(st.done)
+	}
+	st.mu.Unlock()
+	st.cancel()
+}
+
+// broken returns true if the stream broke.
+func (st *stream) broken() bool { return st.error() != nil }
+
+func (st *stream) error() error {
+	st.mu.Lock()
+	defer st.mu.Unlock()
+	return st.err
+}
@@ -0,0 +1,193 @@
+package grpc
+
+import (
+	"context"
+	"net"
+	"sync"
+	"testing"
+
+	"github.com/coredns/coredns/pb"
+	"github.com/coredns/coredns/plugin/test"
+
+	"github.com/miekg/dns"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// testServer answers queries with an A record for 127.0.0.1, with Query or, if stream is true, Stream.
+type testServer struct {
+	*pb.UnimplementedDnsServiceServer
+	stream    bool
+	malformed bool // reply on the stream with only the ID of the query
+
+	mu      sync.Mutex
+	queries int
+	streams int
+}
+
+func (s *testServer) Query(ctx context.Context, in *pb.DnsPacket) (*pb.DnsPacket, error) {
+	s.mu.Lock()
+	s.queries++
+	s.mu.Unlock()
+	return answer(in)
+}
+
+func (s *testServer) Stream(stream pb.DnsService_StreamServer) error {
+	if !s.stream {
+		return status.Error(codes.Unimplemented, "method Stream not implemented")
+	}
+	s.mu.Lock()
+	s.streams++
+	s.mu.Unlock()
+
+	// Reply to every pair of queries in reverse order.
+	var held *pb.DnsPacket
+	for {
+		in, err := stream.Recv()
+		if err != nil {
+			return nil
+		}
+		if s.malformed {
+			stream.Send(&pb.DnsPacket{Msg: in.Msg[:2]})
+			continue
+		}
+		reply, err := answer(in)
+		if err != nil {
+			return err
+		}
+		if held == nil {
+			held = reply
+			continue
+		}
+		stream.Send(reply)
+		stream.Send(held)
+		held = nil
+	}
+}
+
+func answer(in *pb.DnsPacket) (*pb.DnsPacket, error) {
+	m := new(dns.Msg)
+	if err := m.Unpack(in.Msg); err != nil {
+		return nil, err
+	}
+	r := new(dns.Msg)
+	r.SetReply(m)
+	r.Answer = []dns.RR{test.A(m.Question[0].Name + " 5 IN A 127.0.0.1")}
+	packed, err := r.Pack()
+	return &pb.DnsPacket{Msg: packed}, err
+}
+
+func newTestServer(t *testing.T, s *testServer) string {
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	srv := grpc.NewServer()
+	pb.RegisterDnsServiceServer(srv, s)
+	go srv.Serve(l)
+	t.Cleanup(srv.Stop)
+	return l.Addr().String()
+}
+
+func TestProxyStream(t *testing.T) {
+	s := &testServer{stream: true}
+	p, err := newProxy(newTestServer(t, s), nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer p.stop()
+
+	names := []string{"a.example.org.", "b.example.org.", "c.example.org.", "d.example.org."}
+	var wg sync.WaitGroup
+	for i, name := range names {
+		wg.Add(1)
+		go func(id uint16, name string) {
+			defer wg.Done()
+			m := new(dns.Msg)
+			m.SetQuestion(name, dns.TypeA)
+			m.Id = id
+			ret, err := p.query(context.TODO(), m)
+			if err != nil {
+				t.Errorf("Expected reply for %s, got error: %s", name, err)
+				return
+			}
+			if ret.Id != id || ret.Question[0].Name != name {
+				t.Errorf("Expected reply for %s with ID %d, got %s with ID %d", name, id, ret.Question[0].Name, ret.Id)
+			}
+		}(uint16(i+1)*1000, name)
+	}
+	wg.Wait()
+
+	if s.queries != 0 || s.streams != 1 {
+		t.Errorf("Expected 1 stream and no queries, got %d streams and %d queries", s.streams, s.queries)
+	}
+}
+
+func TestProxyStreamUnimplemented(t *testing.T) {
+	s := &testServer{}
+	p, err := newProxy(newTestServer(t, s), nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer p.stop()
+
+	for i := 0; i < 2; i++ {
+		m := new(dns.Msg)
+		m.SetQuestion("example.org.", dns.TypeA)
+		if _, err := p.query(context.TODO(), m); err != nil {
+			t.Fatalf("Expected reply, got error: %s", err)
+		}
+	}
+	if s.queries != 2 {
+		t.Errorf("Expected upstream without streams to get 2 queries, got %d", s.queries)
+	}
+}
+
+func TestProxyStreamReopen(t *testing.T) {
+	s := &testServer{stream: true}
+	p, err := newProxy(newTestServer(t, s), nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer p.stop()
+
+	p.streamMu.Lock()
+	st, err := newStream(p.client)
+	if err != nil {
+		t.Fatal(err)
+	}
+	st.close(errStreamClosed)
+	p.stream = st
+	p.streamMu.Unlock()
+
+	// Two queries, so the server replies.
+	var wg sync.WaitGroup
+	for i := 0; i < 2; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			m := new(dns.Msg)
+			m.SetQuestion("example.org.", dns.TypeA)
+			if _, err := p.query(context.TODO(), m); err != nil {
+				t.Errorf("Expected reply on a new stream, got error: %s", err)
+			}
+		}()
+	}
+	wg.Wait()
+}
+
+func TestProxyStreamMalformed(t *testing.T) {
+	s := &testServer{stream: true, malformed: true}
+	p, err := newProxy(newTestServer(t, s), nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer p.stop()
+
+	m := new(dns.Msg)
+	m.SetQuestion("example.org.", dns.TypeA)
+	if _, err := p.query(context.TODO(), m); err != errMalformedReply {
+		t.Errorf("Expected %q, got %v", errMalformedReply, err)
+	}
+}
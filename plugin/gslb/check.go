@@ -0,0 +1,155 @@
+package gslb
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"net/http"
+	"net/url"
+	"strconv"
+	"time"
+
+	"github.com/miekg/dns"
+)
+
+// checker checks the health of a member.
+type checker interface {
+	// Check returns an error when the member with address addr is not healthy.
+	Check(ctx context.Context, addr net.IP) error
+}
+
+// tcpCheck checks if a TCP connection can be made to a port of the member.
+type tcpCheck struct {
+	port string
+}
+
+func (t *tcpCheck) Check(ctx context.Context, addr net.IP) error {
+	var d net.Dialer
+	conn, err := d.DialContext(ctx, "tcp", net.JoinHostPort(addr.String(), t.port))
+	if err != nil {
+		return err
+	}
+	return conn.Close()
+}
+
+// httpCheck checks if a GET of a URL, sent to the member, returns a status below 400. The host
+// in the URL is used for the Host header and TLS, the connection is made to the member.
+type httpCheck struct {
+	url    *url.URL
+	client *http.Client
+	port   string
+}
+
+func newHTTPCheck(u *url.URL) *httpCheck {
+	port := u.Port()
+	if port == "" {
+		port = "80"
+		if u.Scheme == "https" {
+			port = "443"
+		}
+	}
+	h := &httpCheck{url: u, port: port}
+	h.client = &http.Client{
+		Transport: &http.Transport{DisableKeepAlives: true, DialContext: h.dial},
+		// Redirects are not followed, they already show the member is alive.
+		CheckRedirect: func(*http.Request, []*http.Request) error { return http.ErrUseLastResponse },
+	}
+	return h
+}
+
+type addrKey struct{}
+
+func (h *httpCheck) Check(ctx context.Context, addr net.IP) error {
+	req, err := http.NewRequestWithContext(context.WithValue(ctx, addrKey{}, addr), http.MethodGet, h.url.String(), nil)
+	if err != nil {
+		return err
+	}
+	resp, err := h.client.Do(req)
+	if err != nil {
+		return err
+	}
+	resp.Body.Close()
+	if resp.StatusCode >= 400 {
+		return fmt.Errorf("unexpected status %d", resp.StatusCode)
+	}
+	return nil
+}
+
+// dial connects to the member in ctx, instead of the host in the URL.
+func (h *httpCheck) dial(ctx context.Context, network, _ string) (net.Conn, error) {
+	addr, _ := ctx.Value(addrKey{}).(net.IP)
+	var d net.Dialer
+	return d.DialContext(ctx, network, net.JoinHostPort(addr.String(), h.port))
+}
+
+// dnsCheck checks if the member answers a DNS query over UDP. Any answer but SERVFAIL and REFUSED
+// counts as healthy.
+type dnsCheck struct {
+	port  string
+	qname string
+}
+
+func (d *dnsCheck) Check(ctx context.Context, addr net.IP) error {
+	m := new(dns.Msg)
+	m.SetQuestion(d.qname, dns.TypeNS)
+
+	c := new(dns.Client)
+	if deadline, ok := ctx.Deadline(); ok {
+		c.Timeout = time.Until(deadline)
+	}
+	r, _, err := c.ExchangeContext(ctx, m, net.JoinHostPort(addr.String(), d.port))
+	if err != nil {
+		return err
+	}
+	if r.Rcode == dns.RcodeServerFailure || r.Rcode == dns.RcodeRefused {
+		return fmt.Errorf("unexpected rcode %s", dns.RcodeToString[r.Rcode])
+	}
+	return nil
+}
+
+// newChecker returns the checker of the type typ, with its arguments args.
+func newChecker(typ string, args []string) (checker, error) {
+	switch typ {
+	case "tcp":
+		if len(args) != 1 {
+			return nil, fmt.Errorf("tcp check needs a port")
+		}
+		if err := validPort(args[0]); err != nil {
+			return nil, err
+		}
+		return &tcpCheck{port: args[0]}, nil
+	case "http", "https":
+		if len(args) != 1 {
+			return nil, fmt.Errorf("%s check needs a URL", typ)
+		}
+		u, err := url.Parse(args[0])
+		if err != nil {
+			return nil, err
+		}
+		if u.Scheme != typ || u.Host == "" {
+			return nil, fmt.Errorf("invalid %s URL %q", typ, args[0])
+		}
+		return newHTTPCheck(u), nil
+	case "dns":
+		if len(args) == 0 || len(args) > 2 {
+			return nil, fmt.Errorf("dns check needs a port and an optional name")
+		}
+		if err := validPort(args[0]); err != nil {
+			return nil, err
+		}
+		d := &dnsCheck{port: args[0], qname: "."}
+		if len(args) == 2 {
+			d.qname = dns.Fqdn(args[1])
+		}
+		return d, nil
+	}
+	return nil, fmt.Errorf("unknown check type %q", typ)
+}
+
+func validPort(port string) error {
+	p, err := strconv.Atoi(port)
+	if err != nil || p < 1 || p > 65535 {
+		return fmt.Errorf("invalid port %q", port)
+	}
+	return nil
+}
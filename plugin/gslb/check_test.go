@@ -0,0 +1,119 @@
+package gslb
+
+import (
+	"context"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/coredns/coredns/plugin/pkg/dnstest"
+
+	"github.com/miekg/dns"
+)
+
+func TestTCPCheck(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	_, port, _ := net.SplitHostPort(ts.Listener.Addr().String())
+
+	c, err := newChecker("tcp", []string{port})
+	if err != nil {
+		t.Fatal(err)
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+	if err := c.Check(ctx, net.ParseIP("127.0.0.1")); err != nil {
+		t.Errorf("Expected healthy member, got %s", err)
+	}
+	ts.Close()
+	if err := c.Check(ctx, net.ParseIP("127.0.0.1")); err == nil {
+		t.Errorf("Expected unhealthy member, got none")
+	}
+}
+
+func TestHTTPCheck(t *testing.T) {
+	status := http.StatusOK
+	host := ""
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		host = r.Host
+		w.WriteHeader(status)
+	}))
+	defer ts.Close()
+	_, port, _ := net.SplitHostPort(ts.Listener.Addr().String())
+
+	// The URL's host doesn't resolve, the connection is made to the member.
+	c, err := newChecker("http", []string{"http://www.example.invalid:" + port + "/health"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+
+	if err := c.Check(ctx, net.ParseIP("127.0.0.1")); err != nil {
+		t.Errorf("Expected healthy member, got %s", err)
+	}
+	if host != "www.example.invalid:"+port {
+		t.Errorf("Expected Host header www.example.invalid:%s, got %s", port, host)
+	}
+	status = http.StatusFound
+	if err := c.Check(ctx, net.ParseIP("127.0.0.1")); err != nil {
+		t.Errorf("Expected healthy member on redirect, got %s", err)
+	}
+	status = http.StatusServiceUnavailable
+	if err := c.Check(ctx, net.ParseIP("127.0.0.1")); err == nil {
+		t.Errorf("Expected unhealthy member, got none")
+	}
+}
+
+func TestHTTPSCheck(t *testing.T) {
+	ts := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	defer ts.Close()
+	_, port, _ := net.SplitHostPort(ts.Listener.Addr().String())
+
+	// The test certificate is valid for example.com.
+	u, _ := url.Parse("https://example.com:" + port + "/")
+	c := newHTTPCheck(u)
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+
+	if err := c.Check(ctx, net.ParseIP("127.0.0.1")); err == nil {
+		t.Errorf("Expected unhealthy member with an untrusted certificate, got none")
+	}
+	c.client.Transport.(*http.Transport).TLSClientConfig = ts.Client().Transport.(*http.Transport).TLSClientConfig
+	if err := c.Check(ctx, net.ParseIP("127.0.0.1")); err != nil {
+		t.Errorf("Expected healthy member, got %s", err)
+	}
+}
+
+func TestDNSCheck(t *testing.T) {
+	var rcode int32 = dns.RcodeSuccess
+	s := dnstest.NewServer(func(w dns.ResponseWriter, r *dns.Msg) {
+		m := new(dns.Msg)
+		m.SetRcode(r, int(atomic.LoadInt32(&rcode)))
+		w.WriteMsg(m)
+	})
+	defer s.Close()
+	_, port, _ := net.SplitHostPort(s.Addr)
+
+	c, err := newChecker("dns", []string{port, "example.org"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+
+	if err := c.Check(ctx, net.ParseIP("127.0.0.1")); err != nil {
+		t.Errorf("Expected healthy member, got %s", err)
+	}
+	atomic.StoreInt32(&rcode, dns.RcodeNameError)
+	if err := c.Check(ctx, net.ParseIP("127.0.0.1")); err != nil {
+		t.Errorf("Expected healthy member on NXDOMAIN, got %s", err)
+	}
+	atomic.StoreInt32(&rcode, dns.RcodeServerFailure)
+	if err := c.Check(ctx, net.ParseIP("127.0.0.1")); err == nil {
+		t.Errorf("Expected unhealthy member, got none")
+	}
+}
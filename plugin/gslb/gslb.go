@@ -0,0 +1,159 @@
+// Package gslb implements a plugin that answers with the healthy endpoints of a pool.
+package gslb
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/coredns/coredns/plugin"
+	"github.com/coredns/coredns/plugin/pkg/geo"
+	"github.com/coredns/coredns/request"
+
+	"github.com/miekg/dns"
+)
+
+// GSLB is a plugin that answers queries for the names of pools with the addresses of their
+// healthy members.
+type GSLB struct {
+	Next  plugin.Handler
+	Zones []string
+
+	pools    map[string]*pool
+	ttl      uint32
+	answers  int
+	edns0    bool
+	interval time.Duration
+	timeout  time.Duration
+	fails    int
+
+	stop chan struct{}
+	wg   sync.WaitGroup
+}
+
+// New returns a new, empty GSLB.
+func New() *GSLB {
+	return &GSLB{
+		pools:    map[string]*pool{},
+		ttl:      defaultTTL,
+		interval: defaultInterval,
+		timeout:  defaultTimeout,
+		fails:    defaultFails,
+	}
+}
+
+const (
+	defaultTTL      = 30
+	defaultInterval = 10 * time.Second
+	defaultTimeout  = 2 * time.Second
+	defaultFails    = 3
+)
+
+// ServeDNS implements the plugin.Handler interface.
+func (g *GSLB) ServeDNS(ctx context.Context, w dns.ResponseWriter, r *dns.Msg) (int, error) {
+	state := request.Request{W: w, Req: r}
+	qname := state.Name()
+
+	zone := plugin.Zones(g.Zones).Matches(qname)
+	if zone == "" {
+		return plugin.NextOrFailure(g.Name(), g.Next, ctx, w, r)
+	}
+	p, ok := g.pools[qname]
+	if !ok || (state.QType() != dns.TypeA && state.QType() != dns.TypeAAAA) {
+		return plugin.NextOrFailure(g.Name(), g.Next, ctx, w, r)
+	}
+	members := p.choose(ctx, geo.ClientIP(state, g.edns0), state.QType(), g.answers)
+	if len(members) == 0 {
+		return plugin.NextOrFailure(g.Name(), g.Next, ctx, w, r)
+	}
+
+	m := new(dns.Msg)
+	m.SetReply(r)
+	m.Authoritative = true
+	for _, mb := range members {
+		hdr := dns.RR_Header{Name: state.QName(), Rrtype: state.QType(), Class: dns.ClassINET, Ttl: g.ttl}
+		if state.QType() == dns.TypeA {
+			m.Answer = append(m.Answer, &dns.A{Hdr: hdr, A: mb.addr})
+			continue
+		}
+		m.Answer = append(m.Answer, &dns.AAAA{Hdr: hdr, AAAA: mb.addr})
+	}
+
+	w.WriteMsg(m)
+	return dns.RcodeSuccess, nil
+}
+
+// Name implements the Handler interface.
+func (g *GSLB) Name() string { return "gslb" }
+
+// start starts checking the members of the pools that have a check.
+func (g *GSLB) start() {
+	g.stop = make(chan struct{})
+	for _, p := range g.pools {
+		if p.check == nil {
+			continue
+		}
+		for _, m := range p.members {
+			g.wg.Add(1)
+			go func(p *pool, m *member) {
+				defer g.wg.Done()
+				g.checkLoop(p, m)
+			}(p, m)
+		}
+	}
+}
+
+// close stops checking the members and waits for the running checks to finish.
+func (g *GSLB) close() {
+	if g.stop == nil {
+		return
+	}
+	close(g.stop)
+	g.wg.Wait()
+	g.stop = nil
+}
+
+// checkLoop checks m every interval, until g is closed.
+func (g *GSLB) checkLoop(p *pool, m *member) {
+	ticker := time.NewTicker(g.interval)
+	defer ticker.Stop()
+	for {
+		g.checkMember(p, m)
+		select {
+		case <-g.stop:
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// checkMember checks m once, it's marked unhealthy after fails consecutive failed checks, and
+// healthy again after a single successful check.
+func (g *GSLB) checkMember(p *pool, m *member) {
+	ctx, cancel := context.WithTimeout(context.Background(), g.timeout)
+	err := p.check.Check(ctx, m.addr)
+	cancel()
+
+	addr := m.addr.String()
+	if err != nil {
+		checkFailureCount.WithLabelValues(p.name, addr).Inc()
+		m.fails++
+		if m.fails >= g.fails && m.isHealthy() {
+			log.Warningf("Member %s of pool %s is down: %s", addr, p.name, err)
+			m.setHealthy(false)
+		}
+	} else {
+		m.fails = 0
+		if !m.isHealthy() {
+			log.Infof("Member %s of pool %s is up", addr, p.name)
+			m.setHealthy(true)
+		}
+	}
+
+	healthy := 0.0
+	if m.isHealthy() {
+		healthy = 1
+	}
+	memberHealthy.WithLabelValues(p.name, addr).Set(healthy)
+	poolHealthyMembers.WithLabelValues(p.name).Set(float64(p.healthyCount()))
+}
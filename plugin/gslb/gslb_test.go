@@ -0,0 +1,148 @@
+package gslb
+
+import (
+	"context"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/coredns/caddy"
+	"github.com/coredns/coredns/plugin/pkg/dnstest"
+	"github.com/coredns/coredns/plugin/test"
+
+	"github.com/miekg/dns"
+)
+
+func TestServeDNS(t *testing.T) {
+	c := caddy.NewTestController("dns", `gslb example.org {
+		member www.example.org 192.0.2.1
+		member www.example.org 2001:db8::1
+		ttl 10
+	}`)
+	g, err := gslbParse(c)
+	if err != nil {
+		t.Fatalf("Failed to parse: %s", err)
+	}
+	g.Next = test.NextHandler(dns.RcodeRefused, nil)
+
+	tests := []struct {
+		qname    string
+		qtype    uint16
+		rcode    int
+		expected string
+	}{
+		{"www.example.org.", dns.TypeA, dns.RcodeSuccess, "192.0.2.1"},
+		{"WWW.example.org.", dns.TypeAAAA, dns.RcodeSuccess, "2001:db8::1"},
+		{"www.example.org.", dns.TypeMX, dns.RcodeRefused, ""},
+		{"mail.example.org.", dns.TypeA, dns.RcodeRefused, ""},
+		{"www.example.net.", dns.TypeA, dns.RcodeRefused, ""},
+	}
+	for i, tc := range tests {
+		m := new(dns.Msg)
+		m.SetQuestion(tc.qname, tc.qtype)
+		rec := dnstest.NewRecorder(&test.ResponseWriter{})
+		rcode, _ := g.ServeDNS(context.TODO(), rec, m)
+		if rcode != tc.rcode {
+			t.Errorf("Test %d: expected rcode %d, got %d", i, tc.rcode, rcode)
+			continue
+		}
+		if tc.expected == "" {
+			continue
+		}
+		if len(rec.Msg.Answer) != 1 {
+			t.Fatalf("Test %d: expected 1 answer, got %v", i, rec.Msg.Answer)
+		}
+		rr := rec.Msg.Answer[0]
+		var x string
+		switch rr := rr.(type) {
+		case *dns.A:
+			x = rr.A.String()
+		case *dns.AAAA:
+			x = rr.AAAA.String()
+		}
+		if x != tc.expected {
+			t.Errorf("Test %d: expected %s, got %s", i, tc.expected, x)
+		}
+		if rr.Header().Name != tc.qname || rr.Header().Ttl != 10 {
+			t.Errorf("Test %d: expected %s with TTL 10, got %s", i, tc.qname, rr)
+		}
+		if !rec.Msg.Authoritative {
+			t.Errorf("Test %d: expected authoritative answer", i)
+		}
+	}
+}
+
+func TestCheckMember(t *testing.T) {
+	var fail int32
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if atomic.LoadInt32(&fail) == 1 {
+			w.WriteHeader(http.StatusInternalServerError)
+		}
+	}))
+	defer ts.Close()
+
+	c := caddy.NewTestController("dns", `gslb example.org {
+		member www.example.org 127.0.0.1
+		check www.example.org http `+ts.URL+`
+		fails 2
+	}`)
+	g, err := gslbParse(c)
+	if err != nil {
+		t.Fatalf("Failed to parse: %s", err)
+	}
+	p := g.pools["www.example.org."]
+	m := p.members[0]
+
+	g.checkMember(p, m)
+	if !m.isHealthy() {
+		t.Fatalf("Expected member to be healthy")
+	}
+	atomic.StoreInt32(&fail, 1)
+	g.checkMember(p, m)
+	if !m.isHealthy() {
+		t.Errorf("Expected member to be healthy after a single failure")
+	}
+	g.checkMember(p, m)
+	if m.isHealthy() {
+		t.Errorf("Expected member to be unhealthy after two failures")
+	}
+	atomic.StoreInt32(&fail, 0)
+	g.checkMember(p, m)
+	if !m.isHealthy() {
+		t.Errorf("Expected member to be healthy after a successful check")
+	}
+}
+
+func TestStartClose(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	_, port, _ := net.SplitHostPort(ts.Listener.Addr().String())
+
+	c := caddy.NewTestController("dns", `gslb example.org {
+		member www.example.org 127.0.0.1
+		check www.example.org tcp `+port+`
+		interval 10ms
+		timeout 10ms
+		fails 1
+	}`)
+	g, err := gslbParse(c)
+	if err != nil {
+		t.Fatalf("Failed to parse: %s", err)
+	}
+	m := g.pools["www.example.org."].members[0]
+
+	g.start()
+	defer g.close()
+
+	time.Sleep(50 * time.Millisecond)
+	if !m.isHealthy() {
+		t.Errorf("Expected member to be healthy")
+	}
+	ts.Close()
+	time.Sleep(50 * time.Millisecond)
+	if m.isHealthy() {
+		t.Errorf("Expected member to be unhealthy after the server is gone")
+	}
+}
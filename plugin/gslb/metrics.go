@@ -0,0 +1,32 @@
+package gslb
+
+import (
+	"github.com/coredns/coredns/plugin"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+)
+
+var (
+	// memberHealthy is the health of each pool member.
+	memberHealthy = promauto.NewGaugeVec(prometheus.GaugeOpts{
+		Namespace: plugin.Namespace,
+		Subsystem: "gslb",
+		Name:      "member_healthy",
+		Help:      "Gauge of the health of a pool member, 1 if healthy, 0 if not.",
+	}, []string{"pool", "address"})
+	// poolHealthyMembers is the number of healthy members of each pool.
+	poolHealthyMembers = promauto.NewGaugeVec(prometheus.GaugeOpts{
+		Namespace: plugin.Namespace,
+		Subsystem: "gslb",
+		Name:      "pool_healthy_members",
+		Help:      "Gauge of the number of healthy members of a pool.",
+	}, []string{"pool"})
+	// checkFailureCount is the number of failed health checks of each pool member.
+	checkFailureCount = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: plugin.Namespace,
+		Subsystem: "gslb",
+		Name:      "check_failures_total",
+		Help:      "Counter of failed health checks of a pool member.",
+	}, []string{"pool", "address"})
+)
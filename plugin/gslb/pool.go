@@ -0,0 +1,156 @@
+package gslb
+
+import (
+	"context"
+	"math/rand"
+	"net"
+	"sync/atomic"
+
+	"github.com/coredns/coredns/plugin/pkg/geo"
+
+	"github.com/miekg/dns"
+)
+
+// pool is the set of endpoints a name resolves to.
+type pool struct {
+	name    string
+	members []*member
+	check   checker // nil when the members aren't checked
+}
+
+// member is an endpoint of a pool.
+type member struct {
+	addr     net.IP
+	priority int
+	weight   int
+	sel      *geo.Selector // nil when the member is for all clients
+
+	healthy int32 // 1 when healthy, accessed atomically
+	fails   int   // consecutive failed checks, only used by the checking goroutine
+}
+
+func (m *member) isHealthy() bool { return atomic.LoadInt32(&m.healthy) == 1 }
+
+func (m *member) setHealthy(h bool) {
+	if h {
+		atomic.StoreInt32(&m.healthy, 1)
+		return
+	}
+	atomic.StoreInt32(&m.healthy, 0)
+}
+
+// healthyCount returns the number of healthy members of p.
+func (p *pool) healthyCount() int {
+	n := 0
+	for _, m := range p.members {
+		if m.isHealthy() {
+			n++
+		}
+	}
+	return n
+}
+
+// choose returns the members of p with an address of the family of qtype that should be given
+// to a client with address ip. Unhealthy members are left out, unless there are no healthy ones.
+// Of the remaining members those with the best matching geo selector are kept, and of those
+// the members with the lowest priority, ordered by a weighted shuffle. At most n members are
+// returned, all of them if n is 0.
+func (p *pool) choose(ctx context.Context, ip net.IP, qtype uint16, n int) []*member {
+	family := []*member{}
+	for _, m := range p.members {
+		if (m.addr.To4() != nil) == (qtype == dns.TypeA) {
+			family = append(family, m)
+		}
+	}
+
+	candidates := []*member{}
+	for _, m := range family {
+		if m.isHealthy() {
+			candidates = append(candidates, m)
+		}
+	}
+	// Fail open, it's better to return a dead member than nothing at all.
+	if len(candidates) == 0 {
+		candidates = family
+	}
+
+	candidates = bestGeo(ctx, ip, candidates)
+	candidates = lowestPriority(candidates)
+	candidates = shuffle(candidates)
+
+	if n > 0 && len(candidates) > n {
+		candidates = candidates[:n]
+	}
+	return candidates
+}
+
+// bestGeo returns the members of which the geo selector matches the client best. A member
+// without a selector matches as well as a default selector. If none match, all are returned.
+func bestGeo(ctx context.Context, ip net.IP, members []*member) []*member {
+	best := -1
+	scores := make([]int, len(members))
+	for i, m := range members {
+		if m.sel != nil {
+			scores[i] = m.sel.Match(ctx, ip)
+		}
+		if scores[i] > best {
+			best = scores[i]
+		}
+	}
+	if best == -1 {
+		return members
+	}
+	matched := []*member{}
+	for i, m := range members {
+		if scores[i] == best {
+			matched = append(matched, m)
+		}
+	}
+	return matched
+}
+
+// lowestPriority returns the members with the lowest priority.
+func lowestPriority(members []*member) []*member {
+	if len(members) == 0 {
+		return members
+	}
+	lowest := members[0].priority
+	for _, m := range members {
+		if m.priority < lowest {
+			lowest = m.priority
+		}
+	}
+	prio := []*member{}
+	for _, m := range members {
+		if m.priority == lowest {
+			prio = append(prio, m)
+		}
+	}
+	return prio
+}
+
+// shuffle returns the members in random order, members with a higher weight are more likely
+// to end up in front.
+func shuffle(members []*member) []*member {
+	left := make([]*member, len(members))
+	copy(left, members)
+	total := 0
+	for _, m := range left {
+		total += m.weight
+	}
+
+	shuffled := make([]*member, 0, len(members))
+	for len(left) > 0 {
+		r := rand.Intn(total)
+		for i, m := range left {
+			if r < m.weight {
+				shuffled = append(shuffled, m)
+				total -= m.weight
+				left = append(left[:i], left[i+1:]...)
+				break
+			}
+			r -= m.weight
+		}
+	}
+	return shuffled
+}
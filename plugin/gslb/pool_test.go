@@ -0,0 +1,99 @@
+package gslb
+
+import (
+	"context"
+	"net"
+	"sort"
+	"strings"
+	"testing"
+
+	"github.com/coredns/caddy"
+	"github.com/coredns/coredns/plugin/metadata"
+
+	"github.com/miekg/dns"
+)
+
+func newTestPool(t *testing.T, members ...string) *pool {
+	input := "gslb example.org {\n"
+	for _, m := range members {
+		input += "member www.example.org " + m + "\n"
+	}
+	input += "}"
+	g, err := gslbParse(caddy.NewTestController("dns", input))
+	if err != nil {
+		t.Fatalf("Failed to parse %q: %s", input, err)
+	}
+	return g.pools["www.example.org."]
+}
+
+func addrs(members []*member) string {
+	s := make([]string, len(members))
+	for i, m := range members {
+		s[i] = m.addr.String()
+	}
+	sort.Strings(s)
+	return strings.Join(s, " ")
+}
+
+func TestChoose(t *testing.T) {
+	tests := []struct {
+		members  []string
+		down     []int
+		qtype    uint16
+		country  string
+		n        int
+		expected string
+	}{
+		{[]string{"192.0.2.1", "192.0.2.2", "2001:db8::1"}, nil, dns.TypeA, "", 0, "192.0.2.1 192.0.2.2"},
+		{[]string{"192.0.2.1", "192.0.2.2", "2001:db8::1"}, nil, dns.TypeAAAA, "", 0, "2001:db8::1"},
+		// Unhealthy members are left out.
+		{[]string{"192.0.2.1", "192.0.2.2"}, []int{0}, dns.TypeA, "", 0, "192.0.2.2"},
+		// Unless all are down.
+		{[]string{"192.0.2.1", "192.0.2.2"}, []int{0, 1}, dns.TypeA, "", 0, "192.0.2.1 192.0.2.2"},
+		// Failover to the next priority.
+		{[]string{"192.0.2.1", "192.0.2.2 priority 1", "192.0.2.3 priority 2"}, nil, dns.TypeA, "", 0, "192.0.2.1"},
+		{[]string{"192.0.2.1", "192.0.2.2 priority 1", "192.0.2.3 priority 2"}, []int{0}, dns.TypeA, "", 0, "192.0.2.2"},
+		{[]string{"192.0.2.1", "192.0.2.2 priority 1", "192.0.2.3 priority 2"}, []int{0, 1}, dns.TypeA, "", 0, "192.0.2.3"},
+		// Geo selection comes before priority.
+		{[]string{"192.0.2.1", "192.0.2.2 geo country:NL", "192.0.2.3 geo country:NL priority 1"}, nil, dns.TypeA, "NL", 0, "192.0.2.2"},
+		{[]string{"192.0.2.1", "192.0.2.2 geo country:NL", "192.0.2.3 geo country:NL priority 1"}, []int{1}, dns.TypeA, "NL", 0, "192.0.2.3"},
+		{[]string{"192.0.2.1", "192.0.2.2 geo country:NL", "192.0.2.3 geo country:NL priority 1"}, []int{1, 2}, dns.TypeA, "NL", 0, "192.0.2.1"},
+		{[]string{"192.0.2.1", "192.0.2.2 geo country:NL"}, nil, dns.TypeA, "BE", 0, "192.0.2.1"},
+		// No member matches, all are used.
+		{[]string{"192.0.2.1 geo country:NL", "192.0.2.2 geo country:BE"}, nil, dns.TypeA, "DE", 0, "192.0.2.1 192.0.2.2"},
+		// Limited number of answers.
+		{[]string{"192.0.2.1 weight 1000", "192.0.2.2"}, nil, dns.TypeA, "", 1, "192.0.2.1"},
+	}
+
+	for i, tc := range tests {
+		p := newTestPool(t, tc.members...)
+		for _, d := range tc.down {
+			p.members[d].setHealthy(false)
+		}
+		ctx := metadata.ContextWithMetadata(context.Background())
+		country := tc.country
+		metadata.SetValueFunc(ctx, "geoip/country/code", func() string { return country })
+
+		members := p.choose(ctx, net.ParseIP("10.0.0.1"), tc.qtype, tc.n)
+		if x := addrs(members); tc.n == 0 && x != tc.expected {
+			t.Errorf("Test %d: expected %q, got %q", i, tc.expected, x)
+		}
+		if tc.n > 0 && len(members) != tc.n {
+			t.Errorf("Test %d: expected %d members, got %d", i, tc.n, len(members))
+		}
+	}
+}
+
+func TestShuffleWeight(t *testing.T) {
+	p := newTestPool(t, "192.0.2.1 weight 9", "192.0.2.2")
+	first := 0
+	for i := 0; i < 1000; i++ {
+		if shuffle(p.members)[0].addr.String() == "192.0.2.1" {
+			first++
+		}
+	}
+	// Expect about 900, be very lenient.
+	if first < 700 || first == 1000 {
+		t.Errorf("Expected the heavier member first about 90%% of the time, got %d out of 1000", first)
+	}
+}
@@ -0,0 +1,203 @@
+package gslb
+
+import (
+	"fmt"
+	"net"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/coredns/caddy"
+	"github.com/coredns/coredns/core/dnsserver"
+	"github.com/coredns/coredns/plugin"
+	"github.com/coredns/coredns/plugin/pkg/geo"
+	clog "github.com/coredns/coredns/plugin/pkg/log"
+
+	"github.com/miekg/dns"
+)
+
+var log = clog.NewWithPlugin("gslb")
+
+func init() { plugin.Register("gslb", setup) }
+
+func setup(c *caddy.Controller) error {
+	g, err := gslbParse(c)
+	if err != nil {
+		return plugin.Error("gslb", err)
+	}
+
+	c.OnStartup(func() error {
+		g.start()
+		return nil
+	})
+	c.OnShutdown(func() error {
+		g.close()
+		return nil
+	})
+
+	dnsserver.GetConfig(c).AddPlugin(func(next plugin.Handler) plugin.Handler {
+		g.Next = next
+		return g
+	})
+
+	return nil
+}
+
+func gslbParse(c *caddy.Controller) (*GSLB, error) {
+	g := New()
+
+	i := 0
+	for c.Next() {
+		if i > 0 {
+			return nil, plugin.ErrOnce
+		}
+		i++
+
+		g.Zones = plugin.OriginsFromArgsOrServerBlock(c.RemainingArgs(), c.ServerBlockKeys)
+
+		for c.NextBlock() {
+			switch c.Val() {
+			case "member":
+				args := c.RemainingArgs()
+				if len(args) < 2 {
+					return nil, c.ArgErr()
+				}
+				p, err := g.pool(args[0])
+				if err != nil {
+					return nil, c.Err(err.Error())
+				}
+				m, err := parseMember(c, args[1:])
+				if err != nil {
+					return nil, err
+				}
+				p.members = append(p.members, m)
+			case "check":
+				args := c.RemainingArgs()
+				if len(args) < 2 {
+					return nil, c.ArgErr()
+				}
+				p, err := g.pool(args[0])
+				if err != nil {
+					return nil, c.Err(err.Error())
+				}
+				if p.check != nil {
+					return nil, c.Errf("pool %s already has a check", p.name)
+				}
+				if p.check, err = newChecker(args[1], args[2:]); err != nil {
+					return nil, c.Err(err.Error())
+				}
+			case "interval", "timeout":
+				prop := c.Val()
+				args := c.RemainingArgs()
+				if len(args) != 1 {
+					return nil, c.ArgErr()
+				}
+				d, err := time.ParseDuration(args[0])
+				if err != nil {
+					return nil, c.Errf("invalid duration for %s '%s'", prop, args[0])
+				}
+				if d <= 0 {
+					return nil, c.Errf("%s must be positive, got '%s'", prop, args[0])
+				}
+				if prop == "interval" {
+					g.interval = d
+				} else {
+					g.timeout = d
+				}
+			case "fails", "answers", "ttl":
+				prop := c.Val()
+				args := c.RemainingArgs()
+				if len(args) != 1 {
+					return nil, c.ArgErr()
+				}
+				n, err := strconv.Atoi(args[0])
+				if err != nil || n < 0 {
+					return nil, c.Errf("invalid number for %s '%s'", prop, args[0])
+				}
+				switch prop {
+				case "fails":
+					if n == 0 {
+						return nil, c.Errf("fails must be at least 1")
+					}
+					g.fails = n
+				case "answers":
+					g.answers = n
+				case "ttl":
+					if n > 3600 {
+						return nil, c.Errf("ttl must be at most 3600, got '%s'", args[0])
+					}
+					g.ttl = uint32(n)
+				}
+			case "edns-subnet":
+				if c.NextArg() {
+					return nil, c.ArgErr()
+				}
+				g.edns0 = true
+			default:
+				return nil, c.Errf("unknown property '%s'", c.Val())
+			}
+		}
+	}
+
+	for _, p := range g.pools {
+		if len(p.members) == 0 {
+			return nil, c.Errf("pool %s has no members", p.name)
+		}
+	}
+	if g.timeout > g.interval {
+		return nil, c.Errf("timeout %s is longer than interval %s", g.timeout, g.interval)
+	}
+	return g, nil
+}
+
+// pool returns the pool for name, it's created if it doesn't exist yet.
+func (g *GSLB) pool(name string) (*pool, error) {
+	name = dns.Fqdn(strings.ToLower(name))
+	if plugin.Zones(g.Zones).Matches(name) == "" {
+		return nil, fmt.Errorf("name %q is not in zones %v", name, g.Zones)
+	}
+	if p, ok := g.pools[name]; ok {
+		return p, nil
+	}
+	p := &pool{name: name}
+	g.pools[name] = p
+	return p, nil
+}
+
+// parseMember parses the address and the options of a member.
+func parseMember(c *caddy.Controller, args []string) (*member, error) {
+	m := &member{addr: net.ParseIP(args[0]), weight: 1, healthy: 1}
+	if m.addr == nil {
+		return nil, c.Errf("invalid address '%s'", args[0])
+	}
+	args = args[1:]
+	for len(args) > 0 {
+		if len(args) < 2 {
+			return nil, c.Errf("option '%s' needs a value", args[0])
+		}
+		switch args[0] {
+		case "priority":
+			n, err := strconv.Atoi(args[1])
+			if err != nil || n < 0 {
+				return nil, c.Errf("invalid priority '%s'", args[1])
+			}
+			m.priority = n
+		case "weight":
+			n, err := strconv.Atoi(args[1])
+			if err != nil || n < 1 {
+				return nil, c.Errf("invalid weight '%s'", args[1])
+			}
+			m.weight = n
+		case "geo":
+			sel, err := geo.ParseSelector(args[1])
+			if err != nil {
+				return nil, c.Err(err.Error())
+			}
+			m.sel = sel
+		default:
+			return nil, c.Errf("unknown member option '%s'", args[0])
+		}
+		args = args[2:]
+	}
+	return m, nil
+}
@@ -0,0 +1,124 @@
+package gslb
+
+import (
+	"testing"
+
+	"github.com/coredns/caddy"
+)
+
+func TestSetup(t *testing.T) {
+	tests := []struct {
+		input     string
+		shouldErr bool
+		pools     int
+	}{
+		{`gslb example.org`, false, 0},
+		{`gslb example.org {
+			member www.example.org 192.0.2.1
+			member www.example.org 192.0.2.2 priority 1 weight 3
+			member www.example.org 2001:db8::1 geo country:NL,BE
+			check www.example.org http http://www.example.org/health
+			member api.example.org. 192.0.2.10
+			check api.example.org tcp 443
+			member ns.example.org 192.0.2.53
+			check ns.example.org dns 53 example.org
+			interval 5s
+			timeout 1s
+			fails 2
+			answers 1
+			ttl 10
+			edns-subnet
+		}`, false, 3},
+		{`gslb example.org {
+			member www.example.net 192.0.2.1
+		}`, true, 0},
+		{`gslb example.org {
+			member www.example.org 192.0.2
+		}`, true, 0},
+		{`gslb example.org {
+			member www.example.org 192.0.2.1 weight 0
+		}`, true, 0},
+		{`gslb example.org {
+			member www.example.org 192.0.2.1 priority
+		}`, true, 0},
+		{`gslb example.org {
+			member www.example.org 192.0.2.1 color blue
+		}`, true, 0},
+		{`gslb example.org {
+			member www.example.org 192.0.2.1 geo planet:earth
+		}`, true, 0},
+		{`gslb example.org {
+			check www.example.org tcp 80
+		}`, true, 0},
+		{`gslb example.org {
+			member www.example.org 192.0.2.1
+			check www.example.org tcp 80
+			check www.example.org tcp 443
+		}`, true, 0},
+		{`gslb example.org {
+			member www.example.org 192.0.2.1
+			check www.example.org icmp
+		}`, true, 0},
+		{`gslb example.org {
+			member www.example.org 192.0.2.1
+			check www.example.org tcp 0
+		}`, true, 0},
+		{`gslb example.org {
+			member www.example.org 192.0.2.1
+			check www.example.org https http://www.example.org
+		}`, true, 0},
+		{`gslb example.org {
+			interval 1s
+			timeout 2s
+		}`, true, 0},
+		{`gslb example.org {
+			fails 0
+		}`, true, 0},
+		{`gslb example.org {
+			ttl 3601
+		}`, true, 0},
+		{`gslb example.org {
+			bogus
+		}`, true, 0},
+		{`gslb example.org
+		gslb example.net`, true, 0},
+	}
+
+	for i, tc := range tests {
+		c := caddy.NewTestController("dns", tc.input)
+		g, err := gslbParse(c)
+		if tc.shouldErr {
+			if err == nil {
+				t.Errorf("Test %d: expected error, got none", i)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("Test %d: expected no error, got %s", i, err)
+			continue
+		}
+		if len(g.pools) != tc.pools {
+			t.Errorf("Test %d: expected %d pools, got %d", i, tc.pools, len(g.pools))
+		}
+	}
+}
+
+func TestSetupDefaults(t *testing.T) {
+	c := caddy.NewTestController("dns", `gslb example.org {
+		member www.example.org 192.0.2.1
+	}`)
+	g, err := gslbParse(c)
+	if err != nil {
+		t.Fatalf("Expected no error, got %s", err)
+	}
+	if g.ttl != defaultTTL || g.interval != defaultInterval || g.timeout != defaultTimeout || g.fails != defaultFails {
+		t.Errorf("Expected defaults, got ttl %d, interval %s, timeout %s, fails %d", g.ttl, g.interval, g.timeout, g.fails)
+	}
+	p := g.pools["www.example.org."]
+	if p == nil || len(p.members) != 1 {
+		t.Fatalf("Expected pool with 1 member, got %v", p)
+	}
+	if m := p.members[0]; m.weight != 1 || m.priority != 0 || !m.isHealthy() {
+		t.Errorf("Expected healthy member with weight 1 and priority 0, got %+v", m)
+	}
+}
@@ -3,6 +3,7 @@ package hosts
 import (
 	"context"
 	"net"
+	"time"
 
 	"github.com/coredns/coredns/plugin"
 	"github.com/coredns/coredns/plugin/pkg/dnsutil"
@@ -85,6 +86,21 @@ func (h Hosts) otherRecordsExist(qname string) bool {
 // Name implements the plugin.Handle interface.
 func (h Hosts) Name() string { return "hosts" }
 
+// Reload re-reads the hosts file right away, even if it has not been modified. If zone is not empty, the
+// file is only read when zone is one of the hosts' origins. It returns the origins that were reloaded.
+func (h Hosts) Reload(zone string) ([]string, error) {
+	if zone != "" && plugin.Zones(h.Origins).Matches(plugin.Name(zone).Normalize()) == "" {
+		return nil, nil
+	}
+	h.Lock()
+	h.mtime = time.Time{}
+	h.size = 0
+	h.Unlock()
+
+	h.readHosts()
+	return h.Origins, nil
+}
+
 // a takes a slice of net.IPs and returns a slice of A RRs.
 func a(zone string, ttl uint32, ips []net.IP) []dns.RR {
 	answers := make([]dns.RR, len(ips))
@@ -100,7 +100,7 @@ type Hostsfile struct {
 	// path to the hosts file
 	path string
 
-	// mtime and size are only read and modified by a single goroutine
+	// mtime and size are protected by the mutex, as they are also reset by Reload
 	mtime time.Time
 	size  int64
 
@@ -122,9 +122,10 @@ func (h *Hostsfile) readHosts() {
 	}
 	h.RLock()
 	size := h.size
+	mtime := h.mtime
 	h.RUnlock()
 
-	if h.mtime.Equal(stat.ModTime()) && size == stat.Size() {
+	if mtime.Equal(stat.ModTime()) && size == stat.Size() {
 		return
 	}
 
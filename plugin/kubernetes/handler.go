@@ -2,6 +2,7 @@ package kubernetes
 
 import (
 	"context"
+	"time"
 
 	"github.com/coredns/coredns/plugin"
 	"github.com/coredns/coredns/request"
@@ -12,6 +13,7 @@ import (
 // ServeDNS implements the plugin.Handler interface.
 func (k Kubernetes) ServeDNS(ctx context.Context, w dns.ResponseWriter, r *dns.Msg) (int, error) {
 	state := request.Request{W: w, Req: r}
+	start := time.Now()
 
 	qname := state.QName()
 	zone := plugin.Zones(k.Zones).Matches(qname)
@@ -21,6 +23,10 @@ func (k Kubernetes) ServeDNS(ctx context.Context, w dns.ResponseWriter, r *dns.M
 	zone = qname[len(qname)-len(zone):] // maintain case of original query
 	state.Zone = zone
 
+	// Negative answers are written by plugin.BackendError, have those tapped too.
+	authState := state
+	authState.W = k.tap.AuthWriter(ctx, state, start)
+
 	var (
 		records   []dns.RR
 		extra     []dns.RR
@@ -68,16 +74,16 @@ func (k Kubernetes) ServeDNS(ctx context.Context, w dns.ResponseWriter, r *dns.M
 		}
 		if !k.APIConn.HasSynced() {
 			// If we haven't synchronized with the kubernetes cluster, return server failure
-			return plugin.BackendError(ctx, &k, zone, dns.RcodeServerFailure, state, nil /* err */, plugin.Options{})
+			return plugin.BackendError(ctx, &k, zone, dns.RcodeServerFailure, authState, nil /* err */, plugin.Options{})
 		}
-		return plugin.BackendError(ctx, &k, zone, dns.RcodeNameError, state, nil /* err */, plugin.Options{})
+		return plugin.BackendError(ctx, &k, zone, dns.RcodeNameError, authState, nil /* err */, plugin.Options{})
 	}
 	if err != nil {
 		return dns.RcodeServerFailure, err
 	}
 
 	if len(records) == 0 {
-		return plugin.BackendError(ctx, &k, zone, dns.RcodeSuccess, state, nil, plugin.Options{})
+		return plugin.BackendError(ctx, &k, zone, dns.RcodeSuccess, authState, nil, plugin.Options{})
 	}
 
 	m := new(dns.Msg)
@@ -86,6 +92,7 @@ func (k Kubernetes) ServeDNS(ctx context.Context, w dns.ResponseWriter, r *dns.M
 	m.Authoritative = true
 	m.Answer = append(m.Answer, records...)
 	m.Extra = append(m.Extra, extra...)
+	k.tap.TapAuth(ctx, state, m, start)
 	w.WriteMsg(m)
 	return dns.RcodeSuccess, nil
 }
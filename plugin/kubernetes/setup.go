@@ -10,6 +10,7 @@ import (
 	"github.com/coredns/caddy"
 	"github.com/coredns/coredns/core/dnsserver"
 	"github.com/coredns/coredns/plugin"
+	"github.com/coredns/coredns/plugin/dnstap"
 	"github.com/coredns/coredns/plugin/pkg/dnsutil"
 	clog "github.com/coredns/coredns/plugin/pkg/log"
 	"github.com/coredns/coredns/plugin/pkg/upstream"
@@ -48,6 +49,8 @@ func setup(c *caddy.Controller) error {
 		c.OnShutdown(onShut)
 	}
 
+	k.tap = dnstap.NewTaps(c)
+
 	dnsserver.GetConfig(c).AddPlugin(func(next plugin.Handler) plugin.Handler {
 		k.Next = next
 		return k
@@ -59,6 +62,16 @@ func setup(c *caddy.Controller) error {
 		return nil
 	})
 
+	stop := make(chan struct{})
+	c.OnStartup(func() error {
+		go k.watchChanges(stop)
+		return nil
+	})
+	c.OnShutdown(func() error {
+		close(stop)
+		return nil
+	})
+
 	return nil
 }
 
@@ -0,0 +1,33 @@
+package kubernetes
+
+import "time"
+
+// watchInterval is the interval at which the API objects are checked for changes.
+const watchInterval = time.Second
+
+// Watch implements the watch.Watchable interface.
+func (k *Kubernetes) Watch(f func(name string)) { k.changes.Watch(f) }
+
+// watchChanges signals a change of all zones when the API objects changed, until stop is closed.
+func (k *Kubernetes) watchChanges(stop <-chan struct{}) {
+	tick := time.NewTicker(watchInterval)
+	defer tick.Stop()
+
+	last, prev := k.APIConn.Modified(false), time.Now().Unix()
+	for {
+		select {
+		case <-stop:
+			return
+		case now := <-tick.C:
+			// Modified has a resolution of a second, so a change in the same second as the
+			// previous check may not have been seen by it.
+			m := k.APIConn.Modified(false)
+			if m != last || m >= prev {
+				for _, z := range k.Zones {
+					k.changes.Changed(z)
+				}
+			}
+			last, prev = m, now.Unix()
+		}
+	}
+}
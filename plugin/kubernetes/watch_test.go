@@ -0,0 +1,43 @@
+package kubernetes
+
+import (
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+type APIConnWatchTest struct {
+	APIConnServeTest
+	modified int64
+}
+
+func (a *APIConnWatchTest) Modified(bool) int64 { return atomic.LoadInt64(&a.modified) }
+
+func TestWatchChanges(t *testing.T) {
+	k := New([]string{"cluster.local."})
+	conn := &APIConnWatchTest{}
+	k.APIConn = conn
+
+	changed := make(chan string, 10)
+	k.Watch(func(name string) { changed <- name })
+
+	stop := make(chan struct{})
+	defer close(stop)
+	go k.watchChanges(stop)
+
+	select {
+	case name := <-changed:
+		t.Fatalf("Expected no change to be signalled, got %s", name)
+	case <-time.After(2 * watchInterval):
+	}
+
+	atomic.StoreInt64(&conn.modified, time.Now().Unix())
+	select {
+	case name := <-changed:
+		if name != "cluster.local." {
+			t.Errorf("Expected change of cluster.local., got %s", name)
+		}
+	case <-time.After(3 * watchInterval):
+		t.Fatal("Expected change to be signalled")
+	}
+}
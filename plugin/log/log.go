@@ -44,9 +44,8 @@ func (l Logger) ServeDNS(ctx context.Context, w dns.ResponseWriter, r *dns.Msg)
 			class := response.Classify(tpe)
 			_, ok1 = rule.Class[class]
 		}
-		if ok || ok1 {
-			logstr := l.repl.Replace(ctx, state, rrw, rule.Format)
-			clog.Info(logstr)
+		if (ok || ok1) && (rule.sampler == nil || rule.sampler.Sample(rrw)) {
+			l.log(ctx, rule, state, rrw)
 		}
 
 		return rc, err
@@ -54,6 +53,23 @@ func (l Logger) ServeDNS(ctx context.Context, w dns.ResponseWriter, r *dns.Msg)
 	return plugin.NextOrFailure(l.Name(), l.Next, ctx, w, r)
 }
 
+// log logs the query in state and the response recorded in rrw according to rule.
+func (l Logger) log(ctx context.Context, rule Rule, state request.Request, rrw *dnstest.Recorder) {
+	switch rule.Encoding {
+	case EncodingJSON:
+		rule.out.Log(newRecord(ctx, state, rrw).JSON())
+	case EncodingLogfmt:
+		rule.out.Log(newRecord(ctx, state, rrw).Logfmt())
+	default:
+		logstr := l.repl.Replace(ctx, state, rrw, rule.Format)
+		if rule.out == nil {
+			clog.Info(logstr)
+			return
+		}
+		rule.out.Log([]byte(logstr))
+	}
+}
+
 // Name implements the Handler interface.
 func (l Logger) Name() string { return "log" }
 
@@ -62,6 +78,10 @@ type Rule struct {
 	NameScope string
 	Class     map[response.Class]struct{}
 	Format    string
+	Encoding  Encoding
+
+	out     *output  // where records are written to, if nil text is logged via clog
+	sampler *sampler // if nil all queries are logged
 }
 
 const (
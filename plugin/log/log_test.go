@@ -278,3 +278,52 @@ func BenchmarkLogged(b *testing.B) {
 		logger.ServeDNS(ctx, rec, r)
 	}
 }
+
+func TestLoggedStructured(t *testing.T) {
+	tests := []struct {
+		encoding Encoding
+		expected []string
+	}{
+		{EncodingJSON, []string{`"name":"example.org."`, `"type":"A"`, `"rcode":"NOERROR"`, `"rflags":["qr","aa","rd"]`, `"answer":["example.org.\t300\tIN\tA\t127.0.0.1"]`, `"port":40212`}},
+		{EncodingLogfmt, []string{`name=example.org.`, `type=A`, `rcode=NOERROR`, `rflags=qr,aa,rd`, `answer="example.org.\t300\tIN\tA\t127.0.0.1"`, `port=40212`}},
+	}
+	for i, tc := range tests {
+		m := &memory{}
+		o := newOutput("memory", m, 10)
+		if err := o.Start(); err != nil {
+			t.Fatal(err)
+		}
+		logger := Logger{
+			Rules: []Rule{{
+				NameScope: ".",
+				Class:     map[response.Class]struct{}{response.All: {}},
+				Encoding:  tc.encoding,
+				out:       o,
+			}},
+			Next: test.HandlerFunc(func(ctx context.Context, w dns.ResponseWriter, r *dns.Msg) (int, error) {
+				m := new(dns.Msg)
+				m.SetReply(r)
+				m.Authoritative = true
+				m.Answer = []dns.RR{test.A("example.org. 300 IN A 127.0.0.1")}
+				w.WriteMsg(m)
+				return dns.RcodeSuccess, nil
+			}),
+			repl: replacer.New(),
+		}
+
+		r := new(dns.Msg)
+		r.SetQuestion("example.org.", dns.TypeA)
+		logger.ServeDNS(context.TODO(), dnstest.NewRecorder(&test.ResponseWriter{}), r)
+		o.Stop()
+
+		records := m.Records()
+		if len(records) != 1 {
+			t.Fatalf("Test %d: expected 1 record, got %d", i, len(records))
+		}
+		for _, e := range tc.expected {
+			if !strings.Contains(records[0], e) {
+				t.Errorf("Test %d: expected %s in record %s", i, e, records[0])
+			}
+		}
+	}
+}
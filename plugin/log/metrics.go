@@ -0,0 +1,24 @@
+package log
+
+import (
+	"github.com/coredns/coredns/plugin"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+)
+
+// droppedCount is the counter of log records that were dropped.
+var droppedCount = promauto.NewCounterVec(prometheus.CounterOpts{
+	Namespace: plugin.Namespace,
+	Subsystem: "log",
+	Name:      "dropped_records_total",
+	Help:      "Counter of log records dropped because the output's buffer was full or writing failed.",
+}, []string{"output"})
+
+// skippedCount is the counter of log records skipped by sampling or rate limiting.
+var skippedCount = promauto.NewCounterVec(prometheus.CounterOpts{
+	Namespace: plugin.Namespace,
+	Subsystem: "log",
+	Name:      "skipped_records_total",
+	Help:      "Counter of log records skipped because of sampling or rate limiting.",
+}, []string{"reason"})
@@ -0,0 +1,262 @@
+package log
+
+import (
+	"fmt"
+	"net"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+
+	clog "github.com/coredns/coredns/plugin/pkg/log"
+)
+
+// defaultBuffer is the default number of records an output buffers before dropping them.
+const defaultBuffer = 1024
+
+// sink is where an output writes its records to.
+type sink interface {
+	// Open opens the sink, it is called before the first Write.
+	Open() error
+	// Write writes a single record.
+	Write(b []byte) error
+	Close() error
+}
+
+// output writes log records to a sink from its own goroutine, so that logging never blocks the query
+// path. When the buffer is full records are dropped.
+type output struct {
+	name string // used as the metric label
+	sink sink
+
+	mu     sync.RWMutex
+	ch     chan []byte
+	done   chan struct{}
+	closed bool
+}
+
+func newOutput(name string, s sink, size int) *output {
+	return &output{name: name, sink: s, ch: make(chan []byte, size), done: make(chan struct{})}
+}
+
+// Log queues b to be written, or drops it if the buffer is full.
+func (o *output) Log(b []byte) {
+	o.mu.RLock()
+	defer o.mu.RUnlock()
+	if o.closed {
+		return
+	}
+	select {
+	case o.ch <- b:
+	default:
+		droppedCount.WithLabelValues(o.name).Inc()
+	}
+}
+
+// Start opens the sink and starts writing records. If the sink can't be opened records are dropped.
+func (o *output) Start() error {
+	if err := o.sink.Open(); err != nil {
+		o.mu.Lock()
+		o.closed = true
+		o.mu.Unlock()
+		close(o.done)
+		return err
+	}
+	go func() {
+		defer close(o.done)
+		for b := range o.ch {
+			if err := o.sink.Write(b); err != nil {
+				droppedCount.WithLabelValues(o.name).Inc()
+				clog.Warningf("Failed to write log record to %s: %s", o.name, err)
+			}
+		}
+	}()
+	return nil
+}
+
+// Stop writes the buffered records and closes the sink.
+func (o *output) Stop() error {
+	o.mu.Lock()
+	if o.closed {
+		o.mu.Unlock()
+		return nil
+	}
+	o.closed = true
+	close(o.ch)
+	o.mu.Unlock()
+
+	<-o.done
+	return o.sink.Close()
+}
+
+// stdout writes records to standard output.
+type stdout struct{}
+
+func (stdout) Open() error          { return nil }
+func (stdout) Close() error         { return nil }
+func (stdout) Write(b []byte) error { return writeLine(os.Stdout, b) }
+
+func writeLine(f *os.File, b []byte) error {
+	_, err := f.Write(append(b, '\n'))
+	return err
+}
+
+// file writes records to a file that is rotated when it grows beyond maxSize bytes. At most maxBackups
+// rotated files, path.1 being the most recent, are kept.
+type file struct {
+	path       string
+	maxSize    int64
+	maxBackups int
+
+	sf *sharedFile
+}
+
+// sharedFile is an open log file. File sinks with the same path, e.g. those of the old and the new
+// instance during a reload, share it, so that they don't rotate the file away from each other.
+type sharedFile struct {
+	path string
+	refs int // number of sinks that have it open, guarded by filesMu
+
+	mu   sync.Mutex
+	f    *os.File // nil after opening a rotated file failed, the next write tries again
+	size int64
+}
+
+var (
+	filesMu sync.Mutex
+	files   = map[string]*sharedFile{}
+)
+
+func (fl *file) Open() error {
+	path := filepath.Clean(fl.path)
+
+	filesMu.Lock()
+	defer filesMu.Unlock()
+	sf, ok := files[path]
+	if !ok {
+		sf = &sharedFile{path: path}
+		if err := sf.open(); err != nil {
+			return err
+		}
+		files[path] = sf
+	}
+	sf.refs++
+	fl.sf = sf
+	return nil
+}
+
+func (fl *file) Write(b []byte) error {
+	sf := fl.sf
+	sf.mu.Lock()
+	defer sf.mu.Unlock()
+
+	if sf.f != nil && fl.maxSize > 0 && sf.size > 0 && sf.size+int64(len(b))+1 > fl.maxSize {
+		sf.rotate(fl.maxBackups)
+	}
+	if sf.f == nil {
+		if err := sf.open(); err != nil {
+			return err
+		}
+	}
+	if err := writeLine(sf.f, b); err != nil {
+		return err
+	}
+	sf.size += int64(len(b)) + 1
+	return nil
+}
+
+func (fl *file) Close() error {
+	sf := fl.sf
+	if sf == nil {
+		return nil
+	}
+	fl.sf = nil
+
+	filesMu.Lock()
+	defer filesMu.Unlock()
+	if sf.refs--; sf.refs > 0 {
+		return nil
+	}
+	delete(files, sf.path)
+
+	sf.mu.Lock()
+	defer sf.mu.Unlock()
+	if sf.f == nil {
+		return nil
+	}
+	err := sf.f.Close()
+	sf.f = nil
+	return err
+}
+
+func (sf *sharedFile) open() error {
+	f, err := os.OpenFile(sf.path, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0644)
+	if err != nil {
+		return err
+	}
+	info, err := f.Stat()
+	if err != nil {
+		f.Close()
+		return err
+	}
+	sf.f, sf.size = f, info.Size()
+	return nil
+}
+
+// rotate closes the file and moves path to path.1, path.1 to path.2, etc. The next write opens a new,
+// empty, path.
+func (sf *sharedFile) rotate(maxBackups int) {
+	sf.f.Close()
+	sf.f = nil
+	if maxBackups == 0 {
+		os.Remove(sf.path)
+	} else {
+		os.Remove(fmt.Sprintf("%s.%d", sf.path, maxBackups))
+		for i := maxBackups - 1; i > 0; i-- {
+			os.Rename(fmt.Sprintf("%s.%d", sf.path, i), fmt.Sprintf("%s.%d", sf.path, i+1))
+		}
+		os.Rename(sf.path, sf.path+".1")
+	}
+}
+
+// udp sends every record in its own datagram to addr. When syslog is true the records are prefixed
+// with an RFC 5424 header.
+type udp struct {
+	addr   string
+	syslog bool
+
+	conn     net.Conn
+	hostname string
+}
+
+// syslogPriority is facility local0 (16) with severity informational (6).
+const syslogPriority = 16*8 + 6
+
+func (u *udp) Open() error {
+	conn, err := net.Dial("udp", u.addr)
+	if err != nil {
+		return err
+	}
+	u.conn = conn
+	u.hostname, _ = os.Hostname()
+	if u.hostname == "" {
+		u.hostname = "-"
+	}
+	return nil
+}
+
+func (u *udp) Write(b []byte) error {
+	if u.syslog {
+		header := fmt.Sprintf("<%d>1 %s %s coredns %d - - ", syslogPriority, time.Now().UTC().Format(time.RFC3339Nano), u.hostname, os.Getpid())
+		b = append([]byte(header), b...)
+	}
+	_, err := u.conn.Write(b)
+	return err
+}
+
+func (u *udp) Close() error {
+	if u.conn == nil {
+		return nil
+	}
+	return u.conn.Close()
+}
@@ -0,0 +1,228 @@
+package log
+
+import (
+	"errors"
+	"net"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus/testutil"
+)
+
+// memory is a sink that keeps the records in memory.
+type memory struct {
+	sync.Mutex
+	records []string
+	block   chan struct{} // when not nil, Write blocks until it is closed
+}
+
+func (m *memory) Open() error  { return nil }
+func (m *memory) Close() error { return nil }
+func (m *memory) Write(b []byte) error {
+	if m.block != nil {
+		<-m.block
+	}
+	m.Lock()
+	defer m.Unlock()
+	m.records = append(m.records, string(b))
+	return nil
+}
+
+func (m *memory) Records() []string {
+	m.Lock()
+	defer m.Unlock()
+	return append([]string(nil), m.records...)
+}
+
+func TestOutputDrops(t *testing.T) {
+	m := &memory{block: make(chan struct{})}
+	o := newOutput("memory:drops", m, 2)
+	if err := o.Start(); err != nil {
+		t.Fatal(err)
+	}
+
+	// The first record is taken by the writing goroutine, which then blocks. Two more fill the
+	// buffer, the rest is dropped.
+	o.Log([]byte("1"))
+	time.Sleep(10 * time.Millisecond)
+	for _, b := range []string{"2", "3", "4", "5"} {
+		o.Log([]byte(b))
+	}
+	if dropped := testutil.ToFloat64(droppedCount.WithLabelValues("memory:drops")); dropped != 2 {
+		t.Errorf("Expected 2 dropped records, got %f", dropped)
+	}
+
+	close(m.block)
+	o.Stop()
+	if records := m.Records(); strings.Join(records, ",") != "1,2,3" {
+		t.Errorf("Expected records 1,2,3, got %v", records)
+	}
+
+	// Logging after Stop must not panic.
+	o.Log([]byte("6"))
+}
+
+// failing is a sink that can't be opened.
+type failing struct{ memory }
+
+func (f *failing) Open() error { return errors.New("failed") }
+
+func TestOutputOpenFails(t *testing.T) {
+	f := &failing{}
+	o := newOutput("failing", f, 2)
+	if err := o.Start(); err == nil {
+		t.Fatal("Expected an error from Start")
+	}
+	o.Log([]byte("1"))
+
+	stopped := make(chan struct{})
+	go func() {
+		o.Stop()
+		close(stopped)
+	}()
+	select {
+	case <-stopped:
+	case <-time.After(time.Second):
+		t.Fatal("Stop didn't return after Start failed")
+	}
+	if records := f.Records(); len(records) != 0 {
+		t.Errorf("Expected no records, got %v", records)
+	}
+}
+
+func TestFileRotate(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "query.log")
+	f := &file{path: path, maxSize: 10, maxBackups: 2}
+	if err := f.Open(); err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+
+	for _, b := range []string{"aaaa", "bbbb", "cccc", "dddd", "eeee"} {
+		if err := f.Write([]byte(b)); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	expected := map[string]string{
+		path:        "eeee\n",
+		path + ".1": "cccc\ndddd\n",
+		path + ".2": "aaaa\nbbbb\n",
+	}
+	for p, content := range expected {
+		b, err := os.ReadFile(p)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if string(b) != content {
+			t.Errorf("Expected %q in %s, got %q", content, p, b)
+		}
+	}
+	if _, err := os.Stat(path + ".3"); !os.IsNotExist(err) {
+		t.Errorf("Expected only 2 backups to be kept")
+	}
+}
+
+func TestFileShared(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "query.log")
+	// The previous and the current instance during a reload.
+	prev := &file{path: path, maxSize: 10, maxBackups: 2}
+	cur := &file{path: path, maxSize: 10, maxBackups: 2}
+	if err := prev.Open(); err != nil {
+		t.Fatal(err)
+	}
+	if err := cur.Open(); err != nil {
+		t.Fatal(err)
+	}
+
+	for i, b := range []string{"aaaa", "bbbb", "cccc", "dddd"} {
+		f := prev
+		if i%2 == 1 {
+			f = cur
+		}
+		if err := f.Write([]byte(b)); err != nil {
+			t.Fatal(err)
+		}
+	}
+	prev.Close()
+	if err := cur.Write([]byte("eeee")); err != nil {
+		t.Fatal(err)
+	}
+	cur.Close()
+
+	expected := map[string]string{
+		path:        "eeee\n",
+		path + ".1": "cccc\ndddd\n",
+		path + ".2": "aaaa\nbbbb\n",
+	}
+	for p, content := range expected {
+		b, err := os.ReadFile(p)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if string(b) != content {
+			t.Errorf("Expected %q in %s, got %q", content, p, b)
+		}
+	}
+	if len(files) != 0 {
+		t.Errorf("Expected no open files, got %d", len(files))
+	}
+}
+
+func TestFileReopen(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "query.log")
+	f := &file{path: path, maxSize: 10, maxBackups: 1}
+	if err := f.Open(); err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+
+	// Opening the new file after rotating fails, the write after that opens it.
+	f.Write([]byte("aaaa"))
+	f.sf.rotate(f.maxBackups)
+	if err := os.Mkdir(path, 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := f.Write([]byte("bbbb")); err == nil {
+		t.Fatal("Expected an error writing to the rotated file")
+	}
+	os.Remove(path)
+	if err := f.Write([]byte("cccc")); err != nil {
+		t.Fatal(err)
+	}
+	if b, _ := os.ReadFile(path); string(b) != "cccc\n" {
+		t.Errorf("Expected %q in %s, got %q", "cccc\n", path, b)
+	}
+}
+
+func TestUDPSyslog(t *testing.T) {
+	pc, err := net.ListenPacket("udp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer pc.Close()
+
+	u := &udp{addr: pc.LocalAddr().String(), syslog: true}
+	if err := u.Open(); err != nil {
+		t.Fatal(err)
+	}
+	defer u.Close()
+	if err := u.Write([]byte(`{"name":"example.org."}`)); err != nil {
+		t.Fatal(err)
+	}
+
+	buf := make([]byte, 1024)
+	pc.SetReadDeadline(time.Now().Add(time.Second))
+	n, _, err := pc.ReadFrom(buf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	msg := string(buf[:n])
+	if !strings.HasPrefix(msg, "<134>1 ") || !strings.HasSuffix(msg, ` - - {"name":"example.org."}`) {
+		t.Errorf("Unexpected syslog message %q", msg)
+	}
+}
@@ -0,0 +1,184 @@
+package log
+
+import (
+	"context"
+	"encoding/json"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/coredns/coredns/plugin/metadata"
+	"github.com/coredns/coredns/plugin/pkg/dnstest"
+	"github.com/coredns/coredns/request"
+
+	"github.com/miekg/dns"
+)
+
+// Encoding is the encoding of the records a Rule logs.
+type Encoding int
+
+const (
+	// EncodingText logs the Rule's Format, this is the default.
+	EncodingText Encoding = iota
+	// EncodingJSON logs a JSON object per query.
+	EncodingJSON
+	// EncodingLogfmt logs a line of key=value pairs per query.
+	EncodingLogfmt
+)
+
+// record is a structured log record of a query and its response.
+type record struct {
+	Time     time.Time         `json:"time"`
+	Remote   string            `json:"remote"`
+	Port     int               `json:"port"`
+	Local    string            `json:"local"`
+	Proto    string            `json:"proto"`
+	ID       uint16            `json:"id"`
+	Opcode   string            `json:"opcode"`
+	Name     string            `json:"name"`
+	Type     string            `json:"type"`
+	Class    string            `json:"class"`
+	Size     int               `json:"size"`
+	Do       bool              `json:"do"`
+	Bufsize  int               `json:"bufsize"`
+	Rcode    string            `json:"rcode"`
+	Rflags   []string          `json:"rflags"`
+	Rsize    int               `json:"rsize"`
+	Duration float64           `json:"duration"` // in seconds
+	Answer   []string          `json:"answer,omitempty"`
+	Metadata map[string]string `json:"metadata,omitempty"`
+}
+
+// newRecord returns the record for the query in state and the response recorded in rr.
+func newRecord(ctx context.Context, state request.Request, rr *dnstest.Recorder) record {
+	port, _ := strconv.Atoi(state.Port())
+	r := record{
+		Time:     rr.Start.UTC(),
+		Remote:   state.IP(),
+		Port:     port,
+		Local:    state.LocalIP(),
+		Proto:    state.Proto(),
+		ID:       state.Req.Id,
+		Opcode:   dns.OpcodeToString[state.Req.Opcode],
+		Name:     state.Name(),
+		Type:     state.Type(),
+		Class:    state.Class(),
+		Size:     state.Req.Len(),
+		Do:       state.Do(),
+		Bufsize:  state.Size(),
+		Rcode:    "-",
+		Rflags:   []string{},
+		Rsize:    rr.Len,
+		Duration: time.Since(rr.Start).Seconds(),
+	}
+	if rr.Msg != nil {
+		r.Rcode = dns.RcodeToString[rr.Rcode]
+		if r.Rcode == "" {
+			r.Rcode = strconv.Itoa(rr.Rcode)
+		}
+		r.Rflags = flags(rr.Msg.MsgHdr)
+		for _, a := range rr.Msg.Answer {
+			r.Answer = append(r.Answer, a.String())
+		}
+	}
+	for _, label := range metadata.Labels(ctx) {
+		f := metadata.ValueFunc(ctx, label)
+		if f == nil {
+			continue
+		}
+		if r.Metadata == nil {
+			r.Metadata = make(map[string]string)
+		}
+		r.Metadata[label] = f()
+	}
+	return r
+}
+
+// flags returns the names of the header flags that are set in h.
+func flags(h dns.MsgHdr) []string {
+	f := []string{}
+	if h.Response {
+		f = append(f, "qr")
+	}
+	if h.Authoritative {
+		f = append(f, "aa")
+	}
+	if h.Truncated {
+		f = append(f, "tc")
+	}
+	if h.RecursionDesired {
+		f = append(f, "rd")
+	}
+	if h.RecursionAvailable {
+		f = append(f, "ra")
+	}
+	if h.Zero {
+		f = append(f, "z")
+	}
+	if h.AuthenticatedData {
+		f = append(f, "ad")
+	}
+	if h.CheckingDisabled {
+		f = append(f, "cd")
+	}
+	return f
+}
+
+// JSON returns r as a JSON object.
+func (r record) JSON() []byte {
+	b, _ := json.Marshal(r)
+	return b
+}
+
+// Logfmt returns r as a line of key=value pairs. Answers are joined with "; ", metadata labels are
+// used as keys.
+func (r record) Logfmt() []byte {
+	b := make([]byte, 0, 256)
+	b = appendPair(b, "time", r.Time.Format(time.RFC3339Nano))
+	b = appendPair(b, "remote", r.Remote)
+	b = appendPair(b, "port", strconv.Itoa(r.Port))
+	b = appendPair(b, "local", r.Local)
+	b = appendPair(b, "proto", r.Proto)
+	b = appendPair(b, "id", strconv.Itoa(int(r.ID)))
+	b = appendPair(b, "opcode", r.Opcode)
+	b = appendPair(b, "name", r.Name)
+	b = appendPair(b, "type", r.Type)
+	b = appendPair(b, "class", r.Class)
+	b = appendPair(b, "size", strconv.Itoa(r.Size))
+	b = appendPair(b, "do", strconv.FormatBool(r.Do))
+	b = appendPair(b, "bufsize", strconv.Itoa(r.Bufsize))
+	b = appendPair(b, "rcode", r.Rcode)
+	b = appendPair(b, "rflags", strings.Join(r.Rflags, ","))
+	b = appendPair(b, "rsize", strconv.Itoa(r.Rsize))
+	b = appendPair(b, "duration", strconv.FormatFloat(r.Duration, 'f', -1, 64))
+	if len(r.Answer) > 0 {
+		b = appendPair(b, "answer", strings.Join(r.Answer, "; "))
+	}
+	for _, label := range sortedKeys(r.Metadata) {
+		b = appendPair(b, label, r.Metadata[label])
+	}
+	return b
+}
+
+// appendPair appends key=value to b, the value is quoted when needed.
+func appendPair(b []byte, key, value string) []byte {
+	if len(b) > 0 {
+		b = append(b, ' ')
+	}
+	b = append(b, key...)
+	b = append(b, '=')
+	if value == "" || strings.ContainsAny(value, " =\"\t\n\\") {
+		return strconv.AppendQuote(b, value)
+	}
+	return append(b, value...)
+}
+
+func sortedKeys(m map[string]string) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
@@ -0,0 +1,82 @@
+package log
+
+import (
+	"math/rand"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/coredns/coredns/plugin/pkg/dnstest"
+	"github.com/coredns/coredns/plugin/pkg/response"
+)
+
+// sampler decides which of the queries that match a rule are logged.
+type sampler struct {
+	every uint64  // log 1 in every queries, 0 disables this
+	ratio float64 // log queries with this probability, 0 disables this
+	n     uint64  // queries seen, used with every
+
+	limit *bucket // caps the number of records logged per second, nil disables this
+
+	alwaysError bool          // always log errors
+	alwaysSlow  time.Duration // always log queries that took longer than this, 0 disables this
+}
+
+// Sample returns true if the query whose response is recorded in rrw should be logged. Skipped queries
+// are counted in the skippedCount metric.
+func (s *sampler) Sample(rrw *dnstest.Recorder) bool {
+	if s.alwaysSlow > 0 && time.Since(rrw.Start) > s.alwaysSlow {
+		return true
+	}
+	if s.alwaysError {
+		tpe, _ := response.Typify(rrw.Msg, time.Now().UTC())
+		if response.Classify(tpe) == response.Error {
+			return true
+		}
+	}
+
+	if s.every > 1 && atomic.AddUint64(&s.n, 1)%s.every != 1 {
+		skippedCount.WithLabelValues("sampled").Inc()
+		return false
+	}
+	if s.ratio > 0 && rand.Float64() >= s.ratio {
+		skippedCount.WithLabelValues("sampled").Inc()
+		return false
+	}
+	if s.limit != nil && !s.limit.Allow(time.Now()) {
+		skippedCount.WithLabelValues("rate_limited").Inc()
+		return false
+	}
+	return true
+}
+
+// bucket is a token bucket that allows rate events per second, with bursts of up to rate events.
+type bucket struct {
+	rate float64
+
+	mu     sync.Mutex
+	tokens float64
+	last   time.Time
+}
+
+func newBucket(rate int) *bucket {
+	return &bucket{rate: float64(rate), tokens: float64(rate), last: time.Now()}
+}
+
+// Allow returns true if an event may happen at now.
+func (b *bucket) Allow(now time.Time) bool {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if elapsed := now.Sub(b.last).Seconds(); elapsed > 0 {
+		b.tokens += elapsed * b.rate
+		if b.tokens > b.rate {
+			b.tokens = b.rate
+		}
+	}
+	b.last = now
+	if b.tokens < 1 {
+		return false
+	}
+	b.tokens--
+	return true
+}
@@ -0,0 +1,89 @@
+package log
+
+import (
+	"testing"
+	"time"
+
+	"github.com/coredns/coredns/plugin/pkg/dnstest"
+	"github.com/coredns/coredns/plugin/test"
+
+	"github.com/miekg/dns"
+)
+
+func newRecorder(rcode int, took time.Duration) *dnstest.Recorder {
+	rrw := dnstest.NewRecorder(&test.ResponseWriter{})
+	rrw.Start = time.Now().Add(-took)
+	m := new(dns.Msg)
+	m.SetQuestion("example.org.", dns.TypeA)
+	m.Rcode = rcode
+	rrw.WriteMsg(m)
+	return rrw
+}
+
+func TestSampleEvery(t *testing.T) {
+	s := &sampler{every: 3}
+	logged := 0
+	for i := 0; i < 9; i++ {
+		if s.Sample(newRecorder(dns.RcodeSuccess, 0)) {
+			logged++
+		}
+	}
+	if logged != 3 {
+		t.Errorf("Expected 3 logged queries, got %d", logged)
+	}
+}
+
+func TestSampleRatio(t *testing.T) {
+	s := &sampler{ratio: 1}
+	for i := 0; i < 10; i++ {
+		if !s.Sample(newRecorder(dns.RcodeSuccess, 0)) {
+			t.Fatal("Expected all queries to be logged with ratio 1")
+		}
+	}
+	s = &sampler{ratio: 1e-12}
+	for i := 0; i < 10; i++ {
+		if s.Sample(newRecorder(dns.RcodeSuccess, 0)) {
+			t.Fatal("Expected no queries to be logged with a tiny ratio")
+		}
+	}
+}
+
+func TestSampleAlways(t *testing.T) {
+	s := &sampler{every: 1000, n: 1, alwaysError: true, alwaysSlow: 100 * time.Millisecond}
+	if s.Sample(newRecorder(dns.RcodeSuccess, 0)) {
+		t.Error("Expected fast successful query to be skipped")
+	}
+	if !s.Sample(newRecorder(dns.RcodeServerFailure, 0)) {
+		t.Error("Expected SERVFAIL to be logged")
+	}
+	if !s.Sample(newRecorder(dns.RcodeSuccess, time.Second)) {
+		t.Error("Expected slow query to be logged")
+	}
+}
+
+func TestBucket(t *testing.T) {
+	b := newBucket(2)
+	now := b.last
+	if !b.Allow(now) || !b.Allow(now) {
+		t.Fatal("Expected burst of 2 to be allowed")
+	}
+	if b.Allow(now) {
+		t.Fatal("Expected third event to be denied")
+	}
+	if !b.Allow(now.Add(500 * time.Millisecond)) {
+		t.Fatal("Expected event to be allowed after refill")
+	}
+	if b.Allow(now.Add(500 * time.Millisecond)) {
+		t.Fatal("Expected event to be denied before refill")
+	}
+	// Tokens don't accumulate beyond the burst size.
+	later := now.Add(time.Minute)
+	for i := 0; i < 2; i++ {
+		if !b.Allow(later) {
+			t.Fatalf("Expected event %d to be allowed", i)
+		}
+	}
+	if b.Allow(later) {
+		t.Fatal("Expected burst to be capped")
+	}
+}
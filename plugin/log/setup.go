@@ -1,7 +1,11 @@
 package log
 
 import (
+	"net"
+	"path/filepath"
+	"strconv"
 	"strings"
+	"time"
 
 	"github.com/coredns/caddy"
 	"github.com/coredns/coredns/core/dnsserver"
@@ -20,6 +24,19 @@ func setup(c *caddy.Controller) error {
 		return plugin.Error("log", err)
 	}
 
+	seen := map[*output]struct{}{}
+	for _, r := range rules {
+		if r.out == nil {
+			continue
+		}
+		if _, ok := seen[r.out]; ok {
+			continue
+		}
+		seen[r.out] = struct{}{}
+		c.OnStartup(r.out.Start)
+		c.OnShutdown(r.out.Stop)
+	}
+
 	dnsserver.GetConfig(c).AddPlugin(func(next plugin.Handler) plugin.Handler {
 		return Logger{Next: next, Rules: rules, repl: replacer.New()}
 	})
@@ -33,6 +50,7 @@ func logParse(c *caddy.Controller) ([]Rule, error) {
 	for c.Next() {
 		args := c.RemainingArgs()
 		length := len(rules)
+		formatted := len(args) > 1 && strings.Contains(args[len(args)-1], "{")
 
 		switch len(args) {
 		case 0:
@@ -68,8 +86,15 @@ func logParse(c *caddy.Controller) ([]Rule, error) {
 			}
 		}
 
-		// Class refinements in an extra block.
+		// Class refinements and outputs in an extra block.
 		classes := make(map[response.Class]struct{})
+		encoding := EncodingText
+		var (
+			out    sink
+			name   string
+			buffer = defaultBuffer
+			smp    = &sampler{}
+		)
 		for c.NextBlock() {
 			switch c.Val() {
 			// class followed by combinations of all, denial, error and success.
@@ -85,6 +110,87 @@ func logParse(c *caddy.Controller) ([]Rule, error) {
 					}
 					classes[cls] = struct{}{}
 				}
+			case "encoding":
+				if !c.NextArg() {
+					return nil, c.ArgErr()
+				}
+				switch c.Val() {
+				case "text":
+					encoding = EncodingText
+				case "json":
+					encoding = EncodingJSON
+				case "logfmt":
+					encoding = EncodingLogfmt
+				default:
+					return nil, c.Errf("unknown encoding '%s'", c.Val())
+				}
+				if c.NextArg() {
+					return nil, c.ArgErr()
+				}
+			case "output":
+				var err error
+				out, name, err = parseOutput(c)
+				if err != nil {
+					return nil, err
+				}
+			case "buffer":
+				if !c.NextArg() {
+					return nil, c.ArgErr()
+				}
+				n, err := strconv.Atoi(c.Val())
+				if err != nil || n <= 0 {
+					return nil, c.Errf("buffer must be a positive integer: '%s'", c.Val())
+				}
+				buffer = n
+			case "sample":
+				if !c.NextArg() {
+					return nil, c.ArgErr()
+				}
+				if n, err := strconv.ParseUint(c.Val(), 10, 64); err == nil && n > 0 {
+					smp.every = n
+				} else if r, err := strconv.ParseFloat(c.Val(), 64); err == nil && r > 0 && r <= 1 {
+					smp.ratio = r
+				} else {
+					return nil, c.Errf("sample must be a positive integer or a ratio between 0 and 1: '%s'", c.Val())
+				}
+				if c.NextArg() {
+					return nil, c.ArgErr()
+				}
+			case "rate_limit":
+				if !c.NextArg() {
+					return nil, c.ArgErr()
+				}
+				n, err := strconv.Atoi(c.Val())
+				if err != nil || n <= 0 {
+					return nil, c.Errf("rate_limit must be a positive integer: '%s'", c.Val())
+				}
+				smp.limit = newBucket(n)
+				if c.NextArg() {
+					return nil, c.ArgErr()
+				}
+			case "always":
+				args := c.RemainingArgs()
+				if len(args) == 0 {
+					return nil, c.ArgErr()
+				}
+				for i := 0; i < len(args); i++ {
+					switch args[i] {
+					case "error":
+						smp.alwaysError = true
+					case "slow":
+						i++
+						if i == len(args) {
+							return nil, c.ArgErr()
+						}
+						d, err := time.ParseDuration(args[i])
+						if err != nil || d <= 0 {
+							return nil, c.Errf("invalid duration '%s'", args[i])
+						}
+						smp.alwaysSlow = d
+					default:
+						return nil, c.Errf("unknown always condition '%s'", args[i])
+					}
+				}
 			default:
 				return nil, c.ArgErr()
 			}
@@ -92,11 +198,85 @@ func logParse(c *caddy.Controller) ([]Rule, error) {
 		if len(classes) == 0 {
 			classes[response.All] = struct{}{}
 		}
+		if encoding != EncodingText && formatted {
+			return nil, c.Err("a format can only be used with the text encoding")
+		}
+		if encoding != EncodingText && out == nil {
+			out, name = stdout{}, "stdout"
+		}
+		var o *output
+		if out != nil {
+			o = newOutput(name, out, buffer)
+		}
+		if smp.every == 0 && smp.ratio == 0 && smp.limit == nil {
+			smp = nil // log all queries
+		}
 
 		for i := len(rules) - 1; i >= length; i-- {
 			rules[i].Class = classes
+			rules[i].Encoding = encoding
+			rules[i].out = o
+			rules[i].sampler = smp
 		}
 	}
 
 	return rules, nil
 }
+
+// parseOutput parses the arguments of the output option, it returns the sink and its name.
+func parseOutput(c *caddy.Controller) (sink, string, error) {
+	args := c.RemainingArgs()
+	if len(args) == 0 {
+		return nil, "", c.ArgErr()
+	}
+	switch args[0] {
+	case "stdout":
+		if len(args) != 1 {
+			return nil, "", c.ArgErr()
+		}
+		return stdout{}, "stdout", nil
+	case "file":
+		// file PATH [MAX_SIZE [MAX_BACKUPS]]
+		if len(args) < 2 || len(args) > 4 {
+			return nil, "", c.ArgErr()
+		}
+		f := &file{path: args[1], maxSize: defaultMaxSize * megabyte, maxBackups: defaultMaxBackups}
+		if !filepath.IsAbs(f.path) && dnsserver.GetConfig(c).Root != "" {
+			f.path = filepath.Join(dnsserver.GetConfig(c).Root, f.path)
+		}
+		if len(args) > 2 {
+			n, err := strconv.Atoi(args[2])
+			if err != nil || n < 0 {
+				return nil, "", c.Errf("invalid maximum file size '%s'", args[2])
+			}
+			f.maxSize = int64(n) * megabyte
+		}
+		if len(args) > 3 {
+			n, err := strconv.Atoi(args[3])
+			if err != nil || n < 0 {
+				return nil, "", c.Errf("invalid number of backups '%s'", args[3])
+			}
+			f.maxBackups = n
+		}
+		return f, "file:" + f.path, nil
+	case "syslog", "udp":
+		if len(args) != 2 {
+			return nil, "", c.ArgErr()
+		}
+		addr := args[1]
+		if _, _, err := net.SplitHostPort(addr); err != nil {
+			addr = net.JoinHostPort(addr, "514")
+			if args[0] == "udp" {
+				return nil, "", c.Errf("invalid address '%s': %s", args[1], err)
+			}
+		}
+		return &udp{addr: addr, syslog: args[0] == "syslog"}, args[0] + ":" + addr, nil
+	}
+	return nil, "", c.Errf("unknown output '%s'", args[0])
+}
+
+const (
+	megabyte          = 1 << 20
+	defaultMaxSize    = 100 // in megabytes
+	defaultMaxBackups = 5
+)
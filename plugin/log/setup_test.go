@@ -3,6 +3,7 @@ package log
 import (
 	"reflect"
 	"testing"
+	"time"
 
 	"github.com/coredns/caddy"
 	"github.com/coredns/coredns/plugin/pkg/response"
@@ -182,3 +183,165 @@ func TestLogParse(t *testing.T) {
 		}
 	}
 }
+
+func TestLogParseOutput(t *testing.T) {
+	tests := []struct {
+		input     string
+		shouldErr bool
+		encoding  Encoding
+		output    string
+		buffer    int
+	}{
+		{`log`, false, EncodingText, "", 0},
+		{`log {
+			encoding json
+		}`, false, EncodingJSON, "stdout", defaultBuffer},
+		{`log . {
+			encoding logfmt
+			output syslog 127.0.0.1
+			buffer 10
+		}`, false, EncodingLogfmt, "syslog:127.0.0.1:514", 10},
+		{`log . {
+			encoding json
+			output udp 127.0.0.1:5140
+		}`, false, EncodingJSON, "udp:127.0.0.1:5140", defaultBuffer},
+		{`log . {combined} {
+			output file /var/log/coredns.log 10 2
+		}`, false, EncodingText, "file:/var/log/coredns.log", defaultBuffer},
+		{`log . {combined} {
+			encoding json
+		}`, true, 0, "", 0},
+		{`log {
+			encoding xml
+		}`, true, 0, "", 0},
+		{`log {
+			output udp 127.0.0.1
+		}`, true, 0, "", 0},
+		{`log {
+			output file
+		}`, true, 0, "", 0},
+		{`log {
+			output file /var/log/coredns.log ten
+		}`, true, 0, "", 0},
+		{`log {
+			output kafka
+		}`, true, 0, "", 0},
+		{`log {
+			buffer 0
+		}`, true, 0, "", 0},
+	}
+	for i, test := range tests {
+		rules, err := logParse(caddy.NewTestController("dns", test.input))
+		if test.shouldErr {
+			if err == nil {
+				t.Errorf("Test %d: expected error, got none", i)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("Test %d: expected no error, got %v", i, err)
+			continue
+		}
+		r := rules[0]
+		if r.Encoding != test.encoding {
+			t.Errorf("Test %d: expected encoding %d, got %d", i, test.encoding, r.Encoding)
+		}
+		if test.output == "" {
+			if r.out != nil {
+				t.Errorf("Test %d: expected no output, got %s", i, r.out.name)
+			}
+			continue
+		}
+		if r.out == nil {
+			t.Fatalf("Test %d: expected output %s, got none", i, test.output)
+		}
+		if r.out.name != test.output {
+			t.Errorf("Test %d: expected output %s, got %s", i, test.output, r.out.name)
+		}
+		if cap(r.out.ch) != test.buffer {
+			t.Errorf("Test %d: expected buffer %d, got %d", i, test.buffer, cap(r.out.ch))
+		}
+	}
+}
+
+func TestLogParseSample(t *testing.T) {
+	tests := []struct {
+		input       string
+		shouldErr   bool
+		sampled     bool
+		every       uint64
+		ratio       float64
+		limit       float64
+		alwaysError bool
+		alwaysSlow  time.Duration
+	}{
+		{`log`, false, false, 0, 0, 0, false, 0},
+		{`log {
+			sample 10
+		}`, false, true, 10, 0, 0, false, 0},
+		{`log {
+			sample 0.25
+			rate_limit 100
+		}`, false, true, 0, 0.25, 100, false, 0},
+		{`log {
+			rate_limit 5
+			always error slow 100ms
+		}`, false, true, 0, 0, 5, true, 100 * time.Millisecond},
+		{`log {
+			always slow 1s
+		}`, false, false, 0, 0, 0, false, 0},
+		{`log {
+			sample 0
+		}`, true, false, 0, 0, 0, false, 0},
+		{`log {
+			sample 1.5
+		}`, true, false, 0, 0, 0, false, 0},
+		{`log {
+			sample half
+		}`, true, false, 0, 0, 0, false, 0},
+		{`log {
+			rate_limit -1
+		}`, true, false, 0, 0, 0, false, 0},
+		{`log {
+			always
+		}`, true, false, 0, 0, 0, false, 0},
+		{`log {
+			always slow
+		}`, true, false, 0, 0, 0, false, 0},
+		{`log {
+			always denial
+		}`, true, false, 0, 0, 0, false, 0},
+	}
+	for i, test := range tests {
+		rules, err := logParse(caddy.NewTestController("dns", test.input))
+		if test.shouldErr {
+			if err == nil {
+				t.Errorf("Test %d: expected error, got none", i)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("Test %d: expected no error, got %v", i, err)
+			continue
+		}
+		s := rules[0].sampler
+		if !test.sampled {
+			if s != nil {
+				t.Errorf("Test %d: expected no sampler, got %+v", i, s)
+			}
+			continue
+		}
+		if s == nil {
+			t.Fatalf("Test %d: expected sampler, got none", i)
+		}
+		if s.every != test.every || s.ratio != test.ratio {
+			t.Errorf("Test %d: expected sample %d/%f, got %d/%f", i, test.every, test.ratio, s.every, s.ratio)
+		}
+		if test.limit == 0 && s.limit != nil || test.limit != 0 && (s.limit == nil || s.limit.rate != test.limit) {
+			t.Errorf("Test %d: expected rate limit %f, got %+v", i, test.limit, s.limit)
+		}
+		if s.alwaysError != test.alwaysError || s.alwaysSlow != test.alwaysSlow {
+			t.Errorf("Test %d: expected always %t/%s, got %t/%s", i, test.alwaysError, test.alwaysSlow, s.alwaysError, s.alwaysSlow)
+		}
+	}
+}
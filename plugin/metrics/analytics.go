@@ -0,0 +1,168 @@
+package metrics
+
+import (
+	"encoding/json"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/coredns/coredns/plugin"
+	"github.com/coredns/coredns/request"
+
+	"github.com/miekg/dns"
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// The categories of keys analytics keeps a top for.
+const (
+	categoryName     = "name"
+	categoryClient   = "client"
+	categoryNXDomain = "nxdomain"
+)
+
+var categories = []string{categoryName, categoryClient, categoryNXDomain}
+
+const (
+	defaultTopK       = 10
+	defaultTopKWindow = time.Minute
+	// topKSlack is how many more keys than k are tracked, this makes the top k more accurate.
+	topKSlack = 10
+)
+
+// analytics keeps the approximate top k query names, clients and names that resulted in NXDOMAIN seen
+// in the current window. At the end of each window all counts are reset.
+type analytics struct {
+	k      int
+	window time.Duration
+
+	mu    sync.Mutex
+	start time.Time
+	tops  map[string]*topK
+}
+
+func newAnalytics(k int, window time.Duration) *analytics {
+	a := &analytics{k: k, window: window, start: time.Now(), tops: make(map[string]*topK, len(categories))}
+	for _, c := range categories {
+		a.tops[c] = newTopK(k * topKSlack)
+	}
+	return a
+}
+
+// rotate resets the counts when the window has passed. The lock must be held.
+func (a *analytics) rotate(now time.Time) {
+	if now.Sub(a.start) < a.window {
+		return
+	}
+	for _, t := range a.tops {
+		t.Reset()
+	}
+	a.start = now
+}
+
+// Report counts the query in state, which was answered with rcode.
+func (a *analytics) Report(state request.Request, rcode int) {
+	name := state.Name()
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	a.rotate(time.Now())
+	a.tops[categoryName].Add(name)
+	a.tops[categoryClient].Add(state.IP())
+	if rcode == dns.RcodeNameError {
+		a.tops[categoryNXDomain].Add(name)
+	}
+}
+
+// Top returns the top n of each category and the start of the current window.
+func (a *analytics) Top(n int) (map[string][]counter, time.Time) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	a.rotate(time.Now())
+	top := make(map[string][]counter, len(a.tops))
+	for c, t := range a.tops {
+		top[c] = t.Top(n)
+	}
+	return top, a.start
+}
+
+var topRequestsDesc = prometheus.NewDesc(
+	prometheus.BuildFQName(plugin.Namespace, "dns", "top_requests"),
+	"Approximate number of requests of the most frequent query names, clients and NXDOMAIN names in the current window.",
+	[]string{"category", "key"}, nil,
+)
+
+// Describe implements prometheus.Collector.
+func (a *analytics) Describe(ch chan<- *prometheus.Desc) { ch <- topRequestsDesc }
+
+// Collect implements prometheus.Collector. Only the top k of each category are exported, which bounds
+// the number of series.
+func (a *analytics) Collect(ch chan<- prometheus.Metric) {
+	top, _ := a.Top(a.k)
+	for c, counters := range top {
+		for _, cnt := range counters {
+			ch <- prometheus.MustNewConstMetric(topRequestsDesc, prometheus.GaugeValue, float64(cnt.Count), c, cnt.Key)
+		}
+	}
+}
+
+type topResponse struct {
+	Start time.Time            `json:"start"`
+	Top   map[string][]counter `json:"top"`
+}
+
+// ServeHTTP returns the top of each category as JSON. The optional n parameter sets the number of keys
+// per category, k by default, and the optional category parameter selects a single category.
+func (a *analytics) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, http.StatusText(http.StatusMethodNotAllowed), http.StatusMethodNotAllowed)
+		return
+	}
+	n := a.k
+	if s := r.URL.Query().Get("n"); s != "" {
+		var err error
+		if n, err = strconv.Atoi(s); err != nil || n <= 0 {
+			http.Error(w, "invalid n parameter", http.StatusBadRequest)
+			return
+		}
+	}
+	top, start := a.Top(n)
+	if c := strings.ToLower(r.URL.Query().Get("category")); c != "" {
+		if _, ok := top[c]; !ok {
+			http.Error(w, "invalid category parameter", http.StatusBadRequest)
+			return
+		}
+		top = map[string][]counter{c: top[c]}
+	}
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(topResponse{Start: start, Top: top})
+}
+
+// analyticsReg holds the analytics per metrics address, so server blocks sharing an address share
+// the analytics like they share the registry.
+type analyticsReg struct {
+	sync.Mutex
+	a map[string]*analytics
+}
+
+func (r *analyticsReg) getOrSet(addr string, a *analytics) *analytics {
+	r.Lock()
+	defer r.Unlock()
+	if v, ok := r.a[addr]; ok {
+		return v
+	}
+	r.a[addr] = a
+	return a
+}
+
+func (r *analyticsReg) get(addr string) *analytics {
+	r.Lock()
+	defer r.Unlock()
+	return r.a[addr]
+}
+
+func (r *analyticsReg) remove(addr string) {
+	r.Lock()
+	defer r.Unlock()
+	delete(r.a, addr)
+}
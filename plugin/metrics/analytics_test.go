@@ -0,0 +1,102 @@
+package metrics
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/coredns/coredns/plugin/test"
+	"github.com/coredns/coredns/request"
+
+	"github.com/miekg/dns"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+)
+
+func TestAnalytics(t *testing.T) {
+	a := newAnalytics(2, time.Minute)
+
+	queries := []struct {
+		qname string
+		rcode int
+	}{
+		{"example.org.", dns.RcodeSuccess},
+		{"example.org.", dns.RcodeSuccess},
+		{"a.example.net.", dns.RcodeNameError},
+		{"b.example.net.", dns.RcodeNameError},
+		{"b.example.net.", dns.RcodeNameError},
+	}
+	for _, q := range queries {
+		m := new(dns.Msg)
+		m.SetQuestion(q.qname, dns.TypeA)
+		a.Report(request.Request{W: &test.ResponseWriter{}, Req: m}, q.rcode)
+	}
+
+	top, _ := a.Top(2)
+	if n := top[categoryName]; len(n) != 2 || n[0].Key != "b.example.net." || n[1].Key != "example.org." {
+		t.Errorf("Unexpected top names: %+v", n)
+	}
+	if c := top[categoryClient]; len(c) != 1 || c[0].Key != "10.240.0.1" || c[0].Count != 5 {
+		t.Errorf("Unexpected top clients: %+v", c)
+	}
+	if n := top[categoryNXDomain]; len(n) != 2 || n[0].Key != "b.example.net." || n[0].Count != 2 {
+		t.Errorf("Unexpected top NXDOMAIN names: %+v", n)
+	}
+
+	reg := prometheus.NewRegistry()
+	reg.MustRegister(a)
+	if n := testutil.CollectAndCount(a); n != 5 {
+		t.Errorf("Expected 5 series, got %d", n)
+	}
+	expected := `
+# HELP coredns_dns_top_requests Approximate number of requests of the most frequent query names, clients and NXDOMAIN names in the current window.
+# TYPE coredns_dns_top_requests gauge
+coredns_dns_top_requests{category="client",key="10.240.0.1"} 5
+`
+	if err := testutil.CollectAndCompare(filterCategory{a, "client"}, strings.NewReader(expected)); err != nil {
+		t.Error(err)
+	}
+
+	rec := httptest.NewRecorder()
+	a.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/topk?n=1&category=nxdomain", nil))
+	if rec.Code != http.StatusOK {
+		t.Fatalf("Expected status 200, got %d", rec.Code)
+	}
+	resp := topResponse{}
+	if err := json.NewDecoder(rec.Body).Decode(&resp); err != nil {
+		t.Fatal(err)
+	}
+	if len(resp.Top) != 1 || len(resp.Top[categoryNXDomain]) != 1 || resp.Top[categoryNXDomain][0].Key != "b.example.net." {
+		t.Errorf("Unexpected response: %+v", resp)
+	}
+
+	for _, q := range []string{"n=0", "category=server"} {
+		rec := httptest.NewRecorder()
+		a.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/topk?"+q, nil))
+		if rec.Code != http.StatusBadRequest {
+			t.Errorf("Expected status 400 for %q, got %d", q, rec.Code)
+		}
+	}
+
+	// When the window has passed the counts are reset.
+	a.start = time.Now().Add(-2 * time.Minute)
+	if top, _ := a.Top(2); len(top[categoryName]) != 0 {
+		t.Errorf("Expected no names after the window passed, got %+v", top[categoryName])
+	}
+}
+
+// filterCategory only collects the metrics of one category.
+type filterCategory struct {
+	*analytics
+	category string
+}
+
+func (f filterCategory) Collect(ch chan<- prometheus.Metric) {
+	top, _ := f.Top(f.k)
+	for _, c := range top[f.category] {
+		ch <- prometheus.MustNewConstMetric(topRequestsDesc, prometheus.GaugeValue, float64(c.Count), f.category, c.Key)
+	}
+}
@@ -35,6 +35,9 @@ func (m *Metrics) ServeDNS(ctx context.Context, w dns.ResponseWriter, r *dns.Msg
 	}
 	plugin := m.authoritativePlugin(rw.Caller)
 	vars.Report(WithServer(ctx), state, zone, WithView(ctx), rcode.ToString(rc), plugin, rw.Len, rw.Start)
+	if m.topk != nil {
+		m.topk.Report(state, rc)
+	}
 
 	return status, err
 }
@@ -34,6 +34,8 @@ type Metrics struct {
 	zoneMu    sync.RWMutex
 
 	plugins map[string]struct{} // all available plugins, used to determine which plugin made the client write
+
+	topk *analytics // if not nil, the top clients and names are tracked
 }
 
 // New returns a new instance of Metrics with the given address.
@@ -96,6 +98,7 @@ func (m *Metrics) OnStartup() error {
 
 	m.mux = http.NewServeMux()
 	m.mux.Handle("/metrics", promhttp.HandlerFor(m.Reg, promhttp.HandlerOpts{}))
+	m.mux.HandleFunc("/topk", m.serveTopK)
 
 	// creating some helper variables to avoid data races on m.srv and m.ln
 	server := &http.Server{Handler: m.mux}
@@ -109,6 +112,16 @@ func (m *Metrics) OnStartup() error {
 	return nil
 }
 
+// serveTopK serves the analytics of the server blocks using this address, if they enabled them.
+func (m *Metrics) serveTopK(w http.ResponseWriter, r *http.Request) {
+	a := analyticsRegistry.get(m.Addr)
+	if a == nil {
+		http.NotFound(w, r)
+		return
+	}
+	a.ServeHTTP(w, r)
+}
+
 // OnRestart stops the listener on reload.
 func (m *Metrics) OnRestart() error {
 	if !m.lnSetup {
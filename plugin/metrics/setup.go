@@ -3,6 +3,8 @@ package metrics
 import (
 	"net"
 	"runtime"
+	"strconv"
+	"time"
 
 	"github.com/coredns/caddy"
 	"github.com/coredns/coredns/core/dnsserver"
@@ -17,6 +19,8 @@ var (
 	log      = clog.NewWithPlugin("prometheus")
 	u        = uniq.New()
 	registry = newReg()
+
+	analyticsRegistry = &analyticsReg{a: make(map[string]*analytics)}
 )
 
 func init() { plugin.Register("prometheus", setup) }
@@ -55,6 +59,18 @@ func setup(c *caddy.Controller) error {
 		return nil
 	})
 
+	if m.topk != nil {
+		m.topk = analyticsRegistry.getOrSet(m.Addr, m.topk)
+		c.OnStartup(func() error { m.MustRegister(m.topk); return nil })
+		c.OnRestartFailed(func() error {
+			m.topk = analyticsRegistry.getOrSet(m.Addr, m.topk)
+			m.MustRegister(m.topk)
+			return nil
+		})
+		// The new instance may track different analytics, or none at all.
+		c.OnRestart(func() error { m.Reg.Unregister(m.topk); analyticsRegistry.remove(m.Addr); return nil })
+	}
+
 	c.OnRestart(m.OnRestart)
 	c.OnRestart(func() error { vars.PluginEnabled.Reset(); return nil })
 	c.OnFinalShutdown(m.OnFinalShutdown)
@@ -97,6 +113,43 @@ func parse(c *caddy.Controller) (*Metrics, error) {
 		default:
 			return met, c.ArgErr()
 		}
+
+		var (
+			k      int
+			window = defaultTopKWindow
+		)
+		for c.NextBlock() {
+			switch c.Val() {
+			case "topk":
+				args := c.RemainingArgs()
+				if len(args) > 1 {
+					return nil, c.ArgErr()
+				}
+				k = defaultTopK
+				if len(args) == 1 {
+					n, err := strconv.Atoi(args[0])
+					if err != nil || n <= 0 {
+						return nil, c.Errf("topk must be a positive integer: '%s'", args[0])
+					}
+					k = n
+				}
+			case "topk_window":
+				args := c.RemainingArgs()
+				if len(args) != 1 {
+					return nil, c.ArgErr()
+				}
+				d, err := time.ParseDuration(args[0])
+				if err != nil || d <= 0 {
+					return nil, c.Errf("invalid topk_window duration: '%s'", args[0])
+				}
+				window = d
+			default:
+				return nil, c.Errf("unknown property '%s'", c.Val())
+			}
+		}
+		if k > 0 {
+			met.topk = newAnalytics(k, window)
+		}
 	}
 	return met, nil
 }
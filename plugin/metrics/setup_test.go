@@ -2,6 +2,7 @@ package metrics
 
 import (
 	"testing"
+	"time"
 
 	"github.com/coredns/caddy"
 )
@@ -40,3 +41,48 @@ func TestPrometheusParse(t *testing.T) {
 		}
 	}
 }
+
+func TestPrometheusParseTopK(t *testing.T) {
+	tests := []struct {
+		input     string
+		shouldErr bool
+		k         int
+		window    time.Duration
+	}{
+		{`prometheus`, false, 0, 0},
+		{"prometheus {\n topk\n}", false, defaultTopK, defaultTopKWindow},
+		{"prometheus localhost:9153 {\n topk 5\n topk_window 10s\n}", false, 5, 10 * time.Second},
+		{"prometheus {\n topk_window 10s\n}", false, 0, 0},
+
+		{"prometheus {\n topk 0\n}", true, 0, 0},
+		{"prometheus {\n topk 5 10\n}", true, 0, 0},
+		{"prometheus {\n topk_window\n}", true, 0, 0},
+		{"prometheus {\n topk_window -1s\n}", true, 0, 0},
+		{"prometheus {\n bottomk\n}", true, 0, 0},
+	}
+	for i, test := range tests {
+		m, err := parse(caddy.NewTestController("dns", test.input))
+		if test.shouldErr {
+			if err == nil {
+				t.Errorf("Test %d: expected error, got none", i)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("Test %d: expected no error, got %s", i, err)
+			continue
+		}
+		if test.k == 0 {
+			if m.topk != nil {
+				t.Errorf("Test %d: expected no analytics, got top %d", i, m.topk.k)
+			}
+			continue
+		}
+		if m.topk == nil {
+			t.Fatalf("Test %d: expected analytics, got none", i)
+		}
+		if m.topk.k != test.k || m.topk.window != test.window {
+			t.Errorf("Test %d: expected top %d per %s, got top %d per %s", i, test.k, test.window, m.topk.k, m.topk.window)
+		}
+	}
+}
@@ -0,0 +1,102 @@
+package metrics
+
+import (
+	"container/heap"
+	"sort"
+)
+
+// topK keeps the approximate top of the most frequent keys using the space-saving algorithm. It tracks
+// at most capacity keys; when a new key is seen and all slots are used, the least frequent key is evicted
+// and the new key takes over its count. The count of a key therefore overestimates its true count by at
+// most its error. topK is not safe for concurrent use.
+type topK struct {
+	capacity int
+	counters counterHeap
+	index    map[string]*counter
+}
+
+// counter is the count of a key in a topK.
+type counter struct {
+	Key   string `json:"key"`
+	Count uint64 `json:"count"`
+	Error uint64 `json:"error"`
+
+	i int // index in the heap
+}
+
+func newTopK(capacity int) *topK {
+	return &topK{capacity: capacity, index: make(map[string]*counter, capacity)}
+}
+
+// Add counts an occurrence of key.
+func (t *topK) Add(key string) {
+	if c, ok := t.index[key]; ok {
+		c.Count++
+		heap.Fix(&t.counters, c.i)
+		return
+	}
+	if len(t.counters) < t.capacity {
+		c := &counter{Key: key, Count: 1}
+		t.index[key] = c
+		heap.Push(&t.counters, c)
+		return
+	}
+	// Replace the least frequent key.
+	c := t.counters[0]
+	delete(t.index, c.Key)
+	c.Key = key
+	c.Error = c.Count
+	c.Count++
+	t.index[key] = c
+	heap.Fix(&t.counters, 0)
+}
+
+// Top returns the n keys with the highest counts, highest first.
+func (t *topK) Top(n int) []counter {
+	top := make([]counter, len(t.counters))
+	for i, c := range t.counters {
+		top[i] = *c
+	}
+	sort.Slice(top, func(i, j int) bool {
+		if top[i].Count == top[j].Count {
+			return top[i].Key < top[j].Key
+		}
+		return top[i].Count > top[j].Count
+	})
+	if len(top) > n {
+		top = top[:n]
+	}
+	return top
+}
+
+// Reset forgets all keys.
+func (t *topK) Reset() {
+	t.counters = t.counters[:0]
+	t.index = make(map[string]*counter, t.capacity)
+}
+
+// counterHeap is a min-heap of counters ordered by count.
+type counterHeap []*counter
+
+func (h counterHeap) Len() int           { return len(h) }
+func (h counterHeap) Less(i, j int) bool { return h[i].Count < h[j].Count }
+func (h counterHeap) Swap(i, j int) {
+	h[i], h[j] = h[j], h[i]
+	h[i].i = i
+	h[j].i = j
+}
+
+func (h *counterHeap) Push(x interface{}) {
+	c := x.(*counter)
+	c.i = len(*h)
+	*h = append(*h, c)
+}
+
+func (h *counterHeap) Pop() interface{} {
+	old := *h
+	n := len(old)
+	c := old[n-1]
+	old[n-1] = nil
+	*h = old[:n-1]
+	return c
+}
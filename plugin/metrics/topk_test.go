@@ -0,0 +1,48 @@
+package metrics
+
+import "testing"
+
+func TestTopK(t *testing.T) {
+	top := newTopK(3)
+	for _, k := range []string{"a", "b", "a", "c", "a", "b"} {
+		top.Add(k)
+	}
+	got := top.Top(2)
+	if len(got) != 2 || got[0].Key != "a" || got[0].Count != 3 || got[1].Key != "b" || got[1].Count != 2 {
+		t.Fatalf("Expected a=3 b=2, got %+v", got)
+	}
+
+	// d evicts c, the least frequent key, and inherits its count as error.
+	top.Add("d")
+	got = top.Top(3)
+	if got[2].Key != "d" || got[2].Count != 2 || got[2].Error != 1 {
+		t.Errorf("Expected d=2 with error 1, got %+v", got[2])
+	}
+	for _, c := range got {
+		if c.Key == "c" {
+			t.Errorf("Expected c to be evicted, got %+v", got)
+		}
+	}
+
+	top.Reset()
+	if got := top.Top(3); len(got) != 0 {
+		t.Errorf("Expected no keys after reset, got %+v", got)
+	}
+}
+
+func TestTopKHeavyHitters(t *testing.T) {
+	top := newTopK(10)
+	// A few heavy hitters among many keys seen once; they must survive in the top.
+	for i := 0; i < 1000; i++ {
+		top.Add(string(rune('A' + i%3)))
+		top.Add(string(rune(0x1000 + i)))
+	}
+	got := top.Top(3)
+	keys := map[string]bool{}
+	for _, c := range got {
+		keys[c.Key] = true
+	}
+	if !keys["A"] || !keys["B"] || !keys["C"] {
+		t.Errorf("Expected A, B and C in the top 3, got %+v", got)
+	}
+}
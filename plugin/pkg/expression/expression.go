@@ -2,11 +2,14 @@ package expression
 
 import (
 	"context"
+	"encoding/hex"
 	"errors"
 	"net"
 
 	"github.com/coredns/coredns/plugin/metadata"
 	"github.com/coredns/coredns/request"
+
+	"github.com/miekg/dns"
 )
 
 // DefaultEnv returns the default set of custom state variables and functions available to for use in expression evaluation.
@@ -30,6 +33,22 @@ func DefaultEnv(ctx context.Context, state *request.Request) map[string]interfac
 			}
 			return f()
 		},
+		"edns0": func(code int) string {
+			opt := state.Req.IsEdns0()
+			if opt == nil {
+				return ""
+			}
+			for _, o := range opt.Option {
+				if int(o.Option()) != code {
+					continue
+				}
+				if l, ok := o.(*dns.EDNS0_LOCAL); ok {
+					return "0x" + hex.EncodeToString(l.Data)
+				}
+				return o.String()
+			}
+			return ""
+		},
 		"type":        state.Type,
 		"name":        state.Name,
 		"class":       state.Class,
@@ -2,10 +2,13 @@ package expression
 
 import (
 	"context"
+	"net"
 	"testing"
 
 	"github.com/coredns/coredns/plugin/metadata"
 	"github.com/coredns/coredns/request"
+
+	"github.com/miekg/dns"
 )
 
 func TestInCidr(t *testing.T) {
@@ -71,3 +74,34 @@ func TestMetadata(t *testing.T) {
 		}
 	}
 }
+
+func TestEdns0(t *testing.T) {
+	m := new(dns.Msg)
+	m.SetQuestion("example.org.", dns.TypeA)
+	m.SetEdns0(4096, false)
+	opt := m.IsEdns0()
+	opt.Option = append(opt.Option,
+		&dns.EDNS0_LOCAL{Code: 0xffee, Data: []byte{0xab, 0xcd}},
+		&dns.EDNS0_SUBNET{Code: dns.EDNS0SUBNET, Family: 1, SourceNetmask: 24, Address: net.ParseIP("10.0.0.0").To4()},
+	)
+	f := DefaultEnv(context.Background(), &request.Request{Req: m})["edns0"].(func(int) string)
+
+	cases := []struct {
+		code     int
+		expected string
+	}{
+		{0xffee, "0xabcd"},
+		{dns.EDNS0SUBNET, "10.0.0.0/24/0"},
+		{dns.EDNS0NSID, ""},
+	}
+	for i, c := range cases {
+		if r := f(c.code); r != c.expected {
+			t.Errorf("Test %d: expected %q, got %q", i, c.expected, r)
+		}
+	}
+
+	noEdns := DefaultEnv(context.Background(), &request.Request{Req: new(dns.Msg)})["edns0"].(func(int) string)
+	if r := noEdns(0xffee); r != "" {
+		t.Errorf("Expected empty value without EDNS0, got %q", r)
+	}
+}
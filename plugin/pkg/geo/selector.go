@@ -0,0 +1,143 @@
+// Package geo implements selectors that match clients by their location.
+package geo
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"strings"
+
+	"github.com/coredns/coredns/plugin/metadata"
+	"github.com/coredns/coredns/request"
+
+	"github.com/miekg/dns"
+)
+
+// The kinds of selectors, from the least to the most specific.
+const (
+	kindDefault = iota
+	kindContinent
+	kindCountry
+	kindRegion
+	kindASN
+	kindNet
+)
+
+var kindNames = []string{"default", "continent", "country", "region", "asn", "net"}
+
+// labels are the geoip metadata labels the kinds are matched against.
+var labels = map[int]string{
+	kindContinent: "geoip/continent/code",
+	kindCountry:   "geoip/country/code",
+	kindRegion:    "geoip/subdivisions/code",
+	kindASN:       "geoip/asn/number",
+}
+
+// Selector selects clients by their continent, country, region, ASN or address. The first four are
+// taken from the metadata of the geoip plugin.
+type Selector struct {
+	kind   int
+	values map[string]struct{}
+	nets   []*net.IPNet
+}
+
+// ParseSelector parses a selector, which is either default, or a kind followed by a colon
+// and a comma separated list of values, e.g. country:NL,BE.
+func ParseSelector(s string) (*Selector, error) {
+	if s == "default" {
+		return &Selector{kind: kindDefault}, nil
+	}
+	name, list, ok := strings.Cut(s, ":")
+	if !ok || list == "" {
+		return nil, fmt.Errorf("invalid selector %q", s)
+	}
+	sel := &Selector{kind: -1, values: map[string]struct{}{}}
+	for i, k := range kindNames {
+		if i != kindDefault && k == strings.ToLower(name) {
+			sel.kind = i
+		}
+	}
+	if sel.kind == -1 {
+		return nil, fmt.Errorf("unknown selector kind %q", name)
+	}
+	for _, v := range strings.Split(list, ",") {
+		if v == "" {
+			return nil, fmt.Errorf("empty value in selector %q", s)
+		}
+		switch sel.kind {
+		case kindNet:
+			if !strings.Contains(v, "/") {
+				if ip := net.ParseIP(v); ip != nil && ip.To4() != nil {
+					v += "/32"
+				} else {
+					v += "/128"
+				}
+			}
+			_, n, err := net.ParseCIDR(v)
+			if err != nil {
+				return nil, fmt.Errorf("invalid network %q in selector %q", v, s)
+			}
+			sel.nets = append(sel.nets, n)
+		case kindASN:
+			v = strings.TrimPrefix(strings.ToUpper(v), "AS")
+			sel.values[v] = struct{}{}
+		default:
+			sel.values[strings.ToUpper(v)] = struct{}{}
+		}
+	}
+	return sel, nil
+}
+
+// Kind returns the kind of s.
+func (s *Selector) Kind() string { return kindNames[s.kind] }
+
+// Match returns the score of the match of s for a client with address ip, a higher score is
+// a more specific match. The score is -1 if s doesn't match. A network beats an ASN, which
+// beats a region, which beats a country, which beats a continent, which beats the default.
+func (s *Selector) Match(ctx context.Context, ip net.IP) int {
+	switch s.kind {
+	case kindDefault:
+		return 0
+	case kindNet:
+		best := -1
+		for _, n := range s.nets {
+			if n.Contains(ip) {
+				ones, _ := n.Mask.Size()
+				if ones > best {
+					best = ones
+				}
+			}
+		}
+		if best == -1 {
+			return -1
+		}
+		// Networks are the most specific, longer prefixes win.
+		return kindNet*256 + best
+	}
+
+	f := metadata.ValueFunc(ctx, labels[s.kind])
+	if f == nil {
+		return -1
+	}
+	for _, v := range strings.Split(f(), ",") {
+		if _, ok := s.values[strings.ToUpper(v)]; ok {
+			return s.kind * 256
+		}
+	}
+	return -1
+}
+
+// ClientIP returns the address of the client, which is the address in the EDNS0 subnet option,
+// if present and edns0 is true.
+func ClientIP(state request.Request, edns0 bool) net.IP {
+	if edns0 {
+		if o := state.Req.IsEdns0(); o != nil {
+			for _, s := range o.Option {
+				if e, ok := s.(*dns.EDNS0_SUBNET); ok {
+					return e.Address
+				}
+			}
+		}
+	}
+	return net.ParseIP(state.IP())
+}
@@ -0,0 +1,88 @@
+package geo
+
+import (
+	"context"
+	"net"
+	"testing"
+
+	"github.com/coredns/coredns/plugin/metadata"
+)
+
+func TestParseSelector(t *testing.T) {
+	tests := []struct {
+		selector  string
+		kind      string
+		shouldErr bool
+	}{
+		{"default", "default", false},
+		{"continent:EU", "continent", false},
+		{"Country:nl,be", "country", false},
+		{"region:US-CA", "region", false},
+		{"asn:AS64512,64513", "asn", false},
+		{"net:10.0.0.0/8,2001:db8::/32,192.0.2.1", "net", false},
+		{"default:EU", "", true},
+		{"planet:earth", "", true},
+		{"country", "", true},
+		{"country:", "", true},
+		{"country:NL,", "", true},
+		{"net:10.0.0.0/33", "", true},
+	}
+	for i, tc := range tests {
+		s, err := ParseSelector(tc.selector)
+		if tc.shouldErr {
+			if err == nil {
+				t.Errorf("Test %d: expected error for %q", i, tc.selector)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("Test %d: expected no error, got %s", i, err)
+			continue
+		}
+		if s.Kind() != tc.kind {
+			t.Errorf("Test %d: expected kind %s, got %s", i, tc.kind, s.Kind())
+		}
+	}
+}
+
+func TestMatch(t *testing.T) {
+	ctx := metadata.ContextWithMetadata(context.Background())
+	metadata.SetValueFunc(ctx, "geoip/country/code", func() string { return "NL" })
+	metadata.SetValueFunc(ctx, "geoip/subdivisions/code", func() string { return "NL-NH,NL-AMS" })
+	metadata.SetValueFunc(ctx, "geoip/asn/number", func() string { return "64512" })
+	ip := net.ParseIP("10.240.0.1")
+
+	tests := []struct {
+		selector string
+		matches  bool
+	}{
+		{"default", true},
+		{"continent:EU", false},
+		{"country:be,nl", true},
+		{"region:NL-AMS", true},
+		{"asn:AS64512", true},
+		{"net:10.0.0.0/8", true},
+		{"net:192.0.2.0/24", false},
+	}
+	prev := -1
+	for i, tc := range tests {
+		s, _ := ParseSelector(tc.selector)
+		score := s.Match(ctx, ip)
+		if (score >= 0) != tc.matches {
+			t.Errorf("Test %d: expected match %t for %q, got score %d", i, tc.matches, tc.selector, score)
+		}
+		// The matching selectors are listed from the least to the most specific.
+		if tc.matches {
+			if score <= prev {
+				t.Errorf("Test %d: expected a score higher than %d for %q, got %d", i, prev, tc.selector, score)
+			}
+			prev = score
+		}
+	}
+
+	a, _ := ParseSelector("net:10.0.0.0/8")
+	b, _ := ParseSelector("net:10.240.0.0/16")
+	if a.Match(ctx, ip) >= b.Match(ctx, ip) {
+		t.Errorf("Expected a longer prefix to score higher")
+	}
+}
@@ -0,0 +1,35 @@
+// Package watch lets plugins signal changes to the data they serve, so that clients watching a name
+// can be sent the new answer.
+package watch
+
+import "sync"
+
+// Watchable is implemented by plugins that signal changes to the data they serve.
+type Watchable interface {
+	// Watch registers f to be called with the name under which data changed. A change to a zone is
+	// signalled with the name of the zone, which covers every name in it. f must not block.
+	Watch(f func(name string))
+}
+
+// Notifier keeps the functions registered with Watch and calls them on a change. The zero value is
+// ready to use.
+type Notifier struct {
+	mu sync.RWMutex
+	fs []func(string)
+}
+
+// Watch implements the Watchable interface.
+func (n *Notifier) Watch(f func(name string)) {
+	n.mu.Lock()
+	n.fs = append(n.fs, f)
+	n.mu.Unlock()
+}
+
+// Changed signals that the data under name changed.
+func (n *Notifier) Changed(name string) {
+	n.mu.RLock()
+	defer n.mu.RUnlock()
+	for _, f := range n.fs {
+		f(name)
+	}
+}
@@ -0,0 +1,17 @@
+package watch
+
+import "testing"
+
+func TestNotifier(t *testing.T) {
+	var n Notifier
+	n.Changed("example.org.") // no watchers
+
+	var got []string
+	n.Watch(func(name string) { got = append(got, "1 "+name) })
+	n.Watch(func(name string) { got = append(got, "2 "+name) })
+	n.Changed("example.org.")
+
+	if len(got) != 2 || got[0] != "1 example.org." || got[1] != "2 example.org." {
+		t.Errorf("Expected both watchers to be called, got %v", got)
+	}
+}
@@ -2,7 +2,6 @@ package ready
 
 import (
 	"sort"
-	"strings"
 	"sync"
 )
 
@@ -11,6 +10,14 @@ type list struct {
 	sync.RWMutex
 	rs    []Readiness
 	names []string
+	ready []bool // set when a plugin has signaled readiness once
+}
+
+// status is the readiness of a single plugin.
+type status struct {
+	Name   string `json:"name"`
+	Ready  bool   `json:"ready"`
+	Reason string `json:"reason,omitempty"`
 }
 
 // Reset resets l
@@ -19,6 +26,7 @@ func (l *list) Reset() {
 	defer l.Unlock()
 	l.rs = nil
 	l.names = nil
+	l.ready = nil
 }
 
 // Append adds a new readiness to l.
@@ -27,30 +35,34 @@ func (l *list) Append(r Readiness, name string) {
 	defer l.Unlock()
 	l.rs = append(l.rs, r)
 	l.names = append(l.names, name)
+	l.ready = append(l.ready, false)
 }
 
-// Ready return true when all plugins ready, if the returned value is false the string
-// contains a comma separated list of plugins that are not ready.
-func (l *list) Ready() (bool, string) {
-	l.RLock()
-	defer l.RUnlock()
+// Status returns true when all plugins are ready, together with the readiness of each plugin sorted by
+// name. Plugins that have been ready once are not queried again, unless continuously is true.
+func (l *list) Status(continuously bool) (bool, []status) {
+	l.Lock()
+	defer l.Unlock()
 	ok := true
-	s := []string{}
+	statuses := make([]status, 0, len(l.rs))
 	for i, r := range l.rs {
-		if r == nil {
+		if l.ready[i] && !continuously {
+			statuses = append(statuses, status{Name: l.names[i], Ready: true})
 			continue
 		}
-		if !r.Ready() {
-			ok = false
-			s = append(s, l.names[i])
-		} else {
-			// if ok, this plugin is ready and will not be queried anymore.
-			l.rs[i] = nil
+		if r.Ready() {
+			// if ok, this plugin is ready and, unless monitored continuously, will not be queried anymore.
+			l.ready[i] = true
+			statuses = append(statuses, status{Name: l.names[i], Ready: true})
+			continue
 		}
+		ok = false
+		st := status{Name: l.names[i]}
+		if rp, isReporter := r.(Reporter); isReporter {
+			st.Reason = rp.NotReady()
+		}
+		statuses = append(statuses, st)
 	}
-	if ok {
-		return true, ""
-	}
-	sort.Strings(s)
-	return false, strings.Join(s, ",")
+	sort.SliceStable(statuses, func(i, j int) bool { return statuses[i].Name < statuses[j].Name })
+	return ok, statuses
 }
@@ -5,3 +5,10 @@ type Readiness interface {
 	// Ready is called by ready to see whether the plugin is ready.
 	Ready() bool
 }
+
+// The Reporter interface can be implemented by plugins that implement Readiness to explain why they are
+// not ready. The explanation is returned in the JSON body of the /ready endpoint.
+type Reporter interface {
+	// NotReady returns a short description of why the plugin is not ready.
+	NotReady() string
+}
@@ -5,9 +5,11 @@
 package ready
 
 import (
+	"encoding/json"
 	"io"
 	"net"
 	"net/http"
+	"strings"
 	"sync"
 
 	clog "github.com/coredns/coredns/plugin/pkg/log"
@@ -23,6 +25,8 @@ var (
 
 type ready struct {
 	Addr string
+	// Continuously makes ready query plugins on every request, instead of only until they are ready once.
+	Continuously bool
 
 	sync.RWMutex
 	ln   net.Listener
@@ -42,22 +46,37 @@ func (rd *ready) onStartup() error {
 	rd.done = true
 	rd.Unlock()
 
-	rd.mux.HandleFunc("/ready", func(w http.ResponseWriter, _ *http.Request) {
+	rd.mux.HandleFunc("/ready", func(w http.ResponseWriter, r *http.Request) {
 		rd.Lock()
 		defer rd.Unlock()
+		asJSON := strings.Contains(r.Header.Get("Accept"), "application/json")
 		if !rd.done {
+			if asJSON {
+				writeJSON(w, http.StatusServiceUnavailable, response{Ready: false, Plugins: []status{}})
+				return
+			}
 			w.WriteHeader(http.StatusServiceUnavailable)
 			io.WriteString(w, "Shutting down")
 			return
 		}
-		ok, todo := plugins.Ready()
+		ok, statuses := plugins.Status(rd.Continuously)
+		code := http.StatusOK
+		if !ok {
+			code = http.StatusServiceUnavailable
+		}
+		todo := notReady(statuses)
+		if !ok {
+			log.Infof("Still waiting on: %q", todo)
+		}
+		if asJSON {
+			writeJSON(w, code, response{Ready: ok, Plugins: statuses})
+			return
+		}
+		w.WriteHeader(code)
 		if ok {
-			w.WriteHeader(http.StatusOK)
 			io.WriteString(w, http.StatusText(http.StatusOK))
 			return
 		}
-		log.Infof("Still waiting on: %q", todo)
-		w.WriteHeader(http.StatusServiceUnavailable)
 		io.WriteString(w, todo)
 	})
 
@@ -79,3 +98,26 @@ func (rd *ready) onFinalShutdown() error {
 	rd.done = false
 	return nil
 }
+
+// response is the JSON body of the /ready endpoint.
+type response struct {
+	Ready   bool     `json:"ready"`
+	Plugins []status `json:"plugins"`
+}
+
+// notReady returns a comma separated list of the plugins that are not ready.
+func notReady(statuses []status) string {
+	s := []string{}
+	for _, st := range statuses {
+		if !st.Ready {
+			s = append(s, st.Name)
+		}
+	}
+	return strings.Join(s, ",")
+}
+
+func writeJSON(w http.ResponseWriter, code int, v interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(code)
+	json.NewEncoder(w).Encode(v)
+}
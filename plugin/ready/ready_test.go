@@ -2,6 +2,7 @@ package ready
 
 import (
 	"context"
+	"encoding/json"
 	"fmt"
 	"net/http"
 	"testing"
@@ -67,3 +68,53 @@ func TestReady(t *testing.T) {
 	}
 	response.Body.Close()
 }
+
+type reporter struct{ reason string }
+
+func (r *reporter) Ready() bool      { return r.reason == "" }
+func (r *reporter) NotReady() string { return r.reason }
+
+func TestReadyContinuouslyJSON(t *testing.T) {
+	plugins.Reset()
+	defer plugins.Reset()
+
+	rd := &ready{Addr: ":0", Continuously: true}
+	rp := &reporter{}
+	plugins.Append(rp, "reporter")
+
+	if err := rd.onStartup(); err != nil {
+		t.Fatalf("Unable to startup the readiness server: %v", err)
+	}
+	defer rd.onFinalShutdown()
+
+	address := fmt.Sprintf("http://%s/ready", rd.ln.Addr().String())
+	get := func() (int, response) {
+		req, _ := http.NewRequest(http.MethodGet, address, nil)
+		req.Header.Set("Accept", "application/json")
+		resp, err := http.DefaultClient.Do(req)
+		if err != nil {
+			t.Fatalf("Unable to query %s: %v", address, err)
+		}
+		defer resp.Body.Close()
+		var r response
+		if err := json.NewDecoder(resp.Body).Decode(&r); err != nil {
+			t.Fatalf("Unable to decode response: %v", err)
+		}
+		return resp.StatusCode, r
+	}
+
+	code, r := get()
+	if code != 200 || !r.Ready || len(r.Plugins) != 1 || !r.Plugins[0].Ready {
+		t.Errorf("Expected ready, got %d: %+v", code, r)
+	}
+
+	// with continuous monitoring a plugin that becomes unready makes the process unready again.
+	rp.reason = "upstreams down"
+	code, r = get()
+	if code != 503 || r.Ready {
+		t.Errorf("Expected not ready, got %d: %+v", code, r)
+	}
+	if len(r.Plugins) != 1 || r.Plugins[0].Name != "reporter" || r.Plugins[0].Reason != "upstreams down" {
+		t.Errorf("Expected reporter to be not ready with a reason, got %+v", r.Plugins)
+	}
+}
@@ -11,11 +11,11 @@ import (
 func init() { plugin.Register("ready", setup) }
 
 func setup(c *caddy.Controller) error {
-	addr, err := parse(c)
+	rd, err := parse(c)
 	if err != nil {
 		return plugin.Error("ready", err)
 	}
-	rd := &ready{Addr: addr}
+	addr := rd.Addr
 
 	uniqAddr.Set(addr, rd.onStartup)
 	c.OnStartup(func() error { uniqAddr.Set(addr, rd.onStartup); return nil })
@@ -48,12 +48,12 @@ func setup(c *caddy.Controller) error {
 	return nil
 }
 
-func parse(c *caddy.Controller) (string, error) {
-	addr := ":8181"
+func parse(c *caddy.Controller) (*ready, error) {
+	rd := &ready{Addr: ":8181"}
 	i := 0
 	for c.Next() {
 		if i > 0 {
-			return "", plugin.ErrOnce
+			return nil, plugin.ErrOnce
 		}
 		i++
 		args := c.RemainingArgs()
@@ -61,13 +61,33 @@ func parse(c *caddy.Controller) (string, error) {
 		switch len(args) {
 		case 0:
 		case 1:
-			addr = args[0]
-			if _, _, e := net.SplitHostPort(addr); e != nil {
-				return "", e
+			rd.Addr = args[0]
+			if _, _, e := net.SplitHostPort(rd.Addr); e != nil {
+				return nil, e
 			}
 		default:
-			return "", c.ArgErr()
+			return nil, c.ArgErr()
+		}
+
+		for c.NextBlock() {
+			switch c.Val() {
+			case "monitor":
+				args := c.RemainingArgs()
+				if len(args) != 1 {
+					return nil, c.ArgErr()
+				}
+				switch args[0] {
+				case "until-ready":
+					rd.Continuously = false
+				case "continuously":
+					rd.Continuously = true
+				default:
+					return nil, c.Errf("unknown monitor mode '%s'", args[0])
+				}
+			default:
+				return nil, c.Errf("unknown property '%s'", c.Val())
+			}
 		}
 	}
-	return addr, nil
+	return rd, nil
 }
@@ -16,6 +16,21 @@ func TestSetupReady(t *testing.T) {
 		{`ready localhost:1234 b`, true},
 		{`ready bla`, true},
 		{`ready bla bla`, true},
+		{`ready {
+			monitor continuously
+		}`, false},
+		{`ready localhost:1234 {
+			monitor until-ready
+		}`, false},
+		{`ready {
+			monitor
+		}`, true},
+		{`ready {
+			monitor sometimes
+		}`, true},
+		{`ready {
+			unknown
+		}`, true},
 	}
 
 	for i, test := range tests {
@@ -0,0 +1,118 @@
+package rewrite
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"strings"
+
+	"github.com/coredns/coredns/request"
+
+	"github.com/miekg/dns"
+)
+
+// addressResponseRule maps the addresses in A and AAAA records in the from network to the to network,
+// keeping the host part of the address.
+type addressResponseRule struct {
+	from *net.IPNet
+	to   *net.IPNet
+}
+
+func (r *addressResponseRule) RewriteResponse(rr dns.RR) {
+	v4 := len(r.from.IP) == net.IPv4len
+	switch x := rr.(type) {
+	case *dns.A:
+		if v4 {
+			x.A = r.mapIP(x.A)
+		}
+	case *dns.AAAA:
+		if !v4 {
+			x.AAAA = r.mapIP(x.AAAA)
+		}
+	}
+}
+
+func (r *addressResponseRule) mapIP(ip net.IP) net.IP {
+	if !r.from.Contains(ip) {
+		return ip
+	}
+	if len(r.from.IP) == net.IPv4len {
+		ip = ip.To4()
+	}
+	mapped := make(net.IP, len(ip))
+	for i := range ip {
+		mapped[i] = r.to.IP[i] | ip[i]&^r.from.Mask[i]
+	}
+	return mapped
+}
+
+type addressRule struct {
+	nextAction string
+	match      nameMatcher
+	response   addressResponseRule
+}
+
+// newAddressRule creates a rule that maps addresses in the response, the name to match is optional.
+func newAddressRule(nextAction string, args ...string) (Rule, error) {
+	if len(args) < 2 {
+		return nil, fmt.Errorf("too few (%d) arguments for an address rule", len(args))
+	}
+	match := nameMatcher(matchAll)
+	if len(args) > 2 {
+		var err error
+		match, args, err = parseNameMatcher(args)
+		if err != nil {
+			return nil, fmt.Errorf("address rule: %s", err)
+		}
+		if len(args) != 2 {
+			return nil, fmt.Errorf("address rules must end with two networks")
+		}
+	}
+	from, err := parseNetwork(args[0])
+	if err != nil {
+		return nil, err
+	}
+	to, err := parseNetwork(args[1])
+	if err != nil {
+		return nil, err
+	}
+	if len(from.IP) != len(to.IP) {
+		return nil, fmt.Errorf("networks %s and %s are not of the same address family", args[0], args[1])
+	}
+	fromOnes, _ := from.Mask.Size()
+	toOnes, _ := to.Mask.Size()
+	if fromOnes != toOnes {
+		return nil, fmt.Errorf("networks %s and %s must have the same prefix length", args[0], args[1])
+	}
+	return &addressRule{nextAction: nextAction, match: match, response: addressResponseRule{from: from, to: to}}, nil
+}
+
+// parseNetwork parses a network in CIDR notation, or a single address.
+func parseNetwork(s string) (*net.IPNet, error) {
+	if !strings.Contains(s, "/") {
+		ip := net.ParseIP(s)
+		if ip == nil {
+			return nil, fmt.Errorf("invalid address %s", s)
+		}
+		if ip4 := ip.To4(); ip4 != nil {
+			return &net.IPNet{IP: ip4, Mask: net.CIDRMask(32, 32)}, nil
+		}
+		return &net.IPNet{IP: ip, Mask: net.CIDRMask(128, 128)}, nil
+	}
+	_, n, err := net.ParseCIDR(s)
+	if err != nil {
+		return nil, fmt.Errorf("invalid network %s", s)
+	}
+	return n, nil
+}
+
+// Rewrite rewrites the addresses in the response when the name matches.
+func (rule *addressRule) Rewrite(ctx context.Context, state request.Request) (ResponseRules, Result) {
+	if !rule.match(state.Name()) {
+		return nil, RewriteIgnored
+	}
+	return ResponseRules{&rule.response}, RewriteDone
+}
+
+// Mode returns the processing nextAction
+func (rule *addressRule) Mode() string { return rule.nextAction }
@@ -0,0 +1,102 @@
+package rewrite
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/coredns/coredns/plugin"
+	"github.com/coredns/coredns/plugin/pkg/dnstest"
+	"github.com/coredns/coredns/plugin/test"
+
+	"github.com/miekg/dns"
+)
+
+func TestNewAddressRule(t *testing.T) {
+	tests := []struct {
+		args      string
+		shouldErr bool
+	}{
+		{"203.0.113.0/24 10.1.2.0/24", false},
+		{"203.0.113.10 10.1.2.10", false},
+		{"2001:db8::/32 fd00:1::/32", false},
+		{"example.org 203.0.113.0/24 10.1.2.0/24", false},
+		{"suffix .example.org 203.0.113.0/24 10.1.2.0/24", false},
+		{"regex ^www\\. 203.0.113.0/24 10.1.2.0/24", false},
+		{"203.0.113.0/24", true},
+		{"203.0.113.0/24 10.1.0.0/16", true},
+		{"203.0.113.0/24 fd00:1::/24", true},
+		{"203.0.113.0/33 10.1.2.0/24", true},
+		{"notanip 10.1.2.0/24", true},
+		{"suffix .example.org 203.0.113.0/24", true},
+		{"regex ( 203.0.113.0/24 10.1.2.0/24", true},
+	}
+	for i, tc := range tests {
+		_, err := newRule(append([]string{"address"}, strings.Fields(tc.args)...)...)
+		if tc.shouldErr && err == nil {
+			t.Errorf("Test %d: expected error for %q", i, tc.args)
+		}
+		if !tc.shouldErr && err != nil {
+			t.Errorf("Test %d: expected no error for %q, got %s", i, tc.args, err)
+		}
+	}
+}
+
+func TestAddressRewrite(t *testing.T) {
+	tests := []struct {
+		rule     string
+		qname    string
+		answer   []dns.RR
+		expected []string
+	}{
+		{
+			"address 203.0.113.0/24 10.1.2.0/24", "www.example.org.",
+			[]dns.RR{test.A("www.example.org. 300 IN A 203.0.113.7"), test.A("www.example.org. 300 IN A 198.51.100.7")},
+			[]string{"10.1.2.7", "198.51.100.7"},
+		},
+		{
+			"address 203.0.113.7 10.1.2.8", "www.example.org.",
+			[]dns.RR{test.A("www.example.org. 300 IN A 203.0.113.7"), test.A("www.example.org. 300 IN A 203.0.113.8")},
+			[]string{"10.1.2.8", "203.0.113.8"},
+		},
+		{
+			"address 2001:db8::/32 fd00:1::/32", "www.example.org.",
+			[]dns.RR{test.AAAA("www.example.org. 300 IN AAAA 2001:db8:5::1"), test.A("www.example.org. 300 IN A 203.0.113.7")},
+			[]string{"fd00:1:5::1", "203.0.113.7"},
+		},
+		{
+			"address suffix .example.net 203.0.113.0/24 10.1.2.0/24", "www.example.org.",
+			[]dns.RR{test.A("www.example.org. 300 IN A 203.0.113.7")},
+			[]string{"203.0.113.7"},
+		},
+	}
+	for i, tc := range tests {
+		rule, err := newRule(strings.Fields(tc.rule)...)
+		if err != nil {
+			t.Fatalf("Test %d: failed to create rule: %s", i, err)
+		}
+		rw := Rewrite{Next: plugin.HandlerFunc(msgPrinter), Rules: []Rule{rule}, RevertPolicy: NewRevertPolicy(false, false)}
+
+		m := new(dns.Msg)
+		m.SetQuestion(tc.qname, dns.TypeA)
+		m.Answer = tc.answer
+		rec := dnstest.NewRecorder(&test.ResponseWriter{})
+		rw.ServeDNS(context.TODO(), rec, m)
+
+		if len(rec.Msg.Answer) != len(tc.expected) {
+			t.Fatalf("Test %d: expected %d records, got %d", i, len(tc.expected), len(rec.Msg.Answer))
+		}
+		for j, rr := range rec.Msg.Answer {
+			var ip string
+			switch x := rr.(type) {
+			case *dns.A:
+				ip = x.A.String()
+			case *dns.AAAA:
+				ip = x.AAAA.String()
+			}
+			if ip != tc.expected[j] {
+				t.Errorf("Test %d: expected address %s, got %s", i, tc.expected[j], ip)
+			}
+		}
+	}
+}
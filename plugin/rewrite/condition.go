@@ -0,0 +1,59 @@
+package rewrite
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/coredns/coredns/plugin/pkg/expression"
+	"github.com/coredns/coredns/request"
+
+	"github.com/antonmedv/expr"
+	"github.com/antonmedv/expr/vm"
+)
+
+// If is the keyword that starts the condition of a rule.
+const If = "if"
+
+// conditionalRule is a rule that is only applied when its expression evaluates to true.
+type conditionalRule struct {
+	Rule
+	prog *vm.Program
+}
+
+// newConditionalRule wraps rule, so it's only applied when the expression in args evaluates to true.
+func newConditionalRule(rule Rule, args ...string) (Rule, error) {
+	if len(args) == 0 {
+		return nil, fmt.Errorf("%s must be followed by an expression", If)
+	}
+	e := strings.Join(args, " ")
+	prog, err := expr.Compile(e, expr.Env(expression.DefaultEnv(context.Background(), nil)))
+	if err != nil {
+		return nil, fmt.Errorf("invalid expression %q: %s", e, err)
+	}
+	return &conditionalRule{Rule: rule, prog: prog}, nil
+}
+
+// Rewrite implements the Rule interface. The request is only rewritten if the expression evaluates
+// to true, anything else, including an error, leaves it alone.
+func (r *conditionalRule) Rewrite(ctx context.Context, state request.Request) (ResponseRules, Result) {
+	result, err := expr.Run(r.prog, expression.DefaultEnv(ctx, &state))
+	if err != nil {
+		return nil, RewriteIgnored
+	}
+	if b, ok := result.(bool); !ok || !b {
+		return nil, RewriteIgnored
+	}
+	return r.Rule.Rewrite(ctx, state)
+}
+
+// splitCondition splits the arguments of a rule at the if keyword, it returns the arguments of the
+// rule and those of the condition, which are nil when there is no condition.
+func splitCondition(args []string) ([]string, []string) {
+	for i, a := range args {
+		if a == If {
+			return args[:i], args[i+1:]
+		}
+	}
+	return args, nil
+}
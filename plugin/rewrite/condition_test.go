@@ -0,0 +1,95 @@
+package rewrite
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/coredns/caddy"
+	"github.com/coredns/coredns/plugin"
+	"github.com/coredns/coredns/plugin/metadata"
+	"github.com/coredns/coredns/plugin/pkg/dnstest"
+	"github.com/coredns/coredns/plugin/test"
+
+	"github.com/miekg/dns"
+)
+
+func TestNewConditionalRule(t *testing.T) {
+	tests := []struct {
+		args      string
+		shouldErr bool
+	}{
+		{"name a.com b.com if client_ip() == '10.0.0.1'", false},
+		{"continue name suffix .a.com .b.com if incidr(client_ip(), '10.0.0.0/8')", false},
+		{"name regex (.*)\\.a\\.com {1}.b.com answer name (.*)\\.b\\.com {1}.a.com if type() == 'A'", false},
+		{"edns0 local set 0xffee abcd if metadata('test/label') == 'x'", false},
+		{"name a.com b.com if", true},
+		{"name a.com b.com if client_ip( ==", true},
+		{"name a.com b.com if nosuchfunc()", true},
+		{"name a.com if client_ip() == '10.0.0.1'", true},
+	}
+	for i, tc := range tests {
+		r, err := newRule(strings.Fields(tc.args)...)
+		if tc.shouldErr {
+			if err == nil {
+				t.Errorf("Test %d: expected error for %q", i, tc.args)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("Test %d: expected no error, got %s", i, err)
+			continue
+		}
+		if _, ok := r.(*conditionalRule); !ok {
+			t.Errorf("Test %d: expected a conditional rule, got %T", i, r)
+		}
+	}
+}
+
+func TestRewriteCondition(t *testing.T) {
+	tests := []struct {
+		rule     string
+		qtype    uint16
+		label    string
+		expected string
+	}{
+		{"rewrite name a.com b.com if client_ip() == '10.240.0.1'", dns.TypeA, "", "b.com."},
+		{"rewrite name a.com b.com if client_ip() == '10.0.0.1'", dns.TypeA, "", "a.com."},
+		{"rewrite name a.com b.com if incidr(client_ip(), '10.240.0.0/16') && type() == 'AAAA'", dns.TypeA, "", "a.com."},
+		{"rewrite name a.com b.com if incidr(client_ip(), '10.240.0.0/16') && type() == 'AAAA'", dns.TypeAAAA, "", "b.com."},
+		{"rewrite name a.com b.com if metadata('test/label') == 'blue'", dns.TypeA, "blue", "b.com."},
+		{"rewrite name a.com b.com if metadata('test/label') == 'blue'", dns.TypeA, "green", "a.com."},
+		// Not a boolean.
+		{"rewrite name a.com b.com if name()", dns.TypeA, "", "a.com."},
+		{`rewrite {
+    name a.com b.com
+    if port() == '40212'
+}`, dns.TypeA, "", "b.com."},
+	}
+	for i, tc := range tests {
+		c := caddy.NewTestController("dns", tc.rule)
+		rules, err := rewriteParse(c)
+		if err != nil {
+			t.Fatalf("Test %d: failed to parse %q: %s", i, tc.rule, err)
+		}
+		seen := ""
+		next := plugin.HandlerFunc(func(ctx context.Context, w dns.ResponseWriter, r *dns.Msg) (int, error) {
+			seen = r.Question[0].Name
+			return msgPrinter(ctx, w, r)
+		})
+		rw := Rewrite{Next: next, Rules: rules}
+
+		ctx := metadata.ContextWithMetadata(context.Background())
+		label := tc.label
+		metadata.SetValueFunc(ctx, "test/label", func() string { return label })
+
+		m := new(dns.Msg)
+		m.SetQuestion("a.com.", tc.qtype)
+		rec := dnstest.NewRecorder(&test.ResponseWriter{})
+		rw.ServeDNS(ctx, rec, m)
+
+		if seen != tc.expected {
+			t.Errorf("Test %d: expected name %s, got %s", i, tc.expected, seen)
+		}
+	}
+}
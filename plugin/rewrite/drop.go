@@ -0,0 +1,66 @@
+package rewrite
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/coredns/coredns/request"
+
+	"github.com/miekg/dns"
+)
+
+// dropResponseRule removes the records of the given types from the answer section.
+type dropResponseRule struct {
+	types map[uint16]struct{}
+}
+
+// RewriteResponse implements ResponseRule, records are removed in RewriteResponseMsg.
+func (r *dropResponseRule) RewriteResponse(rr dns.RR) {}
+
+func (r *dropResponseRule) RewriteResponseMsg(res *dns.Msg) {
+	answer := res.Answer[:0]
+	for _, rr := range res.Answer {
+		if _, ok := r.types[rr.Header().Rrtype]; !ok {
+			answer = append(answer, rr)
+		}
+	}
+	res.Answer = answer
+}
+
+type dropRule struct {
+	nextAction string
+	match      nameMatcher
+	response   dropResponseRule
+}
+
+// newDropRule creates a rule that removes records of one or more types from the answer section.
+func newDropRule(nextAction string, args ...string) (Rule, error) {
+	match, args, err := parseNameMatcher(args)
+	if err != nil {
+		return nil, fmt.Errorf("drop rule: %s", err)
+	}
+	if len(args) == 0 {
+		return nil, fmt.Errorf("drop rules need one or more types to drop")
+	}
+	types := make(map[uint16]struct{}, len(args))
+	for _, a := range args {
+		t, ok := dns.StringToType[strings.ToUpper(a)]
+		if !ok {
+			return nil, fmt.Errorf("invalid type %q in a drop rule", a)
+		}
+		types[t] = struct{}{}
+	}
+	return &dropRule{nextAction: nextAction, match: match, response: dropResponseRule{types: types}}, nil
+}
+
+// Rewrite drops records from the response when the name matches.
+func (rule *dropRule) Rewrite(ctx context.Context, state request.Request) (ResponseRules, Result) {
+	if !rule.match(state.Name()) {
+		return nil, RewriteIgnored
+	}
+	return ResponseRules{&rule.response}, RewriteDone
+}
+
+// Mode returns the processing nextAction
+func (rule *dropRule) Mode() string { return rule.nextAction }
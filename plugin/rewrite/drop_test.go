@@ -0,0 +1,61 @@
+package rewrite
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/coredns/coredns/plugin"
+	"github.com/coredns/coredns/plugin/pkg/dnstest"
+	"github.com/coredns/coredns/plugin/test"
+
+	"github.com/miekg/dns"
+)
+
+func TestDropRewrite(t *testing.T) {
+	answer := []dns.RR{
+		test.CNAME("www.example.org. 300 IN CNAME web.example.org."),
+		test.AAAA("web.example.org. 300 IN AAAA 2001:db8::1"),
+		test.A("web.example.org. 300 IN A 203.0.113.7"),
+	}
+	tests := []struct {
+		rule      string
+		shouldErr bool
+		expected  []uint16
+	}{
+		{"drop www.example.org AAAA", false, []uint16{dns.TypeCNAME, dns.TypeA}},
+		{"drop suffix .example.org aaaa cname", false, []uint16{dns.TypeA}},
+		{"drop exact other.example.org AAAA", false, []uint16{dns.TypeCNAME, dns.TypeAAAA, dns.TypeA}},
+		{"drop www.example.org", true, nil},
+		{"drop www.example.org BOGUS", true, nil},
+		{"drop regex", true, nil},
+	}
+	for i, tc := range tests {
+		rule, err := newRule(strings.Fields(tc.rule)...)
+		if tc.shouldErr {
+			if err == nil {
+				t.Errorf("Test %d: expected error for %q", i, tc.rule)
+			}
+			continue
+		}
+		if err != nil {
+			t.Fatalf("Test %d: failed to create rule: %s", i, err)
+		}
+		rw := Rewrite{Next: plugin.HandlerFunc(msgPrinter), Rules: []Rule{rule}, RevertPolicy: NewRevertPolicy(false, false)}
+
+		m := new(dns.Msg)
+		m.SetQuestion("www.example.org.", dns.TypeA)
+		m.Answer = answer
+		rec := dnstest.NewRecorder(&test.ResponseWriter{})
+		rw.ServeDNS(context.TODO(), rec, m)
+
+		if len(rec.Msg.Answer) != len(tc.expected) {
+			t.Fatalf("Test %d: expected %d records, got %d", i, len(tc.expected), len(rec.Msg.Answer))
+		}
+		for j, rr := range rec.Msg.Answer {
+			if rr.Header().Rrtype != tc.expected[j] {
+				t.Errorf("Test %d: expected type %s, got %s", i, dns.TypeToString[tc.expected[j]], dns.TypeToString[rr.Header().Rrtype])
+			}
+		}
+	}
+}
@@ -0,0 +1,87 @@
+package rewrite
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/coredns/coredns/request"
+
+	"github.com/miekg/dns"
+)
+
+// flattenResponseRule replaces a CNAME chain in the answer section by the records it leads to, owned by
+// the query name. The TTL of those records is capped at the lowest TTL in the chain.
+type flattenResponseRule struct{}
+
+// RewriteResponse implements ResponseRule, the chain is flattened in RewriteResponseMsg.
+func (r *flattenResponseRule) RewriteResponse(rr dns.RR) {}
+
+func (r *flattenResponseRule) RewriteResponseMsg(res *dns.Msg) {
+	if len(res.Question) == 0 {
+		return
+	}
+	q := res.Question[0]
+	if q.Qtype == dns.TypeCNAME {
+		return
+	}
+
+	ttl := uint32(0)
+	cname := false
+	for _, rr := range res.Answer {
+		if rr.Header().Rrtype == dns.TypeCNAME || rr.Header().Rrtype == dns.TypeDNAME {
+			if !cname || rr.Header().Ttl < ttl {
+				ttl = rr.Header().Ttl
+			}
+			cname = true
+		}
+	}
+	if !cname {
+		return
+	}
+
+	answer := []dns.RR{}
+	for _, rr := range res.Answer {
+		hdr := rr.Header()
+		if hdr.Rrtype != q.Qtype && q.Qtype != dns.TypeANY {
+			continue
+		}
+		if hdr.Rrtype == dns.TypeCNAME || hdr.Rrtype == dns.TypeDNAME || hdr.Rrtype == dns.TypeRRSIG {
+			continue
+		}
+		hdr.Name = q.Name
+		if hdr.Ttl > ttl {
+			hdr.Ttl = ttl
+		}
+		answer = append(answer, rr)
+	}
+	res.Answer = answer
+}
+
+type flattenRule struct {
+	nextAction string
+	match      nameMatcher
+	response   flattenResponseRule
+}
+
+// newFlattenRule creates a rule that flattens CNAME chains in the response.
+func newFlattenRule(nextAction string, args ...string) (Rule, error) {
+	match, args, err := parseNameMatcher(args)
+	if err != nil {
+		return nil, fmt.Errorf("flatten rule: %s", err)
+	}
+	if len(args) != 0 {
+		return nil, fmt.Errorf("too many arguments for a flatten rule")
+	}
+	return &flattenRule{nextAction: nextAction, match: match}, nil
+}
+
+// Rewrite flattens the CNAME chains in the response when the name matches.
+func (rule *flattenRule) Rewrite(ctx context.Context, state request.Request) (ResponseRules, Result) {
+	if !rule.match(state.Name()) {
+		return nil, RewriteIgnored
+	}
+	return ResponseRules{&rule.response}, RewriteDone
+}
+
+// Mode returns the processing nextAction
+func (rule *flattenRule) Mode() string { return rule.nextAction }
@@ -0,0 +1,80 @@
+package rewrite
+
+import (
+	"context"
+	"testing"
+
+	"github.com/coredns/coredns/plugin"
+	"github.com/coredns/coredns/plugin/pkg/dnstest"
+	"github.com/coredns/coredns/plugin/test"
+
+	"github.com/miekg/dns"
+)
+
+func TestFlattenRewrite(t *testing.T) {
+	tests := []struct {
+		qtype    uint16
+		answer   []dns.RR
+		expected []dns.RR
+	}{
+		{
+			dns.TypeA,
+			[]dns.RR{
+				test.CNAME("www.example.org. 300 IN CNAME lb.example.net."),
+				test.CNAME("lb.example.net. 60 IN CNAME eu.lb.example.net."),
+				test.A("eu.lb.example.net. 120 IN A 203.0.113.7"),
+				test.A("eu.lb.example.net. 30 IN A 203.0.113.8"),
+			},
+			[]dns.RR{
+				test.A("www.example.org. 60 IN A 203.0.113.7"),
+				test.A("www.example.org. 30 IN A 203.0.113.8"),
+			},
+		},
+		// No chain, left alone.
+		{
+			dns.TypeA,
+			[]dns.RR{test.A("www.example.org. 300 IN A 203.0.113.7")},
+			[]dns.RR{test.A("www.example.org. 300 IN A 203.0.113.7")},
+		},
+		// CNAME queries are left alone.
+		{
+			dns.TypeCNAME,
+			[]dns.RR{test.CNAME("www.example.org. 300 IN CNAME lb.example.net.")},
+			[]dns.RR{test.CNAME("www.example.org. 300 IN CNAME lb.example.net.")},
+		},
+		// A chain that doesn't lead anywhere becomes NODATA.
+		{
+			dns.TypeA,
+			[]dns.RR{test.CNAME("www.example.org. 300 IN CNAME lb.example.net.")},
+			[]dns.RR{},
+		},
+	}
+	rule, err := newRule("flatten", "suffix", "example.org")
+	if err != nil {
+		t.Fatalf("Failed to create rule: %s", err)
+	}
+	for i, tc := range tests {
+		rw := Rewrite{Next: plugin.HandlerFunc(msgPrinter), Rules: []Rule{rule}, RevertPolicy: NewRevertPolicy(false, false)}
+
+		m := new(dns.Msg)
+		m.SetQuestion("www.example.org.", tc.qtype)
+		m.Answer = tc.answer
+		rec := dnstest.NewRecorder(&test.ResponseWriter{})
+		rw.ServeDNS(context.TODO(), rec, m)
+
+		if len(rec.Msg.Answer) != len(tc.expected) {
+			t.Fatalf("Test %d: expected %d records, got %d: %v", i, len(tc.expected), len(rec.Msg.Answer), rec.Msg.Answer)
+		}
+		for j, rr := range rec.Msg.Answer {
+			if rr.String() != tc.expected[j].String() {
+				t.Errorf("Test %d: expected %s, got %s", i, tc.expected[j], rr)
+			}
+		}
+	}
+
+	for _, args := range [][]string{{"flatten"}, {"flatten", "a.example.org", "b.example.org"}} {
+		if _, err := newRule(args...); err == nil {
+			t.Errorf("Expected error for %v", args)
+		}
+	}
+}
@@ -0,0 +1,53 @@
+package rewrite
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/coredns/coredns/plugin"
+)
+
+// nameMatcher returns true if the name in the question section of a request matches.
+type nameMatcher func(name string) bool
+
+// parseNameMatcher parses [exact|prefix|suffix|substring|regex] STRING at the start of args, it returns
+// the matcher and the arguments after it.
+func parseNameMatcher(args []string) (nameMatcher, []string, error) {
+	if len(args) == 0 {
+		return nil, nil, fmt.Errorf("no name to match")
+	}
+	matchType := ExactMatch
+	switch t := strings.ToLower(args[0]); t {
+	case ExactMatch, PrefixMatch, SuffixMatch, SubstringMatch, RegexMatch:
+		if len(args) < 2 {
+			return nil, nil, fmt.Errorf("no name to match after %s", t)
+		}
+		matchType = t
+		args = args[1:]
+	}
+
+	s := args[0]
+	switch matchType {
+	case ExactMatch:
+		s = plugin.Name(s).Normalize()
+		return func(name string) bool { return name == s }, args[1:], nil
+	case PrefixMatch:
+		s = plugin.Name(s).Normalize()
+		return func(name string) bool { return strings.HasPrefix(name, s) }, args[1:], nil
+	case SuffixMatch:
+		s = plugin.Name(s).Normalize()
+		return func(name string) bool { return strings.HasSuffix(name, s) }, args[1:], nil
+	case SubstringMatch:
+		s = plugin.Name(s).Normalize()
+		return func(name string) bool { return strings.Contains(name, s) }, args[1:], nil
+	}
+	re, err := regexp.Compile(s)
+	if err != nil {
+		return nil, nil, fmt.Errorf("invalid regex pattern: %s", s)
+	}
+	return func(name string) bool { return re.MatchString(name) }, args[1:], nil
+}
+
+// matchAll matches all names.
+func matchAll(string) bool { return true }
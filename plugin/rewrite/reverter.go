@@ -44,6 +44,13 @@ type ResponseRule interface {
 	RewriteResponse(rr dns.RR)
 }
 
+// ResponseMsgRule is a ResponseRule that also rewrites the response as a whole, which allows it to
+// add or remove records. RewriteResponseMsg is called after the records are rewritten.
+type ResponseMsgRule interface {
+	ResponseRule
+	RewriteResponseMsg(res *dns.Msg)
+}
+
 // ResponseRules describes an ordered list of response rules to apply
 // after a name rewrite
 type ResponseRules = []ResponseRule
@@ -85,6 +92,11 @@ func (r *ResponseReverter) WriteMsg(res1 *dns.Msg) error {
 		for _, rr := range res.Extra {
 			r.rewriteResourceRecord(res, rr)
 		}
+		for _, rule := range r.ResponseRules {
+			if mr, ok := rule.(ResponseMsgRule); ok {
+				mr.RewriteResponseMsg(res)
+			}
+		}
 	}
 	return r.ResponseWriter.WriteMsg(res)
 }
@@ -93,6 +93,14 @@ func newRule(args ...string) (Rule, error) {
 		return nil, fmt.Errorf("no rule type specified for rewrite")
 	}
 
+	if args, cond := splitCondition(args); cond != nil {
+		rule, err := newRule(args...)
+		if err != nil {
+			return nil, err
+		}
+		return newConditionalRule(rule, cond...)
+	}
+
 	arg0 := strings.ToLower(args[0])
 	var ruleType string
 	var expectNumArgs, startArg int
@@ -139,6 +147,12 @@ func newRule(args ...string) (Rule, error) {
 		return newEdns0Rule(mode, args[startArg:]...)
 	case "ttl":
 		return newTTLRule(mode, args[startArg:]...)
+	case "address":
+		return newAddressRule(mode, args[startArg:]...)
+	case "drop":
+		return newDropRule(mode, args[startArg:]...)
+	case "flatten":
+		return newFlattenRule(mode, args[startArg:]...)
 	default:
 		return nil, fmt.Errorf("invalid rule type %q", args[0])
 	}
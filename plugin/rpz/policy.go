@@ -0,0 +1,327 @@
+package rpz
+
+import (
+	"net"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/coredns/coredns/plugin/file"
+	"github.com/coredns/coredns/plugin/file/tree"
+
+	"github.com/infobloxopen/go-trees/iptree"
+	"github.com/miekg/dns"
+)
+
+// action is the policy action taken when a trigger matches.
+type action int
+
+const (
+	// actionNXDomain answers with NXDOMAIN, encoded as "CNAME .".
+	actionNXDomain action = iota
+	// actionNoData answers with an empty NOERROR, encoded as "CNAME *.".
+	actionNoData
+	// actionPassthru answers the query unmodified, encoded as "CNAME rpz-passthru.".
+	actionPassthru
+	// actionDrop does not answer the query, encoded as "CNAME rpz-drop.".
+	actionDrop
+	// actionTCPOnly forces the client to retry over TCP, encoded as "CNAME rpz-tcp-only.".
+	actionTCPOnly
+	// actionLocalData answers with the records found in the policy zone.
+	actionLocalData
+)
+
+func (a action) String() string {
+	switch a {
+	case actionNXDomain:
+		return "nxdomain"
+	case actionNoData:
+		return "nodata"
+	case actionPassthru:
+		return "passthru"
+	case actionDrop:
+		return "drop"
+	case actionTCPOnly:
+		return "tcp-only"
+	case actionLocalData:
+		return "local-data"
+	}
+	return "unknown"
+}
+
+// trigger is the part of the query or response that matched a rule.
+type trigger int
+
+const (
+	triggerClientIP trigger = iota
+	triggerQName
+	triggerIP
+	triggerNSDName
+)
+
+func (t trigger) String() string {
+	switch t {
+	case triggerClientIP:
+		return "client-ip"
+	case triggerQName:
+		return "qname"
+	case triggerIP:
+		return "response-ip"
+	case triggerNSDName:
+		return "nsdname"
+	}
+	return "unknown"
+}
+
+// Labels used to encode the trigger in the owner name of a policy record.
+const (
+	labelIP       = "rpz-ip"
+	labelClientIP = "rpz-client-ip"
+	labelNSDName  = "rpz-nsdname"
+	labelNSIP     = "rpz-nsip"
+)
+
+// rule is a single policy rule: the records found at one owner name in the policy zone.
+type rule struct {
+	name   string // owner name in the policy zone
+	action action
+	rrs    []dns.RR // local data, only set for actionLocalData
+}
+
+// rules holds all rules of a policy zone indexed by trigger.
+type rules struct {
+	qname    map[string]*rule // exact names
+	wildcard map[string]*rule // wildcard names, keyed on the name without the leading "*."
+
+	nsdname         map[string]*rule
+	nsdnameWildcard map[string]*rule
+
+	ip       *iptree.Tree
+	clientIP *iptree.Tree
+
+	soa *dns.SOA
+	len int
+}
+
+func newRules() *rules {
+	return &rules{
+		qname:           make(map[string]*rule),
+		wildcard:        make(map[string]*rule),
+		nsdname:         make(map[string]*rule),
+		nsdnameWildcard: make(map[string]*rule),
+		ip:              iptree.NewTree(),
+		clientIP:        iptree.NewTree(),
+	}
+}
+
+// policyZone is a policy zone loaded from disk or transferred from a primary.
+type policyZone struct {
+	origin string
+	*file.Zone
+
+	mu     sync.RWMutex
+	rules  *rules
+	serial int64
+}
+
+func newPolicyZone(origin string, z *file.Zone) *policyZone {
+	return &policyZone{origin: origin, Zone: z, serial: -1}
+}
+
+// Rules returns the compiled rules of p, this may be nil if the zone was never loaded.
+func (p *policyZone) Rules() *rules {
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+	return p.rules
+}
+
+// compile compiles the rules from the zone's content if its SOA serial changed since the last call.
+// It returns true if new rules were compiled.
+func (p *policyZone) compile() bool {
+	serial := p.SOASerialIfDefined()
+	if serial == -1 {
+		return false
+	}
+	p.mu.RLock()
+	same := serial == p.serial
+	p.mu.RUnlock()
+	if same {
+		return false
+	}
+
+	rs := newRules()
+	p.Zone.RLock()
+	rs.soa = p.Apex.SOA
+	p.Tree.Walk(func(e *tree.Elem, _ map[uint16][]dns.RR) error {
+		rs.insert(p.origin, e.Name(), e.All())
+		return nil
+	})
+	p.Zone.RUnlock()
+
+	p.mu.Lock()
+	p.rules = rs
+	p.serial = serial
+	p.mu.Unlock()
+
+	rulesCount.WithLabelValues(p.origin).Set(float64(rs.len))
+	log.Infof("Loaded %d rules from policy zone %q with SOA serial %d", rs.len, p.origin, serial)
+	return true
+}
+
+// insert adds the rule for the records rrs found at owner to rs.
+func (rs *rules) insert(origin, owner string, rrs []dns.RR) {
+	name := strings.TrimSuffix(owner, origin)
+	if name == owner || name == "" {
+		return
+	}
+	name = strings.TrimSuffix(name, ".")
+
+	r := newRule(owner, rrs)
+
+	switch {
+	case strings.HasSuffix(name, "."+labelClientIP):
+		n, err := ipFromName(strings.TrimSuffix(name, "."+labelClientIP))
+		if err != nil {
+			log.Warningf("Skipping %q: %s", owner, err)
+			return
+		}
+		rs.clientIP.InplaceInsertNet(n, r)
+	case strings.HasSuffix(name, "."+labelIP):
+		n, err := ipFromName(strings.TrimSuffix(name, "."+labelIP))
+		if err != nil {
+			log.Warningf("Skipping %q: %s", owner, err)
+			return
+		}
+		rs.ip.InplaceInsertNet(n, r)
+	case strings.HasSuffix(name, "."+labelNSDName):
+		insertName(rs.nsdname, rs.nsdnameWildcard, strings.TrimSuffix(name, "."+labelNSDName), r)
+	case strings.HasSuffix(name, "."+labelNSIP):
+		log.Warningf("Skipping %q: NSIP triggers are not supported", owner)
+		return
+	default:
+		insertName(rs.qname, rs.wildcard, name, r)
+	}
+	rs.len++
+}
+
+func insertName(exact, wildcard map[string]*rule, name string, r *rule) {
+	if strings.HasPrefix(name, "*.") {
+		wildcard[dns.Fqdn(name[2:])] = r
+		return
+	}
+	exact[dns.Fqdn(name)] = r
+}
+
+// newRule returns the rule for the records rrs found at owner.
+func newRule(owner string, rrs []dns.RR) *rule {
+	r := &rule{name: owner, action: actionLocalData}
+	for _, rr := range rrs {
+		if rr.Header().Rrtype == dns.TypeRRSIG || rr.Header().Rrtype == dns.TypeNSEC {
+			continue
+		}
+		if cname, ok := rr.(*dns.CNAME); ok {
+			switch cname.Target {
+			case ".":
+				r.action = actionNXDomain
+				return r
+			case "*.":
+				r.action = actionNoData
+				return r
+			case "rpz-passthru.":
+				r.action = actionPassthru
+				return r
+			case "rpz-drop.":
+				r.action = actionDrop
+				return r
+			case "rpz-tcp-only.":
+				r.action = actionTCPOnly
+				return r
+			}
+		}
+		r.rrs = append(r.rrs, rr)
+	}
+	return r
+}
+
+// matchName returns the rule for name, exact matches take precedence over wildcards, longer wildcards
+// take precedence over shorter ones.
+func matchName(exact, wildcard map[string]*rule, name string) *rule {
+	if r, ok := exact[name]; ok {
+		return r
+	}
+	if len(wildcard) == 0 {
+		return nil
+	}
+	for off, end := dns.NextLabel(name, 0); !end; off, end = dns.NextLabel(name, off) {
+		if r, ok := wildcard[name[off:]]; ok {
+			return r
+		}
+	}
+	return nil
+}
+
+func (rs *rules) matchQName(qname string) *rule { return matchName(rs.qname, rs.wildcard, qname) }
+
+func (rs *rules) matchNSDName(ns string) *rule {
+	return matchName(rs.nsdname, rs.nsdnameWildcard, ns)
+}
+
+func (rs *rules) matchClientIP(ip net.IP) *rule { return matchIP(rs.clientIP, ip) }
+
+func (rs *rules) matchIP(ip net.IP) *rule { return matchIP(rs.ip, ip) }
+
+func matchIP(t *iptree.Tree, ip net.IP) *rule {
+	if ip == nil {
+		return nil
+	}
+	v, ok := t.GetByIP(ip)
+	if !ok {
+		return nil
+	}
+	return v.(*rule)
+}
+
+// ipFromName parses the reversed address encoding used by rpz-ip and rpz-client-ip triggers. For
+// example "32.1.2.0.192" is 192.0.2.1/32 and "128.1.zz.db8.2001" is 2001:db8::1/128.
+func ipFromName(name string) (*net.IPNet, error) {
+	labels := dns.SplitDomainName(name)
+	if len(labels) < 2 {
+		return nil, errInvalidTrigger
+	}
+	prefix, err := strconv.Atoi(labels[0])
+	if err != nil {
+		return nil, errInvalidTrigger
+	}
+	labels = labels[1:]
+	for i, j := 0, len(labels)-1; i < j; i, j = i+1, j-1 {
+		labels[i], labels[j] = labels[j], labels[i]
+	}
+
+	var addr string
+	bits := 128
+	if len(labels) == 4 && !strings.Contains(name, "zz") {
+		addr = strings.Join(labels, ".")
+		bits = 32
+	} else {
+		for i := range labels {
+			if labels[i] == "zz" {
+				labels[i] = ""
+			}
+		}
+		addr = strings.Join(labels, ":")
+		if strings.HasPrefix(addr, ":") {
+			addr = ":" + addr
+		}
+		if strings.HasSuffix(addr, ":") {
+			addr += ":"
+		}
+	}
+	ip := net.ParseIP(addr)
+	if ip == nil || prefix < 0 || prefix > bits {
+		return nil, errInvalidTrigger
+	}
+	if bits == 32 {
+		ip = ip.To4()
+	}
+	return &net.IPNet{IP: ip.Mask(net.CIDRMask(prefix, bits)), Mask: net.CIDRMask(prefix, bits)}, nil
+}
@@ -0,0 +1,11 @@
+package rpz
+
+// Ready implements the ready.Readiness interface. It returns true when all policy zones have been loaded.
+func (rp *RPZ) Ready() bool {
+	for _, p := range rp.policies {
+		if p.Rules() == nil {
+			return false
+		}
+	}
+	return true
+}
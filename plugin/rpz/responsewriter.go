@@ -0,0 +1,85 @@
+package rpz
+
+import (
+	"context"
+	"net"
+	"strings"
+
+	"github.com/coredns/coredns/request"
+
+	"github.com/miekg/dns"
+)
+
+// ResponseWriter checks the response for IP, NSDNAME and CNAME target triggers before it is written
+// to the client.
+type ResponseWriter struct {
+	dns.ResponseWriter
+	ctx   context.Context
+	state request.Request
+	rpz   *RPZ
+}
+
+// WriteMsg implements the dns.ResponseWriter interface.
+func (w *ResponseWriter) WriteMsg(res *dns.Msg) error {
+	for _, p := range w.rpz.policies {
+		rs := p.Rules()
+		if rs == nil {
+			continue
+		}
+		ru, t := rs.matchResponse(res)
+		if ru == nil {
+			continue
+		}
+		state := w.state
+		state.W = w.ResponseWriter
+		_, err := w.rpz.apply(w.ctx, state, p, ru, t, res)
+		return err
+	}
+	return w.ResponseWriter.WriteMsg(res)
+}
+
+// Write implements the dns.ResponseWriter interface.
+func (w *ResponseWriter) Write(buf []byte) (int, error) {
+	log.Warning("ResponseWriter called with Write: not checking policy")
+	return w.ResponseWriter.Write(buf)
+}
+
+// matchResponse returns the first rule that matches the response and the trigger that matched it.
+// CNAME targets are checked as QNAME triggers, addresses in the answer section as IP triggers and
+// the name servers in the answer and authority sections as NSDNAME triggers.
+func (rs *rules) matchResponse(res *dns.Msg) (*rule, trigger) {
+	for _, rr := range res.Answer {
+		if cname, ok := rr.(*dns.CNAME); ok {
+			if ru := rs.matchQName(strings.ToLower(cname.Target)); ru != nil {
+				return ru, triggerQName
+			}
+		}
+	}
+	for _, rr := range res.Answer {
+		var ip net.IP
+		switch x := rr.(type) {
+		case *dns.A:
+			ip = x.A
+		case *dns.AAAA:
+			ip = x.AAAA
+		default:
+			continue
+		}
+		if ru := rs.matchIP(ip); ru != nil {
+			return ru, triggerIP
+		}
+	}
+	if len(rs.nsdname) == 0 && len(rs.nsdnameWildcard) == 0 {
+		return nil, 0
+	}
+	for _, section := range [][]dns.RR{res.Answer, res.Ns} {
+		for _, rr := range section {
+			if ns, ok := rr.(*dns.NS); ok {
+				if ru := rs.matchNSDName(strings.ToLower(ns.Ns)); ru != nil {
+					return ru, triggerNSDName
+				}
+			}
+		}
+	}
+	return nil, 0
+}
@@ -0,0 +1,178 @@
+// Package rpz implements Response Policy Zones.
+package rpz
+
+import (
+	"context"
+	"errors"
+	"net"
+	"strings"
+
+	"github.com/coredns/coredns/plugin"
+	"github.com/coredns/coredns/plugin/metadata"
+	"github.com/coredns/coredns/plugin/metrics"
+	clog "github.com/coredns/coredns/plugin/pkg/log"
+	"github.com/coredns/coredns/plugin/pkg/upstream"
+	"github.com/coredns/coredns/request"
+
+	"github.com/miekg/dns"
+)
+
+var log = clog.NewWithPlugin("rpz")
+
+var errInvalidTrigger = errors.New("invalid address trigger")
+
+// RPZ applies the policies found in one or more response policy zones to queries and responses.
+type RPZ struct {
+	Next plugin.Handler
+
+	Zones    []string
+	policies []*policyZone
+
+	upstream *upstream.Upstream
+}
+
+// ServeDNS implements the plugin.Handler interface.
+func (rp *RPZ) ServeDNS(ctx context.Context, w dns.ResponseWriter, r *dns.Msg) (int, error) {
+	state := request.Request{W: w, Req: r}
+	if plugin.Zones(rp.Zones).Matches(state.Name()) == "" {
+		return plugin.NextOrFailure(rp.Name(), rp.Next, ctx, w, r)
+	}
+
+	ip := clientIP(state)
+	qname := state.Name()
+
+	// Triggers that can be evaluated before resolving the query. Policy zones are checked in the
+	// order they are configured, the first match wins.
+	for _, p := range rp.policies {
+		rs := p.Rules()
+		if rs == nil {
+			continue
+		}
+		if ru := rs.matchClientIP(ip); ru != nil {
+			return rp.apply(ctx, state, p, ru, triggerClientIP, nil)
+		}
+		if ru := rs.matchQName(qname); ru != nil {
+			return rp.apply(ctx, state, p, ru, triggerQName, nil)
+		}
+	}
+
+	rw := &ResponseWriter{ResponseWriter: w, ctx: ctx, state: state, rpz: rp}
+	return plugin.NextOrFailure(rp.Name(), rp.Next, ctx, rw, r)
+}
+
+// Name implements the plugin.Handler interface.
+func (rp *RPZ) Name() string { return "rpz" }
+
+// apply executes the action of rule ru from policy zone p. If res is not nil, it is the response that
+// was being written when a response trigger matched; it is written unmodified on passthru.
+func (rp *RPZ) apply(ctx context.Context, state request.Request, p *policyZone, ru *rule, t trigger, res *dns.Msg) (int, error) {
+	hitCount.WithLabelValues(metrics.WithServer(ctx), p.origin, t.String(), ru.action.String(), metrics.WithView(ctx)).Inc()
+	metadata.SetValueFunc(ctx, "rpz/zone", func() string { return p.origin })
+	metadata.SetValueFunc(ctx, "rpz/rule", func() string { return ru.name })
+	metadata.SetValueFunc(ctx, "rpz/trigger", func() string { return t.String() })
+	metadata.SetValueFunc(ctx, "rpz/action", func() string { return ru.action.String() })
+
+	switch ru.action {
+	case actionDrop:
+		return dns.RcodeSuccess, nil
+
+	case actionPassthru:
+		if res != nil {
+			state.W.WriteMsg(res)
+			return dns.RcodeSuccess, nil
+		}
+		return plugin.NextOrFailure(rp.Name(), rp.Next, ctx, state.W, state.Req)
+
+	case actionTCPOnly:
+		if state.Proto() == "tcp" {
+			if res != nil {
+				state.W.WriteMsg(res)
+				return dns.RcodeSuccess, nil
+			}
+			return plugin.NextOrFailure(rp.Name(), rp.Next, ctx, state.W, state.Req)
+		}
+		m := new(dns.Msg)
+		m.SetReply(state.Req)
+		m.Truncated = true
+		state.W.WriteMsg(m)
+		return dns.RcodeSuccess, nil
+
+	case actionNXDomain:
+		m := new(dns.Msg)
+		m.SetRcode(state.Req, dns.RcodeNameError)
+		m.Ns = p.soa()
+		state.W.WriteMsg(m)
+		return dns.RcodeSuccess, nil
+
+	case actionNoData:
+		m := new(dns.Msg)
+		m.SetReply(state.Req)
+		m.Ns = p.soa()
+		state.W.WriteMsg(m)
+		return dns.RcodeSuccess, nil
+	}
+
+	m := new(dns.Msg)
+	m.SetReply(state.Req)
+	m.Answer = rp.localData(ctx, state, ru)
+	if len(m.Answer) == 0 {
+		m.Ns = p.soa()
+	}
+	state.W.WriteMsg(m)
+	return dns.RcodeSuccess, nil
+}
+
+// localData returns the local data of rule ru for the query in state, with the owner names set to
+// the query name. A CNAME is followed through the server itself.
+func (rp *RPZ) localData(ctx context.Context, state request.Request, ru *rule) []dns.RR {
+	qname, qtype := state.Name(), state.QType()
+	var answer []dns.RR
+	for _, rr := range ru.rrs {
+		rtype := rr.Header().Rrtype
+		if rtype != qtype && rtype != dns.TypeCNAME {
+			continue
+		}
+		rr = dns.Copy(rr)
+		rr.Header().Name = qname
+
+		cname, ok := rr.(*dns.CNAME)
+		if !ok || qtype == dns.TypeCNAME {
+			answer = append(answer, rr)
+			continue
+		}
+		// A wildcard target prepends the query name, i.e. "CNAME *.walled-garden.example.org."
+		if strings.HasPrefix(cname.Target, "*.") {
+			cname.Target = qname + cname.Target[2:]
+		}
+		answer = append(answer, cname)
+
+		m, err := rp.upstream.Lookup(ctx, state, cname.Target, qtype)
+		if err != nil || m == nil {
+			log.Debugf("Failed to resolve local data CNAME target %q: %v", cname.Target, err)
+			break
+		}
+		answer = append(answer, m.Answer...)
+		break
+	}
+	return answer
+}
+
+// soa returns the SOA record of the policy zone, for use in the authority section of negative answers.
+func (p *policyZone) soa() []dns.RR {
+	rs := p.Rules()
+	if rs == nil || rs.soa == nil {
+		return nil
+	}
+	soa := dns.Copy(rs.soa).(*dns.SOA)
+	soa.Hdr.Ttl = soa.Minttl
+	return []dns.RR{soa}
+}
+
+// clientIP returns the source address of the query, stripped of any IPv6 zone.
+func clientIP(state request.Request) net.IP {
+	ip := state.IP()
+	if idx := strings.IndexByte(ip, '%'); idx >= 0 {
+		ip = ip[:idx]
+	}
+	return net.ParseIP(ip)
+}
@@ -0,0 +1,203 @@
+package rpz
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/coredns/coredns/plugin/file"
+	"github.com/coredns/coredns/plugin/pkg/dnstest"
+	"github.com/coredns/coredns/plugin/test"
+
+	"github.com/miekg/dns"
+)
+
+const dbRPZ = `$ORIGIN rpz.example.org.
+@	3600 IN	SOA  ns.example.org. hostmaster.example.org. 1 3600 600 86400 60
+	3600 IN	NS   ns.example.org.
+
+blocked.example.net        CNAME .
+*.blocked.example.net      CNAME .
+nodata.example.net         CNAME *.
+allowed.blocked.example.net CNAME rpz-passthru.
+drop.example.net           CNAME rpz-drop.
+tcp.example.net            CNAME rpz-tcp-only.
+local.example.net          A     192.0.2.53
+local.example.net          TXT   "blocked by policy"
+redirect.example.net       CNAME walled-garden.example.org.
+
+32.1.2.0.192.rpz-client-ip CNAME .
+24.0.100.51.198.rpz-ip     CNAME .
+128.1.zz.db8.2001.rpz-ip   CNAME *.
+ns.bad.example.rpz-nsdname CNAME .
+`
+
+func newTestRPZ(t *testing.T, next test.Handler) *RPZ {
+	z, err := file.Parse(strings.NewReader(dbRPZ), "rpz.example.org.", "stdin", 0)
+	if err != nil {
+		t.Fatalf("Expected no error when reading zone, got %q", err)
+	}
+	p := newPolicyZone("rpz.example.org.", z)
+	if !p.compile() {
+		t.Fatal("Expected policy zone to compile")
+	}
+	return &RPZ{Next: next, Zones: []string{"."}, policies: []*policyZone{p}}
+}
+
+// backend answers every query with an A or AAAA record and a delegation.
+func backend() test.Handler {
+	return test.HandlerFunc(func(ctx context.Context, w dns.ResponseWriter, r *dns.Msg) (int, error) {
+		m := new(dns.Msg)
+		m.SetReply(r)
+		switch r.Question[0].Name {
+		case "www.example.net.":
+			m.Answer = []dns.RR{test.A("www.example.net. 300 IN A 198.51.100.10")}
+		case "cname.example.net.":
+			m.Answer = []dns.RR{
+				test.CNAME("cname.example.net. 300 IN CNAME www.blocked.example.net."),
+				test.A("www.blocked.example.net. 300 IN A 192.0.2.1"),
+			}
+		case "v6.example.net.":
+			m.Answer = []dns.RR{test.AAAA("v6.example.net. 300 IN AAAA 2001:db8::1")}
+		case "delegated.example.net.":
+			m.Ns = []dns.RR{test.NS("delegated.example.net. 300 IN NS ns.bad.example.")}
+		default:
+			m.Answer = []dns.RR{test.A(r.Question[0].Name + " 300 IN A 192.0.2.10")}
+		}
+		w.WriteMsg(m)
+		return dns.RcodeSuccess, nil
+	})
+}
+
+func TestRPZ(t *testing.T) {
+	rp := newTestRPZ(t, backend())
+
+	tests := []struct {
+		qname  string
+		qtype  uint16
+		rcode  int
+		answer []dns.RR
+		ns     bool // expect the policy zone's SOA in the authority section
+	}{
+		{qname: "example.net.", qtype: dns.TypeA, answer: []dns.RR{test.A("example.net. 300 IN A 192.0.2.10")}},
+		{qname: "blocked.example.net.", qtype: dns.TypeA, rcode: dns.RcodeNameError, ns: true},
+		{qname: "a.b.blocked.example.net.", qtype: dns.TypeA, rcode: dns.RcodeNameError, ns: true},
+		{qname: "allowed.blocked.example.net.", qtype: dns.TypeA, answer: []dns.RR{test.A("allowed.blocked.example.net. 300 IN A 192.0.2.10")}},
+		{qname: "nodata.example.net.", qtype: dns.TypeA, ns: true},
+		{qname: "local.example.net.", qtype: dns.TypeA, answer: []dns.RR{test.A("local.example.net. 3600 IN A 192.0.2.53")}},
+		{qname: "local.example.net.", qtype: dns.TypeTXT, answer: []dns.RR{test.TXT(`local.example.net. 3600 IN TXT "blocked by policy"`)}},
+		{qname: "local.example.net.", qtype: dns.TypeAAAA, ns: true},
+		// response IP triggers
+		{qname: "www.example.net.", qtype: dns.TypeA, rcode: dns.RcodeNameError, ns: true},
+		{qname: "v6.example.net.", qtype: dns.TypeAAAA, ns: true},
+		// CNAME target is checked as QNAME trigger
+		{qname: "cname.example.net.", qtype: dns.TypeA, rcode: dns.RcodeNameError, ns: true},
+		// NSDNAME trigger
+		{qname: "delegated.example.net.", qtype: dns.TypeA, rcode: dns.RcodeNameError, ns: true},
+	}
+
+	for i, tc := range tests {
+		m := new(dns.Msg)
+		m.SetQuestion(tc.qname, tc.qtype)
+		rec := dnstest.NewRecorder(&test.ResponseWriter{})
+		if _, err := rp.ServeDNS(context.TODO(), rec, m); err != nil {
+			t.Fatalf("Test %d: expected no error, got %s", i, err)
+		}
+		if rec.Msg == nil {
+			t.Fatalf("Test %d: expected a response, got none", i)
+		}
+		if rec.Msg.Rcode != tc.rcode {
+			t.Errorf("Test %d: expected rcode %d, got %d", i, tc.rcode, rec.Msg.Rcode)
+		}
+		if len(rec.Msg.Answer) != len(tc.answer) {
+			t.Errorf("Test %d: expected %d answers, got %d", i, len(tc.answer), len(rec.Msg.Answer))
+			continue
+		}
+		for j := range tc.answer {
+			if rec.Msg.Answer[j].String() != tc.answer[j].String() {
+				t.Errorf("Test %d: expected answer %s, got %s", i, tc.answer[j], rec.Msg.Answer[j])
+			}
+		}
+		if tc.ns && (len(rec.Msg.Ns) != 1 || rec.Msg.Ns[0].Header().Rrtype != dns.TypeSOA) {
+			t.Errorf("Test %d: expected SOA in authority section, got %v", i, rec.Msg.Ns)
+		}
+	}
+}
+
+func TestRPZDrop(t *testing.T) {
+	rp := newTestRPZ(t, backend())
+
+	m := new(dns.Msg)
+	m.SetQuestion("drop.example.net.", dns.TypeA)
+	rec := dnstest.NewRecorder(&test.ResponseWriter{})
+	rp.ServeDNS(context.TODO(), rec, m)
+	if rec.Msg != nil {
+		t.Errorf("Expected no response, got %s", rec.Msg)
+	}
+}
+
+func TestRPZClientIP(t *testing.T) {
+	rp := newTestRPZ(t, backend())
+
+	m := new(dns.Msg)
+	m.SetQuestion("example.net.", dns.TypeA)
+	// test.ResponseWriter uses 10.240.0.1, test.ResponseWriter6 uses fe80::42:ff:feca:4c65.
+	rec := dnstest.NewRecorder(&test.ResponseWriter{RemoteIP: "192.0.2.1"})
+	rp.ServeDNS(context.TODO(), rec, m)
+	if rec.Msg == nil || rec.Msg.Rcode != dns.RcodeNameError {
+		t.Errorf("Expected NXDOMAIN for client 192.0.2.1, got %v", rec.Msg)
+	}
+}
+
+func TestRPZTCPOnly(t *testing.T) {
+	rp := newTestRPZ(t, backend())
+
+	m := new(dns.Msg)
+	m.SetQuestion("tcp.example.net.", dns.TypeA)
+	rec := dnstest.NewRecorder(&test.ResponseWriter{})
+	rp.ServeDNS(context.TODO(), rec, m)
+	if rec.Msg == nil || !rec.Msg.Truncated {
+		t.Errorf("Expected truncated response over UDP, got %v", rec.Msg)
+	}
+
+	rec = dnstest.NewRecorder(&test.ResponseWriter{TCP: true})
+	rp.ServeDNS(context.TODO(), rec, m)
+	if rec.Msg == nil || rec.Msg.Truncated || len(rec.Msg.Answer) != 1 {
+		t.Errorf("Expected full response over TCP, got %v", rec.Msg)
+	}
+}
+
+func TestIPFromName(t *testing.T) {
+	tests := []struct {
+		name     string
+		expected string
+		err      bool
+	}{
+		{"32.1.2.0.192", "192.0.2.1/32", false},
+		{"24.0.100.51.198", "198.51.100.0/24", false},
+		{"8.0.0.0.10", "10.0.0.0/8", false},
+		{"128.1.zz.db8.2001", "2001:db8::1/128", false},
+		{"48.zz.db8.2001", "2001:db8::/48", false},
+		{"128.1.zz", "::1/128", false},
+		{"33.1.2.0.192", "", true},
+		{"x.1.2.0.192", "", true},
+		{"32", "", true},
+		{"32.1.2.0.300", "", true},
+	}
+	for i, tc := range tests {
+		n, err := ipFromName(tc.name)
+		if tc.err {
+			if err == nil {
+				t.Errorf("Test %d: expected error for %q, got %s", i, tc.name, n)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("Test %d: expected no error for %q, got %s", i, tc.name, err)
+			continue
+		}
+		if n.String() != tc.expected {
+			t.Errorf("Test %d: expected %s, got %s", i, tc.expected, n)
+		}
+	}
+}
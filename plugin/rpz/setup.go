@@ -0,0 +1,194 @@
+package rpz
+
+import (
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/coredns/caddy"
+	"github.com/coredns/coredns/core/dnsserver"
+	"github.com/coredns/coredns/plugin"
+	"github.com/coredns/coredns/plugin/file"
+	"github.com/coredns/coredns/plugin/pkg/parse"
+	"github.com/coredns/coredns/plugin/pkg/upstream"
+)
+
+func init() { plugin.Register("rpz", setup) }
+
+// compileInterval is how often the policy zones are checked for new content.
+var compileInterval = 1 * time.Second
+
+func setup(c *caddy.Controller) error {
+	rp, err := rpzParse(c)
+	if err != nil {
+		return plugin.Error("rpz", err)
+	}
+
+	stop := make(chan struct{})
+	c.OnStartup(func() error {
+		for _, p := range rp.policies {
+			p := p
+			if len(p.TransferFrom) > 0 {
+				p.StartupOnce.Do(func() { go transfer(p) })
+				continue
+			}
+			p.StartupOnce.Do(func() { p.Reload(nil) })
+		}
+		go rp.compileLoop(stop)
+		return nil
+	})
+
+	c.OnShutdown(func() error {
+		close(stop)
+		for _, p := range rp.policies {
+			if len(p.TransferFrom) == 0 {
+				p.OnShutdown()
+			}
+		}
+		return nil
+	})
+
+	dnsserver.GetConfig(c).AddPlugin(func(next plugin.Handler) plugin.Handler {
+		rp.Next = next
+		return rp
+	})
+
+	return nil
+}
+
+// transfer retrieves the policy zone from its primaries and keeps it up to date.
+func transfer(p *policyZone) {
+	dur := time.Millisecond * 250
+	step := time.Duration(2)
+	max := time.Second * 10
+	for {
+		err := p.TransferIn()
+		if err == nil {
+			break
+		}
+		log.Warningf("All '%s' masters failed to transfer, retrying in %s: %s", p.origin, dur.String(), err)
+		time.Sleep(dur)
+		dur = step * dur
+		if dur > max {
+			dur = max
+		}
+	}
+	p.compile()
+	p.Update()
+}
+
+// compileLoop recompiles the rules of the policy zones whenever their content changed.
+func (rp *RPZ) compileLoop(stop <-chan struct{}) {
+	tick := time.NewTicker(compileInterval)
+	defer tick.Stop()
+	for {
+		select {
+		case <-stop:
+			return
+		case <-tick.C:
+			for _, p := range rp.policies {
+				p.compile()
+			}
+		}
+	}
+}
+
+func rpzParse(c *caddy.Controller) (*RPZ, error) {
+	config := dnsserver.GetConfig(c)
+	rp := &RPZ{upstream: upstream.New()}
+
+	i := 0
+	for c.Next() {
+		if i > 0 {
+			return nil, plugin.ErrOnce
+		}
+		i++
+
+		rp.Zones = plugin.OriginsFromArgsOrServerBlock(c.RemainingArgs(), c.ServerBlockKeys)
+		reload := 1 * time.Minute
+
+		for c.NextBlock() {
+			switch c.Val() {
+			case "policy":
+				p, err := policyParse(c, config.Root)
+				if err != nil {
+					return nil, err
+				}
+				for _, q := range rp.policies {
+					if q.origin == p.origin {
+						return nil, c.Errf("duplicate policy zone '%s'", p.origin)
+					}
+				}
+				rp.policies = append(rp.policies, p)
+
+			case "reload":
+				args := c.RemainingArgs()
+				if len(args) != 1 {
+					return nil, c.ArgErr()
+				}
+				d, err := time.ParseDuration(args[0])
+				if err != nil {
+					return nil, c.Errf("invalid duration for reload '%s'", args[0])
+				}
+				if d < 0 {
+					return nil, c.Errf("invalid negative duration for reload '%s'", args[0])
+				}
+				reload = d
+
+			default:
+				return nil, c.Errf("unknown property '%s'", c.Val())
+			}
+		}
+
+		if len(rp.policies) == 0 {
+			return nil, c.Errf("at least one policy zone is required")
+		}
+		for _, p := range rp.policies {
+			if len(p.TransferFrom) == 0 {
+				p.ReloadInterval = reload
+			}
+			p.compile()
+		}
+	}
+	return rp, nil
+}
+
+// policyParse parses 'policy ORIGIN FILE' and 'policy ORIGIN transfer from ADDRESS...'.
+func policyParse(c *caddy.Controller, root string) (*policyZone, error) {
+	if !c.NextArg() {
+		return nil, c.ArgErr()
+	}
+	origin := plugin.Name(c.Val()).Normalize()
+	if !c.NextArg() {
+		return nil, c.ArgErr()
+	}
+
+	if c.Val() == "transfer" {
+		from, err := parse.TransferIn(c)
+		if err != nil {
+			return nil, err
+		}
+		z := file.NewZone(origin, "stdin")
+		z.TransferFrom = from
+		return newPolicyZone(origin, z), nil
+	}
+
+	fileName := c.Val()
+	if c.NextArg() {
+		return nil, c.ArgErr()
+	}
+	if !filepath.IsAbs(fileName) && root != "" {
+		fileName = filepath.Join(root, fileName)
+	}
+	reader, err := os.Open(filepath.Clean(fileName))
+	if err != nil {
+		return nil, err
+	}
+	defer reader.Close()
+
+	z, err := file.Parse(reader, origin, fileName, 0)
+	if err != nil {
+		return nil, err
+	}
+	return newPolicyZone(origin, z), nil
+}
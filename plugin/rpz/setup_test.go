@@ -0,0 +1,95 @@
+package rpz
+
+import (
+	"testing"
+
+	"github.com/coredns/caddy"
+	"github.com/coredns/coredns/plugin/test"
+)
+
+func TestSetup(t *testing.T) {
+	name, rm, err := test.TempFile(".", dbRPZ)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer rm()
+
+	tests := []struct {
+		input     string
+		shouldErr bool
+		policies  []string
+		transfer  int
+	}{
+		{`rpz {
+			policy rpz.example.org ` + name + `
+		}`, false, []string{"rpz.example.org."}, 0},
+		{`rpz example.net {
+			policy rpz.example.org ` + name + `
+			policy rpz2.example.org transfer from 10.0.0.1 10.0.0.2
+			reload 10s
+		}`, false, []string{"rpz.example.org.", "rpz2.example.org."}, 2},
+		// fails
+		{`rpz`, true, nil, 0},
+		{`rpz {
+			policy rpz.example.org
+		}`, true, nil, 0},
+		{`rpz {
+			policy rpz.example.org ` + name + ` extra
+		}`, true, nil, 0},
+		{`rpz {
+			policy rpz.example.org /does/not/exist
+		}`, true, nil, 0},
+		{`rpz {
+			policy rpz.example.org transfer to 10.0.0.1
+		}`, true, nil, 0},
+		{`rpz {
+			policy rpz.example.org ` + name + `
+			policy rpz.example.org transfer from 10.0.0.1
+		}`, true, nil, 0},
+		{`rpz {
+			policy rpz.example.org ` + name + `
+			reload -1s
+		}`, true, nil, 0},
+		{`rpz {
+			policy rpz.example.org ` + name + `
+			blah
+		}`, true, nil, 0},
+		{`rpz {
+			policy rpz.example.org ` + name + `
+		}
+		rpz {
+			policy rpz.example.org ` + name + `
+		}`, true, nil, 0},
+	}
+
+	for i, tc := range tests {
+		c := caddy.NewTestController("dns", tc.input)
+		rp, err := rpzParse(c)
+		if tc.shouldErr {
+			if err == nil {
+				t.Errorf("Test %d: expected error, got none", i)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("Test %d: expected no error, got %s", i, err)
+			continue
+		}
+		if len(rp.policies) != len(tc.policies) {
+			t.Errorf("Test %d: expected %d policy zones, got %d", i, len(tc.policies), len(rp.policies))
+			continue
+		}
+		for j, p := range rp.policies {
+			if p.origin != tc.policies[j] {
+				t.Errorf("Test %d: expected policy zone %q, got %q", i, tc.policies[j], p.origin)
+			}
+		}
+		transfer := 0
+		for _, p := range rp.policies {
+			transfer += len(p.TransferFrom)
+		}
+		if transfer != tc.transfer {
+			t.Errorf("Test %d: expected %d primaries, got %d", i, tc.transfer, transfer)
+		}
+	}
+}
@@ -1,6 +1,9 @@
 package secondary
 
 import (
+	"os"
+	"path/filepath"
+	"strings"
 	"time"
 
 	"github.com/coredns/caddy"
@@ -29,11 +32,22 @@ func setup(c *caddy.Controller) error {
 		if len(z.TransferFrom) > 0 {
 			c.OnStartup(func() error {
 				z.StartupOnce.Do(func() {
+					loaded := false
+					if z.Persist != "" {
+						err := z.ReadPersisted()
+						switch {
+						case err == nil:
+							loaded = true
+							log.Infof("Read '%s' from %s", n, z.Persist)
+						case !os.IsNotExist(err):
+							log.Warningf("Failed to read '%s' from %s: %s", n, z.Persist, err)
+						}
+					}
 					go func() {
 						dur := time.Millisecond * 250
 						step := time.Duration(2)
 						max := time.Second * 10
-						for {
+						for !loaded {
 							err := z.TransferIn()
 							if err == nil {
 								break
@@ -61,6 +75,7 @@ func setup(c *caddy.Controller) error {
 }
 
 func secondaryParse(c *caddy.Controller) (file.Zones, error) {
+	config := dnsserver.GetConfig(c)
 	z := make(map[string]*file.Zone)
 	names := []string{}
 	for c.Next() {
@@ -82,6 +97,44 @@ func secondaryParse(c *caddy.Controller) (file.Zones, error) {
 					if err != nil {
 						return file.Zones{}, err
 					}
+				case "tsig": // tsig NAME SECRET [ALGORITHM]
+					args := c.RemainingArgs()
+					if len(args) < 2 || len(args) > 3 {
+						return file.Zones{}, c.ArgErr()
+					}
+					alg := "hmac-sha256"
+					if len(args) == 3 {
+						alg = strings.ToLower(args[2])
+					}
+					key, err := file.NewTSIGKey(args[0], alg, args[1])
+					if err != nil {
+						return file.Zones{}, c.Err(err.Error())
+					}
+					for _, origin := range origins {
+						z[origin].TransferKey = key
+					}
+					// Let the server verify NOTIFY messages signed with this key.
+					if config.TsigSecret == nil {
+						config.TsigSecret = make(map[string]string)
+					}
+					config.TsigSecret[key.Name] = key.Secret
+				case "persist": // persist FILE
+					if !c.NextArg() {
+						return file.Zones{}, c.ArgErr()
+					}
+					path := c.Val()
+					if c.NextArg() {
+						return file.Zones{}, c.ArgErr()
+					}
+					if !filepath.IsAbs(path) && config.Root != "" {
+						path = filepath.Join(config.Root, path)
+					}
+					if len(origins) > 1 {
+						return file.Zones{}, c.Errf("persist can only be used for a single zone")
+					}
+					for _, origin := range origins {
+						z[origin].Persist = path
+					}
 				default:
 					return file.Zones{}, c.Errf("unknown property '%s'", c.Val())
 				}
@@ -61,3 +61,56 @@ func TestSecondaryParse(t *testing.T) {
 		}
 	}
 }
+
+func TestSecondaryParseOptions(t *testing.T) {
+	tests := []struct {
+		input     string
+		shouldErr bool
+		tsig      string
+		persist   string
+	}{
+		{`secondary example.org {
+			transfer from 127.0.0.1
+			tsig xfr.example.org c2VjcmV0
+		}`, false, "xfr.example.org.", ""},
+		{`secondary example.org {
+			transfer from 127.0.0.1
+			tsig xfr c2VjcmV0 hmac-sha512
+			persist /var/lib/coredns/db.example.org
+		}`, false, "xfr.", "/var/lib/coredns/db.example.org"},
+		// errors.
+		{`secondary example.org {
+			tsig xfr c2VjcmV0 hmac-md4
+		}`, true, "", ""},
+		{`secondary example.org {
+			tsig xfr not-base64!
+		}`, true, "", ""},
+		{`secondary example.org {
+			tsig xfr
+		}`, true, "", ""},
+		{`secondary example.org example.net {
+			persist /var/lib/coredns/db
+		}`, true, "", ""},
+	}
+
+	for i, tc := range tests {
+		c := caddy.NewTestController("dns", tc.input)
+		s, err := secondaryParse(c)
+		if tc.shouldErr {
+			if err == nil {
+				t.Errorf("Test %d: expected error, got none", i)
+			}
+			continue
+		}
+		if err != nil {
+			t.Fatalf("Test %d: expected no error, got %s", i, err)
+		}
+		z := s.Z["example.org."]
+		if z.TransferKey == nil || z.TransferKey.Name != tc.tsig {
+			t.Errorf("Test %d: expected TSIG key %q, got %v", i, tc.tsig, z.TransferKey)
+		}
+		if z.Persist != tc.persist {
+			t.Errorf("Test %d: expected persist %q, got %q", i, tc.persist, z.Persist)
+		}
+	}
+}
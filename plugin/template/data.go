@@ -0,0 +1,147 @@
+package template
+
+import (
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	"sigs.k8s.io/yaml"
+)
+
+const defaultDataReload = 5 * time.Second
+
+// dataFile is a CSV, JSON or YAML file with keys that templates can look up. It's read again when its
+// size or modification time changes.
+type dataFile struct {
+	path   string
+	reload time.Duration // 0 disables reloading
+
+	sync.RWMutex
+	data  map[string]interface{}
+	mtime time.Time
+	size  int64
+
+	stop chan struct{}
+}
+
+func newDataFile(path string) *dataFile {
+	return &dataFile{path: path, reload: defaultDataReload, data: map[string]interface{}{}}
+}
+
+// Data returns the data read from the file.
+func (d *dataFile) Data() map[string]interface{} {
+	if d == nil {
+		return nil
+	}
+	d.RLock()
+	defer d.RUnlock()
+	return d.data
+}
+
+// read reads the file if it changed since the last time it was read.
+func (d *dataFile) read() error {
+	stat, err := os.Stat(d.path)
+	if err != nil {
+		return err
+	}
+	d.RLock()
+	unchanged := d.mtime.Equal(stat.ModTime()) && d.size == stat.Size()
+	d.RUnlock()
+	if unchanged {
+		return nil
+	}
+
+	buf, err := os.ReadFile(d.path)
+	if err != nil {
+		return err
+	}
+	data, err := parseData(d.path, buf)
+	if err != nil {
+		return err
+	}
+
+	d.Lock()
+	d.data = data
+	d.mtime = stat.ModTime()
+	d.size = stat.Size()
+	d.Unlock()
+	return nil
+}
+
+// start reads the file every reload interval until close is called.
+func (d *dataFile) start() {
+	if d.reload == 0 {
+		return
+	}
+	d.stop = make(chan struct{})
+	go func() {
+		ticker := time.NewTicker(d.reload)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-d.stop:
+				return
+			case <-ticker.C:
+				if err := d.read(); err != nil {
+					log.Warningf("Failed to read data file %s, keeping the previous data: %s", d.path, err)
+				}
+			}
+		}
+	}()
+}
+
+func (d *dataFile) close() {
+	if d.stop != nil {
+		close(d.stop)
+		d.stop = nil
+	}
+}
+
+// parseData parses buf according to the extension of path. JSON and YAML files must hold an object. In
+// a CSV file the first column holds the key; with two columns the value is the second column, otherwise
+// it's a list of the other columns.
+func parseData(path string, buf []byte) (map[string]interface{}, error) {
+	data := map[string]interface{}{}
+	switch ext := strings.ToLower(filepath.Ext(path)); ext {
+	case ".json":
+		if err := json.Unmarshal(buf, &data); err != nil {
+			return nil, err
+		}
+	case ".yaml", ".yml":
+		if err := yaml.Unmarshal(buf, &data); err != nil {
+			return nil, err
+		}
+	case ".csv":
+		r := csv.NewReader(bytes.NewReader(buf))
+		r.FieldsPerRecord = -1
+		r.Comment = '#'
+		r.TrimLeadingSpace = true
+		for {
+			record, err := r.Read()
+			if err == io.EOF {
+				break
+			}
+			if err != nil {
+				return nil, err
+			}
+			switch len(record) {
+			case 1:
+				data[record[0]] = ""
+			case 2:
+				data[record[0]] = record[1]
+			default:
+				data[record[0]] = record[1:]
+			}
+		}
+	default:
+		return nil, fmt.Errorf("unknown data file type %q, must be .csv, .json, .yaml or .yml", ext)
+	}
+	return data, nil
+}
@@ -0,0 +1,140 @@
+package template
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/coredns/caddy"
+	"github.com/coredns/coredns/plugin/pkg/dnstest"
+	"github.com/coredns/coredns/plugin/test"
+
+	"github.com/miekg/dns"
+)
+
+func TestParseData(t *testing.T) {
+	tests := []struct {
+		path     string
+		content  string
+		expected map[string]interface{}
+		err      bool
+	}{
+		{"d.json", `{"acme": "10.0.0.1", "corp": {"ip": "10.0.0.2"}}`, map[string]interface{}{"acme": "10.0.0.1", "corp": map[string]interface{}{"ip": "10.0.0.2"}}, false},
+		{"d.yaml", "acme: 10.0.0.1\ncorp:\n  ip: 10.0.0.2\n", map[string]interface{}{"acme": "10.0.0.1", "corp": map[string]interface{}{"ip": "10.0.0.2"}}, false},
+		{"d.YML", "acme: 10.0.0.1\n", map[string]interface{}{"acme": "10.0.0.1"}, false},
+		{"d.csv", "# tenant,ip\nacme, 10.0.0.1\ncorp,10.0.0.2,10.0.0.3\nnone\n", map[string]interface{}{"acme": "10.0.0.1", "corp": []string{"10.0.0.2", "10.0.0.3"}, "none": ""}, false},
+		{"d.json", `["acme"]`, nil, true},
+		{"d.csv", "acme,\"10.0.0.1\n", nil, true},
+		{"d.txt", "acme 10.0.0.1", nil, true},
+	}
+	for i, tc := range tests {
+		data, err := parseData(tc.path, []byte(tc.content))
+		if tc.err {
+			if err == nil {
+				t.Errorf("Test %d: expected error", i)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("Test %d: expected no error, got %s", i, err)
+			continue
+		}
+		if !reflect.DeepEqual(data, tc.expected) {
+			t.Errorf("Test %d: expected %v, got %v", i, tc.expected, data)
+		}
+	}
+}
+
+func TestDataFileReload(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "tenants.json")
+	if err := os.WriteFile(path, []byte(`{"acme": "10.0.0.1"}`), 0644); err != nil {
+		t.Fatal(err)
+	}
+	d := newDataFile(path)
+	if err := d.read(); err != nil {
+		t.Fatal(err)
+	}
+	if x := d.Data()["acme"]; x != "10.0.0.1" {
+		t.Fatalf("Expected 10.0.0.1, got %v", x)
+	}
+
+	// A broken file keeps the previous data.
+	if err := os.WriteFile(path, []byte(`{"acme": `), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := d.read(); err == nil {
+		t.Fatal("Expected error reading broken file")
+	}
+	if x := d.Data()["acme"]; x != "10.0.0.1" {
+		t.Fatalf("Expected 10.0.0.1, got %v", x)
+	}
+
+	if err := os.WriteFile(path, []byte(`{"acme": "10.0.0.10", "corp": "10.0.0.2"}`), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := d.read(); err != nil {
+		t.Fatal(err)
+	}
+	if x := d.Data()["acme"]; x != "10.0.0.10" {
+		t.Fatalf("Expected 10.0.0.10, got %v", x)
+	}
+
+	var none *dataFile
+	if none.Data() != nil {
+		t.Errorf("Expected no data for nil data file")
+	}
+}
+
+func TestTemplateData(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "tenants.yaml")
+	if err := os.WriteFile(path, []byte("acme: 10.0.0.0/24\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	corefile := `template IN A example {
+		match ^(?P<tenant>[a-z]+)-(?P<host>[0-9]+)\.tenant\.example\.$
+		match ^ip-(?P<ip>[0-9-]+)\.example\.$
+		answer "{{ .Name }} 60 IN A {{ if .Group.ip }}{{ nameToIP .Group.ip }}{{ else }}{{ cidrHost (index .Data .Group.tenant) (parseInt .Group.host 10 8) }}{{ end }}"
+		data ` + path + `
+		reload 10s
+		fallthrough
+	}`
+	c := caddy.NewTestController("dns", corefile)
+	handler, err := templateParse(c)
+	if err != nil {
+		t.Fatalf("Failed to parse: %s", err)
+	}
+	if x := handler.Templates[0].data.reload; x != 10*time.Second {
+		t.Errorf("Expected reload of 10s, got %s", x)
+	}
+
+	tests := []struct {
+		qname    string
+		expected string
+		rcode    int
+	}{
+		{"acme-5.tenant.example.", "10.0.0.5", dns.RcodeSuccess},
+		{"ip-192-168-1-1.example.", "192.168.1.1", dns.RcodeSuccess},
+		// Unknown tenant, the template fails.
+		{"corp-5.tenant.example.", "", dns.RcodeServerFailure},
+	}
+	for i, tc := range tests {
+		m := new(dns.Msg)
+		m.SetQuestion(tc.qname, dns.TypeA)
+		rec := dnstest.NewRecorder(&test.ResponseWriter{})
+		rcode, _ := handler.ServeDNS(context.TODO(), rec, m)
+		if rcode != tc.rcode {
+			t.Errorf("Test %d: expected rcode %d, got %d", i, tc.rcode, rcode)
+			continue
+		}
+		if tc.expected == "" {
+			continue
+		}
+		if x := rec.Msg.Answer[0].(*dns.A).A.String(); x != tc.expected {
+			t.Errorf("Test %d: expected %s, got %s", i, tc.expected, x)
+		}
+	}
+}
@@ -0,0 +1,125 @@
+package template
+
+import (
+	"fmt"
+	"math/big"
+	"net"
+	"strconv"
+	"strings"
+	gotmpl "text/template"
+)
+
+// funcMap holds the functions that can be used in templates.
+var funcMap = gotmpl.FuncMap{
+	"parseInt": strconv.ParseUint,
+	"ipOffset": ipOffset,
+	"ipToName": ipToName,
+	"nameToIP": nameToIP,
+	"cidrHost": cidrHost,
+	"inCIDR":   inCIDR,
+}
+
+// ipOffset returns the address n addresses after ip, n can be negative.
+func ipOffset(ip string, n interface{}) (string, error) {
+	addr := net.ParseIP(ip)
+	if addr == nil {
+		return "", fmt.Errorf("invalid address %q", ip)
+	}
+	i, err := toInt(n)
+	if err != nil {
+		return "", err
+	}
+	return addIPBig(addr, big.NewInt(i))
+}
+
+// ipToName encodes ip so it can be used as a label: 10.0.0.1 becomes 10-0-0-1 and 2001:db8::1 becomes 2001-db8--1.
+func ipToName(ip string) (string, error) {
+	addr := net.ParseIP(ip)
+	if addr == nil {
+		return "", fmt.Errorf("invalid address %q", ip)
+	}
+	s := addr.String()
+	if addr.To4() != nil {
+		return strings.ReplaceAll(s, ".", "-"), nil
+	}
+	return strings.ReplaceAll(s, ":", "-"), nil
+}
+
+// nameToIP decodes a label made by ipToName back to an address.
+func nameToIP(name string) (string, error) {
+	s := strings.ReplaceAll(name, "-", ".")
+	if addr := net.ParseIP(s); addr != nil && addr.To4() != nil {
+		return addr.String(), nil
+	}
+	s = strings.ReplaceAll(name, "-", ":")
+	if addr := net.ParseIP(s); addr != nil {
+		return addr.String(), nil
+	}
+	return "", fmt.Errorf("%q is not an encoded address", name)
+}
+
+// cidrHost returns the n-th address in the network cidr, a negative n counts from the end of the network.
+func cidrHost(cidr string, n interface{}) (string, error) {
+	_, network, err := net.ParseCIDR(cidr)
+	if err != nil {
+		return "", err
+	}
+	host, err := toInt(n)
+	if err != nil {
+		return "", err
+	}
+	ones, bits := network.Mask.Size()
+	size := new(big.Int).Lsh(big.NewInt(1), uint(bits-ones))
+	i := big.NewInt(host)
+	if host < 0 {
+		i.Add(i, size)
+	}
+	if i.Sign() < 0 || i.Cmp(size) >= 0 {
+		return "", fmt.Errorf("host %d is not in %s", host, cidr)
+	}
+	return addIPBig(network.IP, i)
+}
+
+// inCIDR returns true if ip is in the network cidr.
+func inCIDR(ip, cidr string) (bool, error) {
+	addr := net.ParseIP(ip)
+	if addr == nil {
+		return false, fmt.Errorf("invalid address %q", ip)
+	}
+	_, network, err := net.ParseCIDR(cidr)
+	if err != nil {
+		return false, err
+	}
+	return network.Contains(addr), nil
+}
+
+// toInt converts the numbers templates can produce, like the result of parseInt, numbers from a data
+// file or strings, to an int64.
+func toInt(n interface{}) (int64, error) {
+	switch x := n.(type) {
+	case int:
+		return int64(x), nil
+	case int64:
+		return x, nil
+	case uint64:
+		return int64(x), nil
+	case float64:
+		return int64(x), nil
+	case string:
+		return strconv.ParseInt(x, 10, 64)
+	}
+	return 0, fmt.Errorf("%v is not a number", n)
+}
+
+// addIPBig adds n to ip, it fails if the result isn't an address of the same family.
+func addIPBig(ip net.IP, n *big.Int) (string, error) {
+	if ip4 := ip.To4(); ip4 != nil {
+		ip = ip4
+	}
+	sum := new(big.Int).Add(new(big.Int).SetBytes(ip), n)
+	if sum.Sign() < 0 || sum.BitLen() > len(ip)*8 {
+		return "", fmt.Errorf("address %s plus %s overflows", ip, n)
+	}
+	b := sum.FillBytes(make([]byte, len(ip)))
+	return net.IP(b).String(), nil
+}
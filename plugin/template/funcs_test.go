@@ -0,0 +1,53 @@
+package template
+
+import "testing"
+
+func TestIPFuncs(t *testing.T) {
+	tests := []struct {
+		name     string
+		f        func() (interface{}, error)
+		expected interface{}
+		err      bool
+	}{
+		{"ipOffset", func() (interface{}, error) { return ipOffset("10.0.0.1", 10) }, "10.0.0.11", false},
+		{"ipOffset carry", func() (interface{}, error) { return ipOffset("10.0.0.255", 1) }, "10.0.1.0", false},
+		{"ipOffset negative", func() (interface{}, error) { return ipOffset("10.0.1.0", -1) }, "10.0.0.255", false},
+		{"ipOffset v6", func() (interface{}, error) { return ipOffset("2001:db8::ffff", 1) }, "2001:db8::1:0", false},
+		{"ipOffset overflow", func() (interface{}, error) { return ipOffset("255.255.255.255", 1) }, "", true},
+		{"ipOffset underflow", func() (interface{}, error) { return ipOffset("0.0.0.0", -1) }, "", true},
+		{"ipOffset invalid", func() (interface{}, error) { return ipOffset("10.0.0", 1) }, "", true},
+		{"ipToName", func() (interface{}, error) { return ipToName("10.0.0.1") }, "10-0-0-1", false},
+		{"ipToName v6", func() (interface{}, error) { return ipToName("2001:db8::1") }, "2001-db8--1", false},
+		{"ipToName invalid", func() (interface{}, error) { return ipToName("example") }, "", true},
+		{"nameToIP", func() (interface{}, error) { return nameToIP("10-0-0-1") }, "10.0.0.1", false},
+		{"nameToIP v6", func() (interface{}, error) { return nameToIP("2001-db8--1") }, "2001:db8::1", false},
+		{"nameToIP invalid", func() (interface{}, error) { return nameToIP("10-0-0") }, "", true},
+		{"cidrHost", func() (interface{}, error) { return cidrHost("10.1.0.0/16", 258) }, "10.1.1.2", false},
+		{"cidrHost last", func() (interface{}, error) { return cidrHost("10.1.0.0/16", -2) }, "10.1.255.254", false},
+		{"cidrHost v6", func() (interface{}, error) { return cidrHost("fd00::/64", 16) }, "fd00::10", false},
+		{"cidrHost outside", func() (interface{}, error) { return cidrHost("10.1.0.0/24", 256) }, "", true},
+		{"cidrHost string", func() (interface{}, error) { return cidrHost("10.1.0.0/16", "3") }, "10.1.0.3", false},
+		{"cidrHost float", func() (interface{}, error) { return cidrHost("10.1.0.0/16", 3.0) }, "10.1.0.3", false},
+		{"cidrHost uint64", func() (interface{}, error) { return cidrHost("10.1.0.0/16", uint64(3)) }, "10.1.0.3", false},
+		{"cidrHost not a number", func() (interface{}, error) { return cidrHost("10.1.0.0/16", "three") }, "", true},
+		{"inCIDR", func() (interface{}, error) { return inCIDR("10.1.2.3", "10.1.0.0/16") }, true, false},
+		{"inCIDR outside", func() (interface{}, error) { return inCIDR("10.2.2.3", "10.1.0.0/16") }, false, false},
+		{"inCIDR invalid", func() (interface{}, error) { return inCIDR("10.2.2.3", "10.1.0.0") }, false, true},
+	}
+	for _, tc := range tests {
+		got, err := tc.f()
+		if tc.err {
+			if err == nil {
+				t.Errorf("%s: expected error, got %v", tc.name, got)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("%s: expected no error, got %s", tc.name, err)
+			continue
+		}
+		if got != tc.expected {
+			t.Errorf("%s: expected %v, got %v", tc.name, tc.expected, got)
+		}
+	}
+}
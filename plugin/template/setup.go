@@ -1,18 +1,23 @@
 package template
 
 import (
+	"path/filepath"
 	"regexp"
 	"strconv"
 	gotmpl "text/template"
+	"time"
 
 	"github.com/coredns/caddy"
 	"github.com/coredns/coredns/core/dnsserver"
 	"github.com/coredns/coredns/plugin"
+	clog "github.com/coredns/coredns/plugin/pkg/log"
 	"github.com/coredns/coredns/plugin/pkg/upstream"
 
 	"github.com/miekg/dns"
 )
 
+var log = clog.NewWithPlugin("template")
+
 func init() { plugin.Register("template", setupTemplate) }
 
 func setupTemplate(c *caddy.Controller) error {
@@ -21,6 +26,21 @@ func setupTemplate(c *caddy.Controller) error {
 		return plugin.Error("template", err)
 	}
 
+	for _, t := range handler.Templates {
+		if t.data == nil {
+			continue
+		}
+		data := t.data
+		c.OnStartup(func() error {
+			data.start()
+			return nil
+		})
+		c.OnShutdown(func() error {
+			data.close()
+			return nil
+		})
+	}
+
 	dnsserver.GetConfig(c).AddPlugin(func(next plugin.Handler) plugin.Handler {
 		handler.Next = next
 		return handler
@@ -140,6 +160,35 @@ func templateParse(c *caddy.Controller) (handler Handler, err error) {
 					t.ederror = &ederror{code: uint16(code)}
 				}
 
+			case "data":
+				if !c.NextArg() {
+					return handler, c.ArgErr()
+				}
+				path := c.Val()
+				if !filepath.IsAbs(path) && dnsserver.GetConfig(c).Root != "" {
+					path = filepath.Join(dnsserver.GetConfig(c).Root, path)
+				}
+				t.data = newDataFile(path)
+				if err := t.data.read(); err != nil {
+					return handler, c.Errf("could not read data file %s: %v", path, err)
+				}
+				if c.NextArg() {
+					return handler, c.ArgErr()
+				}
+
+			case "reload":
+				if !c.NextArg() {
+					return handler, c.ArgErr()
+				}
+				reload, err := time.ParseDuration(c.Val())
+				if err != nil || reload < 0 {
+					return handler, c.Errf("invalid reload interval %s", c.Val())
+				}
+				if t.data == nil {
+					return handler, c.Errf("reload must follow data")
+				}
+				t.data.reload = reload
+
 			case "fallthrough":
 				t.fall.SetZonesFromArgs(c.RemainingArgs())
 
@@ -36,6 +36,7 @@ type template struct {
 	ederror    *ederror
 	fall       fall.F
 	upstream   Upstreamer
+	data       *dataFile
 }
 
 type ederror struct {
@@ -59,6 +60,7 @@ type templateData struct {
 	Message  *dns.Msg
 	Question *dns.Question
 	Remote   string
+	Data     map[string]interface{}
 	md       map[string]metadata.Func
 }
 
@@ -163,15 +165,12 @@ func executeRRTemplate(server, view, section string, template *gotmpl.Template,
 }
 
 func newTemplate(name, text string) (*gotmpl.Template, error) {
-	funcMap := gotmpl.FuncMap{
-		"parseInt": strconv.ParseUint,
-	}
 	return gotmpl.New(name).Funcs(funcMap).Parse(text)
 }
 
 func (t template) match(ctx context.Context, state request.Request) (*templateData, bool, bool) {
 	q := state.Req.Question[0]
-	data := &templateData{md: metadata.ValueFuncs(ctx), Remote: state.IP()}
+	data := &templateData{md: metadata.ValueFuncs(ctx), Remote: state.IP(), Data: t.data.Data()}
 
 	zone := plugin.Zones(t.zones).Matches(state.Name())
 	if zone == "" {
@@ -0,0 +1,42 @@
+package throttle
+
+import (
+	"sync"
+	"time"
+)
+
+// bucket is a token bucket, it holds up to burst tokens and is refilled at rate tokens per second.
+type bucket struct {
+	sync.Mutex
+	tokens float64
+	last   time.Time
+}
+
+// take takes a token from b. If there is none, and the next token becomes available within maxWait,
+// that token is taken in advance and the time until it's available is returned. Otherwise take
+// returns false.
+func (b *bucket) take(now time.Time, l limit, maxWait time.Duration) (bool, time.Duration) {
+	b.Lock()
+	defer b.Unlock()
+
+	if b.last.IsZero() {
+		b.tokens = float64(l.burst)
+	} else if elapsed := now.Sub(b.last).Seconds(); elapsed > 0 {
+		b.tokens += elapsed * l.rate
+		if b.tokens > float64(l.burst) {
+			b.tokens = float64(l.burst)
+		}
+	}
+	b.last = now
+
+	if b.tokens >= 1 {
+		b.tokens--
+		return true, 0
+	}
+	wait := time.Duration((1 - b.tokens) / l.rate * float64(time.Second))
+	if maxWait > 0 && wait <= maxWait {
+		b.tokens--
+		return true, wait
+	}
+	return false, 0
+}
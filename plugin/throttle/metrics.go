@@ -0,0 +1,25 @@
+package throttle
+
+import (
+	"github.com/coredns/coredns/plugin"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+)
+
+var (
+	// RequestCount is the number of throttled requests, by what was done with them.
+	RequestCount = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: plugin.Namespace,
+		Subsystem: "throttle",
+		Name:      "throttled_requests_total",
+		Help:      "Counter of requests over the limit, by the action taken.",
+	}, []string{"server", "zone", "action"})
+	// ThrottledClients is the number of clients that are currently throttled.
+	ThrottledClients = promauto.NewGaugeVec(prometheus.GaugeOpts{
+		Namespace: plugin.Namespace,
+		Subsystem: "throttle",
+		Name:      "throttled_clients",
+		Help:      "Gauge of the number of clients that are currently throttled.",
+	}, []string{"zone"})
+)
@@ -0,0 +1,235 @@
+package throttle
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"time"
+
+	"github.com/coredns/caddy"
+	"github.com/coredns/coredns/core/dnsserver"
+	"github.com/coredns/coredns/plugin"
+	"github.com/coredns/coredns/plugin/pkg/cache"
+	clog "github.com/coredns/coredns/plugin/pkg/log"
+
+	"github.com/miekg/dns"
+)
+
+var log = clog.NewWithPlugin("throttle")
+
+func init() { plugin.Register("throttle", setup) }
+
+func setup(c *caddy.Controller) error {
+	t, err := parse(c)
+	if err != nil {
+		return plugin.Error("throttle", err)
+	}
+
+	stop := make(chan struct{})
+	c.OnStartup(func() error {
+		go t.throttled.expireLoop(stop)
+		return nil
+	})
+	c.OnShutdown(func() error {
+		close(stop)
+		return nil
+	})
+
+	dnsserver.GetConfig(c).AddPlugin(func(next plugin.Handler) plugin.Handler {
+		t.Next = next
+		return t
+	})
+
+	return nil
+}
+
+func parse(c *caddy.Controller) (*Throttle, error) {
+	t := New()
+
+	i := 0
+	for c.Next() {
+		if i > 0 {
+			return nil, plugin.ErrOnce
+		}
+		i++
+
+		t.Zones = plugin.OriginsFromArgsOrServerBlock(c.RemainingArgs(), c.ServerBlockKeys)
+		burst := -1
+		var inherit []*rule // overrides without an action, they get the default action
+		for c.NextBlock() {
+			switch c.Val() {
+			case "rate": // rate QPS [BURST]
+				args := c.RemainingArgs()
+				if len(args) < 1 || len(args) > 2 {
+					return nil, c.ArgErr()
+				}
+				l, err := parseRate(args)
+				if err != nil {
+					return nil, c.Err(err.Error())
+				}
+				t.def.rate = l.rate
+				if len(args) == 2 {
+					burst = l.burst
+				}
+
+			case "action": // action refuse|truncate|drop|delay [MAX_DELAY]
+				args := c.RemainingArgs()
+				if len(args) < 1 {
+					return nil, c.ArgErr()
+				}
+				a, d, n, err := parseAction(args)
+				if err != nil {
+					return nil, c.Err(err.Error())
+				}
+				if n != len(args) {
+					return nil, c.ArgErr()
+				}
+				t.def.action, t.def.maxDelay = a, d
+
+			case "key": // key ip [IPV4_PREFIX [IPV6_PREFIX]] | key metadata LABEL
+				args := c.RemainingArgs()
+				if len(args) < 1 {
+					return nil, c.ArgErr()
+				}
+				switch args[0] {
+				case "ip":
+					if len(args) > 3 {
+						return nil, c.ArgErr()
+					}
+					t.label = ""
+					t.v4Prefix, t.v6Prefix = 32, 128
+					if len(args) > 1 {
+						p, err := strconv.Atoi(args[1])
+						if err != nil || p < 0 || p > 32 {
+							return nil, c.Errf("invalid IPv4 prefix length '%s'", args[1])
+						}
+						t.v4Prefix = p
+					}
+					if len(args) > 2 {
+						p, err := strconv.Atoi(args[2])
+						if err != nil || p < 0 || p > 128 {
+							return nil, c.Errf("invalid IPv6 prefix length '%s'", args[2])
+						}
+						t.v6Prefix = p
+					}
+				case "metadata":
+					if len(args) != 2 {
+						return nil, c.ArgErr()
+					}
+					t.label = args[1]
+				default:
+					return nil, c.Errf("unknown key '%s'", args[0])
+				}
+
+			case "override": // override ZONE QPS [BURST] [ACTION [MAX_DELAY]]
+				args := c.RemainingArgs()
+				if len(args) < 2 {
+					return nil, c.ArgErr()
+				}
+				zone := plugin.Host(args[0]).NormalizeExact()[0]
+				if plugin.Zones(t.Zones).Matches(zone) == "" {
+					return nil, c.Errf("override zone '%s' is not in the zones of the plugin", args[0])
+				}
+				n := 2
+				if len(args) > 2 {
+					if _, err := strconv.Atoi(args[2]); err == nil {
+						n = 3
+					}
+				}
+				l, err := parseRate(args[1:n])
+				if err != nil {
+					return nil, c.Err(err.Error())
+				}
+				r := &rule{zone: zone, limit: l}
+				if len(args) > n {
+					a, d, m, err := parseAction(args[n:])
+					if err != nil {
+						return nil, c.Err(err.Error())
+					}
+					if n+m != len(args) {
+						return nil, c.ArgErr()
+					}
+					r.action, r.maxDelay = a, d
+				} else {
+					inherit = append(inherit, r)
+				}
+				t.overrides = append(t.overrides, r)
+
+			case "max_clients": // max_clients NUMBER
+				args := c.RemainingArgs()
+				if len(args) != 1 {
+					return nil, c.ArgErr()
+				}
+				n, err := strconv.Atoi(args[0])
+				if err != nil || n <= 0 {
+					return nil, c.Errf("invalid max_clients '%s'", args[0])
+				}
+				t.maxClients = n
+
+			default:
+				return nil, c.Errf("unknown property '%s'", c.Val())
+			}
+		}
+
+		t.def.burst = burst
+		if burst < 0 {
+			t.def.burst = defaultBurst(t.def.rate)
+		}
+		for _, r := range inherit {
+			r.action, r.maxDelay = t.def.action, t.def.maxDelay
+		}
+	}
+
+	sort.SliceStable(t.overrides, func(i, j int) bool {
+		return dns.CountLabel(t.overrides[i].zone) > dns.CountLabel(t.overrides[j].zone)
+	})
+	t.def.buckets = cache.New(t.maxClients)
+	for _, o := range t.overrides {
+		o.buckets = cache.New(t.maxClients)
+	}
+	t.throttled = newThrottledSet(t.maxClients)
+	return t, nil
+}
+
+// parseRate parses QPS [BURST].
+func parseRate(args []string) (limit, error) {
+	rate, err := strconv.ParseFloat(args[0], 64)
+	if err != nil || rate <= 0 {
+		return limit{}, fmt.Errorf("invalid rate '%s'", args[0])
+	}
+	l := limit{rate: rate, burst: defaultBurst(rate)}
+	if len(args) > 1 {
+		b, err := strconv.Atoi(args[1])
+		if err != nil || b < 1 {
+			return limit{}, fmt.Errorf("invalid burst '%s'", args[1])
+		}
+		l.burst = b
+	}
+	return l, nil
+}
+
+// parseAction parses ACTION [MAX_DELAY] and returns the number of arguments used.
+func parseAction(args []string) (action, time.Duration, int, error) {
+	for a, name := range actionNames {
+		if args[0] != name {
+			continue
+		}
+		if a != actionDelay || len(args) == 1 {
+			return a, defaultMaxDelay, 1, nil
+		}
+		d, err := time.ParseDuration(args[1])
+		if err != nil || d <= 0 {
+			return a, 0, 0, fmt.Errorf("invalid maximum delay '%s'", args[1])
+		}
+		return a, d, 2, nil
+	}
+	return actionRefuse, 0, 0, fmt.Errorf("unknown action '%s'", args[0])
+}
+
+// defaultBurst returns the burst for rate, if none is given: one second worth of queries.
+func defaultBurst(rate float64) int {
+	if rate < 1 {
+		return 1
+	}
+	return int(rate + 0.5)
+}
@@ -0,0 +1,138 @@
+package throttle
+
+import (
+	"testing"
+	"time"
+
+	"github.com/coredns/caddy"
+)
+
+func TestSetup(t *testing.T) {
+	tests := []struct {
+		input     string
+		shouldErr bool
+	}{
+		{`throttle`, false},
+		{`throttle example.org`, false},
+		{`throttle {
+			rate 10 20
+			key ip 24 56
+			action delay 500ms
+			max_clients 1000
+		}`, false},
+		{`throttle {
+			key metadata kubernetes/client-namespace
+			action truncate
+		}`, false},
+		{`throttle example.org {
+			override a.example.org 5
+			override b.example.org 5 10 drop
+			override c.example.org 5 delay 2s
+		}`, false},
+		// fails
+		{`throttle
+		throttle`, true},
+		{`throttle {
+			rate
+		}`, true},
+		{`throttle {
+			rate 0
+		}`, true},
+		{`throttle {
+			rate 10 0
+		}`, true},
+		{`throttle {
+			key ip 33
+		}`, true},
+		{`throttle {
+			key ip 24 129
+		}`, true},
+		{`throttle {
+			key metadata
+		}`, true},
+		{`throttle {
+			key port
+		}`, true},
+		{`throttle {
+			action bounce
+		}`, true},
+		{`throttle {
+			action delay forever
+		}`, true},
+		{`throttle {
+			action refuse 1s
+		}`, true},
+		{`throttle example.org {
+			override example.net 5
+		}`, true},
+		{`throttle example.org {
+			override a.example.org 5 10 drop 1s
+		}`, true},
+		{`throttle {
+			max_clients -1
+		}`, true},
+		{`throttle {
+			frobnicate
+		}`, true},
+	}
+
+	for i, test := range tests {
+		c := caddy.NewTestController("dns", test.input)
+		err := setup(c)
+		if test.shouldErr && err == nil {
+			t.Errorf("Test %d: expected error but found none for input %s", i, test.input)
+		}
+		if !test.shouldErr && err != nil {
+			t.Errorf("Test %d: expected no error but found one for input %s, got: %v", i, test.input, err)
+		}
+	}
+}
+
+func TestParse(t *testing.T) {
+	c := caddy.NewTestController("dns", `throttle example.org {
+		rate 10
+		action delay 2s
+		override a.example.org 5 10 drop
+		override b.a.example.org 1
+	}`)
+	th, err := parse(c)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if th.def.rate != 10 || th.def.burst != 10 || th.def.action != actionDelay || th.def.maxDelay != 2*time.Second {
+		t.Errorf("Unexpected default limit: %+v", th.def.limit)
+	}
+	if len(th.overrides) != 2 {
+		t.Fatalf("Expected 2 overrides, got %d", len(th.overrides))
+	}
+	if o := th.overrides[0]; o.zone != "b.a.example.org." || o.action != actionDelay || o.burst != 1 {
+		t.Errorf("Expected most specific override with the default action first, got %s %+v", o.zone, o.limit)
+	}
+	if o := th.overrides[1]; o.zone != "a.example.org." || o.action != actionDrop || o.burst != 10 {
+		t.Errorf("Unexpected override %s %+v", o.zone, o.limit)
+	}
+}
+
+func TestParseOverrideBeforeAction(t *testing.T) {
+	c := caddy.NewTestController("dns", `throttle example.org {
+		override a.example.org 5
+		override b.example.org 5 drop
+		action delay 2s
+	}`)
+	th, err := parse(c)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, o := range th.overrides {
+		switch o.zone {
+		case "a.example.org.":
+			if o.action != actionDelay || o.maxDelay != 2*time.Second {
+				t.Errorf("Expected override %s to get the default action, got %+v", o.zone, o.limit)
+			}
+		case "b.example.org.":
+			if o.action != actionDrop {
+				t.Errorf("Expected override %s to keep its own action, got %+v", o.zone, o.limit)
+			}
+		}
+	}
+}
@@ -0,0 +1,322 @@
+// Package throttle implements a plugin that limits the rate of the queries of each client.
+package throttle
+
+import (
+	"context"
+	"net"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/coredns/coredns/plugin"
+	"github.com/coredns/coredns/plugin/metadata"
+	"github.com/coredns/coredns/plugin/metrics"
+	"github.com/coredns/coredns/plugin/pkg/cache"
+	"github.com/coredns/coredns/request"
+
+	"github.com/miekg/dns"
+)
+
+// action is what is done with a query that exceeds the limit.
+type action int
+
+const (
+	// actionRefuse answers with REFUSED.
+	actionRefuse action = iota
+	// actionTruncate answers with an empty, truncated reply, so the client retries over TCP.
+	actionTruncate
+	// actionDrop doesn't answer.
+	actionDrop
+	// actionDelay holds on to the query until the client is within its limit again.
+	actionDelay
+)
+
+var actionNames = map[action]string{
+	actionRefuse:   "refuse",
+	actionTruncate: "truncate",
+	actionDrop:     "drop",
+	actionDelay:    "delay",
+}
+
+// limit is a rate limit and what to do with the queries that exceed it.
+type limit struct {
+	rate     float64       // queries per second
+	burst    int           // number of queries above the rate that are allowed in a burst
+	action   action        // what to do with the queries over the limit
+	maxDelay time.Duration // longest time a query is delayed, for actionDelay
+}
+
+// rule is a limit for the queries for a zone, with the token buckets of the clients.
+type rule struct {
+	zone string
+	limit
+	buckets *cache.Cache
+	mu      sync.Mutex // serializes creating buckets, so concurrent first queries of a client share one
+}
+
+// bucket returns the token bucket of client key.
+func (r *rule) bucket(key string) *bucket {
+	h := cache.Hash([]byte(key))
+	if b, ok := r.buckets.Get(h); ok {
+		return b.(*bucket)
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if b, ok := r.buckets.Get(h); ok {
+		return b.(*bucket)
+	}
+	b := &bucket{}
+	r.buckets.Add(h, b)
+	return b
+}
+
+// Throttle limits the rate of the queries of each client with a token bucket per client. A client is a
+// source address, a prefix the source address is in, or the value of a metadata label.
+type Throttle struct {
+	Next  plugin.Handler
+	Zones []string
+
+	def       *rule   // limit for all queries in Zones
+	overrides []*rule // limits for subzones, most specific first
+
+	v4Prefix   int    // prefix length of the IPv4 networks of clients
+	v6Prefix   int    // prefix length of the IPv6 networks of clients
+	label      string // metadata label that identifies the clients, if set
+	maxClients int
+
+	throttled *throttledSet
+}
+
+// New returns a Throttle with the default settings.
+func New() *Throttle {
+	return &Throttle{
+		def: &rule{
+			limit:   limit{rate: defaultRate, burst: defaultRate, maxDelay: defaultMaxDelay},
+			buckets: cache.New(defaultMaxClients),
+		},
+		v4Prefix:   32,
+		v6Prefix:   128,
+		maxClients: defaultMaxClients,
+		throttled:  newThrottledSet(defaultMaxClients),
+	}
+}
+
+const (
+	defaultRate       = 100
+	defaultMaxDelay   = time.Second
+	defaultMaxClients = 100000
+)
+
+// ServeDNS implements the plugin.Handler interface.
+func (t *Throttle) ServeDNS(ctx context.Context, w dns.ResponseWriter, r *dns.Msg) (int, error) {
+	state := request.Request{W: w, Req: r}
+	zone := plugin.Zones(t.Zones).Matches(state.Name())
+	if zone == "" {
+		return plugin.NextOrFailure(t.Name(), t.Next, ctx, w, r)
+	}
+
+	ru := t.rule(state.Name())
+	if ru.zone != "" {
+		zone = ru.zone
+	}
+	key := t.key(ctx, state)
+
+	var maxWait time.Duration
+	if ru.action == actionDelay {
+		maxWait = ru.maxDelay
+	}
+	ok, wait := ru.bucket(key).take(time.Now(), ru.limit, maxWait)
+	if ok && wait == 0 {
+		t.throttled.remove(zone, key)
+		return plugin.NextOrFailure(t.Name(), t.Next, ctx, w, r)
+	}
+
+	act := ru.action
+	if !ok && act == actionDelay {
+		// Delaying this one would take too long.
+		act = actionRefuse
+	}
+	if act == actionTruncate && state.Proto() != "udp" {
+		act = actionRefuse
+	}
+	t.throttled.add(zone, key, act, time.Now())
+	RequestCount.WithLabelValues(metrics.WithServer(ctx), zone, actionNames[act]).Inc()
+
+	switch act {
+	case actionDelay:
+		timer := time.NewTimer(wait)
+		select {
+		case <-timer.C:
+		case <-ctx.Done():
+			timer.Stop()
+			return dns.RcodeServerFailure, ctx.Err()
+		}
+		return plugin.NextOrFailure(t.Name(), t.Next, ctx, w, r)
+
+	case actionDrop:
+		return dns.RcodeSuccess, nil
+
+	case actionTruncate:
+		m := new(dns.Msg)
+		m.SetReply(r)
+		m.Truncated = true
+		w.WriteMsg(m)
+		return dns.RcodeSuccess, nil
+	}
+	return dns.RcodeRefused, nil
+}
+
+// rule returns the rule for qname, the most specific override or the default.
+func (t *Throttle) rule(qname string) *rule {
+	for _, o := range t.overrides {
+		if dns.IsSubDomain(o.zone, qname) {
+			return o
+		}
+	}
+	return t.def
+}
+
+// key returns the key of the client that sent the request in state.
+func (t *Throttle) key(ctx context.Context, state request.Request) string {
+	if t.label != "" {
+		if f := metadata.ValueFunc(ctx, t.label); f != nil {
+			if v := f(); v != "" {
+				return t.label + "=" + v
+			}
+		}
+	}
+
+	addr := state.IP()
+	if i := strings.IndexByte(addr, '%'); i >= 0 {
+		addr = addr[:i]
+	}
+	ip := net.ParseIP(addr)
+	if ip == nil {
+		return addr
+	}
+	if ip4 := ip.To4(); ip4 != nil {
+		if t.v4Prefix == 32 {
+			return ip4.String()
+		}
+		return (&net.IPNet{IP: ip4.Mask(net.CIDRMask(t.v4Prefix, 32)), Mask: net.CIDRMask(t.v4Prefix, 32)}).String()
+	}
+	if t.v6Prefix == 128 {
+		return ip.String()
+	}
+	return (&net.IPNet{IP: ip.Mask(net.CIDRMask(t.v6Prefix, 128)), Mask: net.CIDRMask(t.v6Prefix, 128)}).String()
+}
+
+// Name implements the plugin.Handler interface.
+func (t *Throttle) Name() string { return "throttle" }
+
+// Throttled returns the clients that are currently throttled, the most throttled first, as a []Client.
+// It returns an interface{}, so that the admin plugin doesn't need to import this package.
+func (t *Throttle) Throttled() interface{} { return t.throttled.list(time.Now()) }
+
+// throttledExpiry is the time after which a client that is no longer seen is no longer throttled.
+const throttledExpiry = time.Minute
+
+// Client is a client that is being throttled.
+type Client struct {
+	Key     string    `json:"key"`     // address, network or metadata label and value
+	Zone    string    `json:"zone"`    // zone of the limit the client exceeds
+	Action  string    `json:"action"`  // what was done with its last throttled query
+	Since   time.Time `json:"since"`   // first throttled query
+	Last    time.Time `json:"last"`    // last throttled query
+	Queries uint64    `json:"queries"` // number of throttled queries
+}
+
+// throttledSet keeps track of the clients that are being throttled.
+type throttledSet struct {
+	sync.RWMutex
+	clients map[string]*Client
+	max     int
+}
+
+func newThrottledSet(max int) *throttledSet {
+	return &throttledSet{clients: make(map[string]*Client), max: max}
+}
+
+// add records a throttled query of client key for zone.
+func (s *throttledSet) add(zone, key string, a action, now time.Time) {
+	s.Lock()
+	defer s.Unlock()
+
+	c, ok := s.clients[zone+" "+key]
+	if !ok {
+		if len(s.clients) >= s.max {
+			return
+		}
+		c = &Client{Key: key, Zone: zone, Since: now}
+		s.clients[zone+" "+key] = c
+		ThrottledClients.WithLabelValues(zone).Inc()
+	}
+	c.Action = actionNames[a]
+	c.Last = now
+	c.Queries++
+}
+
+// remove records that client key is within its limit for zone again.
+func (s *throttledSet) remove(zone, key string) {
+	s.RLock()
+	_, ok := s.clients[zone+" "+key]
+	s.RUnlock()
+	if !ok {
+		return
+	}
+
+	s.Lock()
+	defer s.Unlock()
+	if _, ok := s.clients[zone+" "+key]; ok {
+		delete(s.clients, zone+" "+key)
+		ThrottledClients.WithLabelValues(zone).Dec()
+	}
+}
+
+// expire removes the clients that haven't been throttled for throttledExpiry.
+func (s *throttledSet) expire(now time.Time) {
+	s.Lock()
+	defer s.Unlock()
+	for k, c := range s.clients {
+		if now.Sub(c.Last) > throttledExpiry {
+			delete(s.clients, k)
+			ThrottledClients.WithLabelValues(c.Zone).Dec()
+		}
+	}
+}
+
+// list returns the throttled clients, the ones with most throttled queries first.
+func (s *throttledSet) list(now time.Time) []Client {
+	s.expire(now)
+
+	s.RLock()
+	clients := make([]Client, 0, len(s.clients))
+	for _, c := range s.clients {
+		clients = append(clients, *c)
+	}
+	s.RUnlock()
+
+	sort.Slice(clients, func(i, j int) bool {
+		if clients[i].Queries != clients[j].Queries {
+			return clients[i].Queries > clients[j].Queries
+		}
+		return clients[i].Key < clients[j].Key
+	})
+	return clients
+}
+
+// expireLoop expires throttled clients until stop is closed.
+func (s *throttledSet) expireLoop(stop <-chan struct{}) {
+	tick := time.NewTicker(throttledExpiry / 6)
+	defer tick.Stop()
+	for {
+		select {
+		case <-stop:
+			return
+		case now := <-tick.C:
+			s.expire(now)
+		}
+	}
+}
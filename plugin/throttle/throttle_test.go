@@ -0,0 +1,217 @@
+package throttle
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/coredns/caddy"
+	"github.com/coredns/coredns/plugin/metadata"
+	"github.com/coredns/coredns/plugin/pkg/cache"
+	"github.com/coredns/coredns/plugin/pkg/dnstest"
+	"github.com/coredns/coredns/plugin/test"
+	"github.com/coredns/coredns/request"
+
+	"github.com/miekg/dns"
+)
+
+func TestBucket(t *testing.T) {
+	l := limit{rate: 10, burst: 2}
+	b := &bucket{}
+	now := time.Now()
+
+	for i := 0; i < 2; i++ {
+		if ok, wait := b.take(now, l, 0); !ok || wait != 0 {
+			t.Fatalf("Expected token %d of the burst, got %t %s", i, ok, wait)
+		}
+	}
+	if ok, _ := b.take(now, l, 0); ok {
+		t.Fatal("Expected empty bucket")
+	}
+
+	// One token is back after 100ms.
+	now = now.Add(100 * time.Millisecond)
+	if ok, _ := b.take(now, l, 0); !ok {
+		t.Fatal("Expected refilled token")
+	}
+
+	// The next token is lent if the wait is short enough.
+	if ok, wait := b.take(now, l, 50*time.Millisecond); ok {
+		t.Fatalf("Expected no token within 50ms, got one after %s", wait)
+	}
+	ok, wait := b.take(now, l, time.Second)
+	if !ok || wait <= 0 || wait > 100*time.Millisecond {
+		t.Fatalf("Expected token within 100ms, got %t %s", ok, wait)
+	}
+
+	// The bucket never holds more than burst.
+	now = now.Add(time.Hour)
+	for i := 0; i < 2; i++ {
+		b.take(now, l, 0)
+	}
+	if ok, _ := b.take(now, l, 0); ok {
+		t.Fatal("Expected no more than burst tokens")
+	}
+}
+
+func TestRuleBucketConcurrent(t *testing.T) {
+	r := &rule{buckets: cache.New(defaultMaxClients)}
+
+	// The first queries of a client arrive at the same time, they must all get the same bucket.
+	buckets := make(chan *bucket, 50)
+	var wg sync.WaitGroup
+	for i := 0; i < cap(buckets); i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			buckets <- r.bucket("10.240.0.1")
+		}()
+	}
+	wg.Wait()
+	close(buckets)
+
+	first := <-buckets
+	for b := range buckets {
+		if b != first {
+			t.Fatal("Expected one bucket for the client")
+		}
+	}
+}
+
+func newThrottle(t *testing.T, input string) *Throttle {
+	c := caddy.NewTestController("dns", input)
+	c.ServerBlockKeys = []string{"."}
+	th, err := parse(c)
+	if err != nil {
+		t.Fatal(err)
+	}
+	th.Next = test.NextHandler(dns.RcodeSuccess, nil)
+	return th
+}
+
+func TestThrottleActions(t *testing.T) {
+	tests := []struct {
+		input     string
+		tcp       bool
+		wantRcode int
+		wantTC    bool
+		wantWrite bool
+		action    string
+	}{
+		{"throttle {\nrate 1 1\n}", false, dns.RcodeRefused, false, false, "refuse"},
+		{"throttle {\nrate 1 1\naction truncate\n}", false, dns.RcodeSuccess, true, true, "truncate"},
+		{"throttle {\nrate 1 1\naction truncate\n}", true, dns.RcodeRefused, false, false, "refuse"},
+		{"throttle {\nrate 1 1\naction drop\n}", false, dns.RcodeSuccess, false, false, "drop"},
+		{"throttle {\nrate 100 1\naction delay 1s\n}", false, dns.RcodeSuccess, false, false, "delay"},
+		{"throttle {\nrate 1 1\naction delay 10ms\n}", false, dns.RcodeRefused, false, false, "refuse"},
+	}
+
+	for i, tc := range tests {
+		th := newThrottle(t, tc.input)
+		m := new(dns.Msg)
+		m.SetQuestion("example.org.", dns.TypeA)
+
+		// The first query takes the only token.
+		rec := dnstest.NewRecorder(&test.ResponseWriter{TCP: tc.tcp})
+		if rcode, _ := th.ServeDNS(context.TODO(), rec, m); rcode != dns.RcodeSuccess {
+			t.Errorf("Test %d: expected first query to pass, got rcode %d", i, rcode)
+		}
+
+		rec = dnstest.NewRecorder(&test.ResponseWriter{TCP: tc.tcp})
+		rcode, _ := th.ServeDNS(context.TODO(), rec, m)
+		if rcode != tc.wantRcode {
+			t.Errorf("Test %d: expected rcode %d, got %d", i, tc.wantRcode, rcode)
+		}
+		if tc.wantWrite != (rec.Msg != nil) {
+			t.Errorf("Test %d: expected a written reply to be %t", i, tc.wantWrite)
+		}
+		if rec.Msg != nil && rec.Msg.Truncated != tc.wantTC {
+			t.Errorf("Test %d: expected TC bit %t, got %t", i, tc.wantTC, rec.Msg.Truncated)
+		}
+
+		clients := th.Throttled().([]Client)
+		if len(clients) != 1 {
+			t.Fatalf("Test %d: expected 1 throttled client, got %d", i, len(clients))
+		}
+		if c := clients[0]; c.Key != "10.240.0.1" || c.Zone != "." || c.Action != tc.action || c.Queries != 1 {
+			t.Errorf("Test %d: unexpected throttled client %+v", i, c)
+		}
+	}
+}
+
+func TestThrottleOverride(t *testing.T) {
+	th := newThrottle(t, `throttle example.org {
+		rate 1 1
+		override a.example.org 100 100
+	}`)
+
+	m := new(dns.Msg)
+	for i := 0; i < 10; i++ {
+		m.SetQuestion("www.a.example.org.", dns.TypeA)
+		if rcode, _ := th.ServeDNS(context.TODO(), dnstest.NewRecorder(&test.ResponseWriter{}), m); rcode != dns.RcodeSuccess {
+			t.Fatalf("Expected query %d for the override to pass, got rcode %d", i, rcode)
+		}
+	}
+
+	m.SetQuestion("www.example.org.", dns.TypeA)
+	th.ServeDNS(context.TODO(), dnstest.NewRecorder(&test.ResponseWriter{}), m)
+	if rcode, _ := th.ServeDNS(context.TODO(), dnstest.NewRecorder(&test.ResponseWriter{}), m); rcode != dns.RcodeRefused {
+		t.Errorf("Expected second query outside the override to be refused, got rcode %d", rcode)
+	}
+
+	m.SetQuestion("example.net.", dns.TypeA)
+	for i := 0; i < 10; i++ {
+		if rcode, _ := th.ServeDNS(context.TODO(), dnstest.NewRecorder(&test.ResponseWriter{}), m); rcode != dns.RcodeSuccess {
+			t.Fatalf("Expected query %d outside the zones to pass, got rcode %d", i, rcode)
+		}
+	}
+}
+
+func TestThrottleKey(t *testing.T) {
+	tests := []struct {
+		input    string
+		remote   string
+		label    string
+		expected string
+	}{
+		{"throttle", "10.0.0.1", "", "10.0.0.1"},
+		{"throttle {\nkey ip 24\n}", "10.0.0.1", "", "10.0.0.0/24"},
+		{"throttle {\nkey ip 24 64\n}", "2001:db8::1", "", "2001:db8::/64"},
+		{"throttle", "2001:db8::1", "", "2001:db8::1"},
+		{"throttle {\nkey metadata test/namespace\n}", "10.0.0.1", "default", "test/namespace=default"},
+		{"throttle {\nkey metadata test/namespace\n}", "10.0.0.1", "", "10.0.0.1"},
+	}
+
+	for i, tc := range tests {
+		th := newThrottle(t, tc.input)
+		ctx := metadata.ContextWithMetadata(context.Background())
+		label := tc.label
+		metadata.SetValueFunc(ctx, "test/namespace", func() string { return label })
+
+		m := new(dns.Msg)
+		m.SetQuestion("example.org.", dns.TypeA)
+		state := request.Request{W: &test.ResponseWriter{RemoteIP: tc.remote}, Req: m}
+		if key := th.key(ctx, state); key != tc.expected {
+			t.Errorf("Test %d: expected key %q, got %q", i, tc.expected, key)
+		}
+	}
+}
+
+func TestThrottledExpire(t *testing.T) {
+	s := newThrottledSet(1)
+	now := time.Now()
+	s.add("example.org.", "10.0.0.1", actionRefuse, now)
+	s.add("example.org.", "10.0.0.2", actionRefuse, now)
+	if n := len(s.list(now)); n != 1 {
+		t.Fatalf("Expected at most 1 throttled client, got %d", n)
+	}
+	s.remove("example.org.", "10.0.0.1")
+	if n := len(s.list(now)); n != 0 {
+		t.Fatalf("Expected no throttled clients after remove, got %d", n)
+	}
+	s.add("example.org.", "10.0.0.1", actionRefuse, now)
+	if n := len(s.list(now.Add(2 * throttledExpiry))); n != 0 {
+		t.Fatalf("Expected throttled client to expire, got %d", n)
+	}
+}
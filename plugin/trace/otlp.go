@@ -0,0 +1,259 @@
+package trace
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	dto "github.com/prometheus/client_model/go"
+	"go.opentelemetry.io/otel/attribute"
+	otbridge "go.opentelemetry.io/otel/bridge/opentracing"
+	"go.opentelemetry.io/otel/exporters/otlp/otlpmetric/otlpmetricgrpc"
+	"go.opentelemetry.io/otel/exporters/otlp/otlpmetric/otlpmetrichttp"
+	"go.opentelemetry.io/otel/exporters/otlp/otlptrace/otlptracegrpc"
+	"go.opentelemetry.io/otel/exporters/otlp/otlptrace/otlptracehttp"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/sdk/instrumentation"
+	sdkmetric "go.opentelemetry.io/otel/sdk/metric"
+	"go.opentelemetry.io/otel/sdk/metric/metricdata"
+	"go.opentelemetry.io/otel/sdk/resource"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	semconv "go.opentelemetry.io/otel/semconv/v1.17.0"
+)
+
+const (
+	otlpTracesPath  = "/v1/traces"
+	otlpMetricsPath = "/v1/metrics"
+	otlpTimeout     = 10 * time.Second
+)
+
+// otlp holds the OpenTelemetry state of a trace plugin that exports over OTLP.
+type otlp struct {
+	provider *sdktrace.TracerProvider
+
+	metrics  sdkmetric.Exporter
+	gatherer prometheus.Gatherer
+	resource *resource.Resource
+	start    time.Time
+	stop     chan struct{}
+	done     chan struct{}
+}
+
+// otlpEndpoint splits endpoint into the host and port to connect to, the URL path to send to and
+// whether to use OTLP/HTTP instead of OTLP/gRPC. Endpoints with a http or https scheme use OTLP/HTTP.
+func otlpEndpoint(endpoint string) (hostport, path string, useHTTP, insecure bool, err error) {
+	if !strings.Contains(endpoint, "://") {
+		return endpoint, "", false, false, nil
+	}
+	u, err := url.Parse(endpoint)
+	if err != nil {
+		return "", "", false, false, err
+	}
+	switch u.Scheme {
+	case "http":
+		insecure = true
+	case "https":
+	default:
+		return "", "", false, false, fmt.Errorf("unsupported OTLP endpoint scheme: %s", u.Scheme)
+	}
+	return u.Host, strings.TrimSuffix(u.Path, "/"), true, insecure, nil
+}
+
+func (t *trace) setupOTLP() error {
+	hostport, path, useHTTP, insecure, err := otlpEndpoint(t.Endpoint)
+	if err != nil {
+		return err
+	}
+	insecure = insecure || t.otlpInsecure
+
+	ctx, cancel := context.WithTimeout(context.Background(), otlpTimeout)
+	defer cancel()
+
+	var exp sdktrace.SpanExporter
+	if useHTTP {
+		opts := []otlptracehttp.Option{otlptracehttp.WithEndpoint(hostport), otlptracehttp.WithURLPath(path + otlpTracesPath)}
+		if insecure {
+			opts = append(opts, otlptracehttp.WithInsecure())
+		}
+		if len(t.otlpHeaders) > 0 {
+			opts = append(opts, otlptracehttp.WithHeaders(t.otlpHeaders))
+		}
+		exp, err = otlptracehttp.New(ctx, opts...)
+	} else {
+		opts := []otlptracegrpc.Option{otlptracegrpc.WithEndpoint(hostport)}
+		if insecure {
+			opts = append(opts, otlptracegrpc.WithInsecure())
+		}
+		if len(t.otlpHeaders) > 0 {
+			opts = append(opts, otlptracegrpc.WithHeaders(t.otlpHeaders))
+		}
+		exp, err = otlptracegrpc.New(ctx, opts...)
+	}
+	if err != nil {
+		return err
+	}
+
+	res := resource.NewSchemaless(semconv.ServiceNameKey.String(t.serviceName))
+	o := &otlp{
+		provider: sdktrace.NewTracerProvider(sdktrace.WithBatcher(exp), sdktrace.WithResource(res)),
+		resource: res,
+	}
+
+	// The bridge lets the plugins, which use the OpenTracing API, create OpenTelemetry spans. It also
+	// stores the OpenTelemetry span in the context, so code using the OpenTelemetry API sees it as
+	// the parent.
+	bridge, _ := otbridge.NewTracerPair(o.provider.Tracer("github.com/coredns/coredns/plugin/trace"))
+	bridge.SetTextMapPropagator(propagation.NewCompositeTextMapPropagator(propagation.TraceContext{}, propagation.Baggage{}))
+	bridge.SetWarningHandler(func(msg string) { log.Debug(strings.TrimSpace(msg)) })
+
+	if t.otlpMetricsInterval > 0 {
+		if useHTTP {
+			opts := []otlpmetrichttp.Option{otlpmetrichttp.WithEndpoint(hostport), otlpmetrichttp.WithURLPath(path + otlpMetricsPath)}
+			if insecure {
+				opts = append(opts, otlpmetrichttp.WithInsecure())
+			}
+			if len(t.otlpHeaders) > 0 {
+				opts = append(opts, otlpmetrichttp.WithHeaders(t.otlpHeaders))
+			}
+			o.metrics, err = otlpmetrichttp.New(ctx, opts...)
+		} else {
+			opts := []otlpmetricgrpc.Option{otlpmetricgrpc.WithEndpoint(hostport)}
+			if insecure {
+				opts = append(opts, otlpmetricgrpc.WithInsecure())
+			}
+			if len(t.otlpHeaders) > 0 {
+				opts = append(opts, otlpmetricgrpc.WithHeaders(t.otlpHeaders))
+			}
+			o.metrics, err = otlpmetricgrpc.New(ctx, opts...)
+		}
+		if err != nil {
+			o.provider.Shutdown(ctx)
+			return err
+		}
+		o.gatherer = prometheus.DefaultGatherer
+		o.start = time.Now()
+		o.stop = make(chan struct{})
+		o.done = make(chan struct{})
+		go o.exportMetrics(t.otlpMetricsInterval)
+	}
+
+	t.otlp = o
+	t.tracer = bridge
+	t.tagSet = tagByProvider["default"]
+	return nil
+}
+
+// exportMetrics exports the metrics every interval until o.stop is closed, then exports them one
+// last time.
+func (o *otlp) exportMetrics(interval time.Duration) {
+	defer close(o.done)
+	tick := time.NewTicker(interval)
+	defer tick.Stop()
+	for {
+		select {
+		case <-tick.C:
+			o.export()
+		case <-o.stop:
+			o.export()
+			return
+		}
+	}
+}
+
+func (o *otlp) export() {
+	mfs, err := o.gatherer.Gather()
+	if err != nil {
+		log.Warningf("Failed to gather metrics: %s", err)
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), otlpTimeout)
+	defer cancel()
+	if err := o.metrics.Export(ctx, toResourceMetrics(o.resource, mfs, o.start, time.Now())); err != nil {
+		log.Warningf("Failed to export metrics: %s", err)
+	}
+}
+
+// shutdown flushes the spans and metrics that have not been exported yet.
+func (o *otlp) shutdown() error {
+	ctx, cancel := context.WithTimeout(context.Background(), otlpTimeout)
+	defer cancel()
+	if o.metrics != nil {
+		close(o.stop)
+		<-o.done
+		o.metrics.Shutdown(ctx)
+	}
+	return o.provider.Shutdown(ctx)
+}
+
+// toResourceMetrics converts the Prometheus metric families mfs to OpenTelemetry metrics.
+// Counters become monotonic cumulative sums that started at start, gauges and untyped metrics
+// become gauges and histograms cumulative histograms. Summaries are not converted.
+func toResourceMetrics(res *resource.Resource, mfs []*dto.MetricFamily, start, now time.Time) metricdata.ResourceMetrics {
+	ms := make([]metricdata.Metrics, 0, len(mfs))
+	for _, mf := range mfs {
+		m := metricdata.Metrics{Name: mf.GetName(), Description: mf.GetHelp()}
+		switch mf.GetType() {
+		case dto.MetricType_COUNTER:
+			sum := metricdata.Sum[float64]{Temporality: metricdata.CumulativeTemporality, IsMonotonic: true}
+			for _, p := range mf.Metric {
+				sum.DataPoints = append(sum.DataPoints, metricdata.DataPoint[float64]{
+					Attributes: labels(p.Label), StartTime: start, Time: now, Value: p.GetCounter().GetValue(),
+				})
+			}
+			m.Data = sum
+		case dto.MetricType_GAUGE, dto.MetricType_UNTYPED:
+			gauge := metricdata.Gauge[float64]{}
+			for _, p := range mf.Metric {
+				v := p.GetGauge().GetValue()
+				if mf.GetType() == dto.MetricType_UNTYPED {
+					v = p.GetUntyped().GetValue()
+				}
+				gauge.DataPoints = append(gauge.DataPoints, metricdata.DataPoint[float64]{
+					Attributes: labels(p.Label), Time: now, Value: v,
+				})
+			}
+			m.Data = gauge
+		case dto.MetricType_HISTOGRAM:
+			hist := metricdata.Histogram{Temporality: metricdata.CumulativeTemporality}
+			for _, p := range mf.Metric {
+				h := p.GetHistogram()
+				dp := metricdata.HistogramDataPoint{
+					Attributes: labels(p.Label), StartTime: start, Time: now,
+					Count: h.GetSampleCount(), Sum: h.GetSampleSum(),
+				}
+				// Prometheus buckets hold cumulative counts, OpenTelemetry buckets the count of
+				// each bucket, with an implicit +Inf bucket at the end.
+				var prev uint64
+				for _, b := range h.Bucket {
+					if math.IsInf(b.GetUpperBound(), 1) {
+						continue
+					}
+					dp.Bounds = append(dp.Bounds, b.GetUpperBound())
+					dp.BucketCounts = append(dp.BucketCounts, b.GetCumulativeCount()-prev)
+					prev = b.GetCumulativeCount()
+				}
+				dp.BucketCounts = append(dp.BucketCounts, h.GetSampleCount()-prev)
+				hist.DataPoints = append(hist.DataPoints, dp)
+			}
+			m.Data = hist
+		default:
+			continue
+		}
+		ms = append(ms, m)
+	}
+	return metricdata.ResourceMetrics{
+		Resource:     res,
+		ScopeMetrics: []metricdata.ScopeMetrics{{Scope: instrumentation.Scope{Name: "github.com/coredns/coredns/plugin/metrics"}, Metrics: ms}},
+	}
+}
+
+func labels(lps []*dto.LabelPair) attribute.Set {
+	kvs := make([]attribute.KeyValue, len(lps))
+	for i, lp := range lps {
+		kvs[i] = attribute.String(lp.GetName(), lp.GetValue())
+	}
+	return attribute.NewSet(kvs...)
+}
@@ -0,0 +1,223 @@
+package trace
+
+import (
+	"context"
+	"encoding/hex"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/coredns/caddy"
+	"github.com/coredns/coredns/core/dnsserver"
+	"github.com/coredns/coredns/plugin/metadata"
+	"github.com/coredns/coredns/plugin/pkg/dnstest"
+	"github.com/coredns/coredns/plugin/test"
+
+	"github.com/miekg/dns"
+	"github.com/prometheus/client_golang/prometheus"
+	dto "github.com/prometheus/client_model/go"
+	"go.opentelemetry.io/otel/sdk/metric/metricdata"
+	"go.opentelemetry.io/otel/sdk/resource"
+	colmetricpb "go.opentelemetry.io/proto/otlp/collector/metrics/v1"
+	coltracepb "go.opentelemetry.io/proto/otlp/collector/trace/v1"
+	"google.golang.org/protobuf/proto"
+)
+
+// collector is a stand-in for an OTLP/HTTP collector that records the spans and metrics it receives.
+type collector struct {
+	mu      sync.Mutex
+	spans   map[string]string // span name -> trace ID
+	metrics map[string]bool
+}
+
+func (c *collector) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	buf, err := io.ReadAll(r.Body)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	switch r.URL.Path {
+	case "/otlp" + otlpTracesPath:
+		req := &coltracepb.ExportTraceServiceRequest{}
+		if err := proto.Unmarshal(buf, req); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		for _, rs := range req.ResourceSpans {
+			for _, ss := range rs.ScopeSpans {
+				for _, s := range ss.Spans {
+					c.spans[s.Name] = hex.EncodeToString(s.TraceId)
+				}
+			}
+		}
+		buf, _ = proto.Marshal(&coltracepb.ExportTraceServiceResponse{})
+	case "/otlp" + otlpMetricsPath:
+		req := &colmetricpb.ExportMetricsServiceRequest{}
+		if err := proto.Unmarshal(buf, req); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		for _, rm := range req.ResourceMetrics {
+			for _, sm := range rm.ScopeMetrics {
+				for _, m := range sm.Metrics {
+					c.metrics[m.Name] = true
+				}
+			}
+		}
+		buf, _ = proto.Marshal(&colmetricpb.ExportMetricsServiceResponse{})
+	default:
+		http.NotFound(w, r)
+		return
+	}
+	w.Header().Set("Content-Type", "application/x-protobuf")
+	w.Write(buf)
+}
+
+func TestOTLPExport(t *testing.T) {
+	col := &collector{spans: map[string]string{}, metrics: map[string]bool{}}
+	srv := httptest.NewServer(col)
+	defer srv.Close()
+
+	tr, err := traceParse(caddy.NewTestController("dns", "trace otlp "+srv.URL+"/otlp {\n otlp_metrics 1h\n}"))
+	if err != nil {
+		t.Fatalf("Error parsing test input: %s", err)
+	}
+	tr.Next = test.NextHandler(dns.RcodeSuccess, nil)
+	if err := tr.OnStartup(); err != nil {
+		t.Fatalf("Error starting tracing plugin: %s", err)
+	}
+
+	const parent = "0af7651916cd43dd8448eb211c80319c"
+	req := httptest.NewRequest("POST", "/dns-query", nil)
+	req.Header.Set("traceparent", "00-"+parent+"-b7ad6b7169203331-01")
+	ctx := context.WithValue(metadata.ContextWithMetadata(context.TODO()), dnsserver.HTTPRequestKey{}, req)
+
+	q := new(dns.Msg).SetQuestion("example.org.", dns.TypeA)
+	if _, err := tr.ServeDNS(ctx, dnstest.NewRecorder(&test.ResponseWriter{}), q); err != nil {
+		t.Fatalf("Error during ServeDNS: %s", err)
+	}
+	if id := metadata.ValueFunc(ctx, metaTraceIdKey)(); id != parent {
+		t.Errorf("Expected trace ID metadata %s, got %s", parent, id)
+	}
+
+	if err := tr.OnShutdown(); err != nil {
+		t.Fatalf("Error shutting down tracing plugin: %s", err)
+	}
+
+	col.mu.Lock()
+	defer col.mu.Unlock()
+	for _, name := range []string{defaultTopLevelSpanName, "handlerfunc"} {
+		id, ok := col.spans[name]
+		if !ok {
+			t.Errorf("Expected span %q to be exported, got %v", name, col.spans)
+			continue
+		}
+		if id != parent {
+			t.Errorf("Expected span %q in trace %s, got %s", name, parent, id)
+		}
+	}
+	if !col.metrics["go_goroutines"] {
+		t.Errorf("Expected metric go_goroutines to be exported, got %v", col.metrics)
+	}
+}
+
+func TestOTLPEndpoint(t *testing.T) {
+	tests := []struct {
+		endpoint  string
+		shouldErr bool
+		hostport  string
+		path      string
+		useHTTP   bool
+		insecure  bool
+	}{
+		{"localhost:4317", false, "localhost:4317", "", false, false},
+		{"http://localhost:4318", false, "localhost:4318", "", true, true},
+		{"https://collector.example.org/otlp/", false, "collector.example.org", "/otlp", true, false},
+		{"grpc://localhost:4317", true, "", "", false, false},
+	}
+	for i, test := range tests {
+		hostport, path, useHTTP, insecure, err := otlpEndpoint(test.endpoint)
+		if test.shouldErr {
+			if err == nil {
+				t.Errorf("Test %d: expected error, got none", i)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("Test %d: expected no error, got %s", i, err)
+			continue
+		}
+		if hostport != test.hostport || path != test.path || useHTTP != test.useHTTP || insecure != test.insecure {
+			t.Errorf("Test %d: expected %s %q %t %t, got %s %q %t %t", i,
+				test.hostport, test.path, test.useHTTP, test.insecure, hostport, path, useHTTP, insecure)
+		}
+	}
+}
+
+func TestToResourceMetrics(t *testing.T) {
+	reg := prometheus.NewRegistry()
+	c := prometheus.NewCounterVec(prometheus.CounterOpts{Name: "test_requests_total"}, []string{"server"})
+	h := prometheus.NewHistogram(prometheus.HistogramOpts{Name: "test_duration_seconds", Buckets: []float64{1, 2}})
+	reg.MustRegister(c, h)
+	c.WithLabelValues("dns://:53").Add(3)
+	for _, v := range []float64{0.5, 1.5, 1.5, 5} {
+		h.Observe(v)
+	}
+	mfs, err := reg.Gather()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	rm := toResourceMetrics(resource.Empty(), mfs, time.Now(), time.Now())
+	ms := rm.ScopeMetrics[0].Metrics
+	if len(ms) != 2 {
+		t.Fatalf("Expected 2 metrics, got %d", len(ms))
+	}
+	var (
+		sum  metricdata.Sum[float64]
+		hist metricdata.Histogram
+	)
+	for _, m := range ms {
+		switch d := m.Data.(type) {
+		case metricdata.Sum[float64]:
+			sum = d
+		case metricdata.Histogram:
+			hist = d
+		}
+	}
+
+	if len(sum.DataPoints) != 1 || sum.DataPoints[0].Value != 3 || !sum.IsMonotonic {
+		t.Errorf("Unexpected counter conversion: %+v", sum)
+	}
+	if v, _ := sum.DataPoints[0].Attributes.Value("server"); v.AsString() != "dns://:53" {
+		t.Errorf("Expected server attribute dns://:53, got %s", v.AsString())
+	}
+
+	if len(hist.DataPoints) != 1 {
+		t.Fatalf("Expected 1 histogram data point, got %d", len(hist.DataPoints))
+	}
+	dp := hist.DataPoints[0]
+	if dp.Count != 4 || dp.Sum != 8.5 {
+		t.Errorf("Expected count 4 and sum 8.5, got %d and %f", dp.Count, dp.Sum)
+	}
+	want := []uint64{1, 2, 1}
+	if len(dp.BucketCounts) != len(want) || len(dp.Bounds) != 2 {
+		t.Fatalf("Expected buckets %v with 2 bounds, got %v with %v", want, dp.BucketCounts, dp.Bounds)
+	}
+	for i := range want {
+		if dp.BucketCounts[i] != want[i] {
+			t.Errorf("Expected buckets %v, got %v", want, dp.BucketCounts)
+			break
+		}
+	}
+
+	// Summaries are skipped.
+	if rm := toResourceMetrics(resource.Empty(), []*dto.MetricFamily{{Type: dto.MetricType_SUMMARY.Enum()}}, time.Now(), time.Now()); len(rm.ScopeMetrics[0].Metrics) != 0 {
+		t.Errorf("Expected summary to be skipped, got %v", rm.ScopeMetrics[0].Metrics)
+	}
+}
@@ -25,6 +25,7 @@ func setup(c *caddy.Controller) error {
 	})
 
 	c.OnStartup(t.OnStartup)
+	c.OnShutdown(t.OnShutdown)
 
 	return nil
 }
@@ -128,6 +129,44 @@ func traceParse(c *caddy.Controller) (*trace, error) {
 				if err != nil {
 					return nil, err
 				}
+			case "otlp_insecure":
+				if tr.EndpointType != "otlp" {
+					return nil, c.Errf("%s requires the otlp endpoint type", c.Val())
+				}
+				if c.NextArg() {
+					return nil, c.ArgErr()
+				}
+				tr.otlpInsecure = true
+			case "otlp_header":
+				if tr.EndpointType != "otlp" {
+					return nil, c.Errf("%s requires the otlp endpoint type", c.Val())
+				}
+				args := c.RemainingArgs()
+				if len(args) != 2 {
+					return nil, c.ArgErr()
+				}
+				if tr.otlpHeaders == nil {
+					tr.otlpHeaders = make(map[string]string)
+				}
+				tr.otlpHeaders[args[0]] = args[1]
+			case "otlp_metrics":
+				if tr.EndpointType != "otlp" {
+					return nil, c.Errf("%s requires the otlp endpoint type", c.Val())
+				}
+				args := c.RemainingArgs()
+				if len(args) > 1 {
+					return nil, c.ArgErr()
+				}
+				tr.otlpMetricsInterval = defOTLPMetricsInterval
+				if len(args) == 1 {
+					tr.otlpMetricsInterval, err = time.ParseDuration(args[0])
+					if err != nil {
+						return nil, err
+					}
+					if tr.otlpMetricsInterval <= 0 {
+						return nil, c.Errf("otlp_metrics interval must be positive: %s", args[0])
+					}
+				}
 			}
 		}
 	}
@@ -143,6 +182,12 @@ func normalizeEndpoint(epType, ep string) (string, string, error) {
 		ep = supportedProviders[epType]
 	}
 
+	if epType == "otlp" {
+		if _, _, _, _, err := otlpEndpoint(ep); err != nil {
+			return "", "", err
+		}
+	}
+
 	if epType == "zipkin" {
 		if !strings.Contains(ep, "http") {
 			ep = "http://" + ep + "/api/v2/spans"
@@ -155,9 +200,12 @@ func normalizeEndpoint(epType, ep string) (string, string, error) {
 var supportedProviders = map[string]string{
 	"zipkin":  "localhost:9411",
 	"datadog": "localhost:8126",
+	"otlp":    "localhost:4317",
 }
 
 const (
 	defEpType      = "zipkin"
 	defServiceName = "coredns"
+
+	defOTLPMetricsInterval = time.Minute
 )
@@ -86,3 +86,45 @@ func TestTraceParse(t *testing.T) {
 		}
 	}
 }
+
+func TestTraceParseOTLP(t *testing.T) {
+	tests := []struct {
+		input           string
+		shouldErr       bool
+		endpoint        string
+		insecure        bool
+		headers         int
+		metricsInterval time.Duration
+	}{
+		{`trace otlp localhost:4317`, false, "localhost:4317", false, 0, 0},
+		{`trace otlp http://collector:4318`, false, "http://collector:4318", false, 0, 0},
+		{"trace otlp collector:4317 {\n otlp_insecure\n otlp_header api-key secret\n otlp_metrics\n}", false, "collector:4317", true, 1, time.Minute},
+		{"trace otlp localhost:4317 {\n otlp_metrics 15s\n}", false, "localhost:4317", false, 0, 15 * time.Second},
+
+		{`trace otlp ftp://collector`, true, "", false, 0, 0},
+		{"trace {\n otlp_insecure\n}", true, "", false, 0, 0},
+		{"trace otlp localhost:4317 {\n otlp_header api-key\n}", true, "", false, 0, 0},
+		{"trace otlp localhost:4317 {\n otlp_metrics 0s\n}", true, "", false, 0, 0},
+		{"trace otlp localhost:4317 {\n otlp_metrics soon\n}", true, "", false, 0, 0},
+	}
+	for i, test := range tests {
+		tr, err := traceParse(caddy.NewTestController("dns", test.input))
+		if test.shouldErr {
+			if err == nil {
+				t.Errorf("Test %d: expected error, got none", i)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("Test %d: expected no error, got %s", i, err)
+			continue
+		}
+		if tr.EndpointType != "otlp" || tr.Endpoint != test.endpoint {
+			t.Errorf("Test %d: expected otlp endpoint %s, got %s %s", i, test.endpoint, tr.EndpointType, tr.Endpoint)
+		}
+		if tr.otlpInsecure != test.insecure || len(tr.otlpHeaders) != test.headers || tr.otlpMetricsInterval != test.metricsInterval {
+			t.Errorf("Test %d: expected %t %d %s, got %t %d %s", i, test.insecure, test.headers, test.metricsInterval,
+				tr.otlpInsecure, len(tr.otlpHeaders), tr.otlpMetricsInterval)
+		}
+	}
+}
@@ -1,4 +1,4 @@
-// Package trace implements OpenTracing-based tracing
+// Package trace implements OpenTracing-based tracing, optionally exported with OpenTelemetry.
 package trace
 
 import (
@@ -6,6 +6,7 @@ import (
 	"fmt"
 	stdlog "log"
 	"net/http"
+	"strings"
 	"sync"
 	"sync/atomic"
 	"time"
@@ -79,6 +80,10 @@ type trace struct {
 	zipkinMaxBacklogSize   int
 	zipkinMaxBatchSize     int
 	zipkinMaxBatchInterval time.Duration
+	otlpInsecure           bool
+	otlpHeaders            map[string]string
+	otlpMetricsInterval    time.Duration
+	otlp                   *otlp
 	Once                   sync.Once
 	tagSet                 traceTags
 }
@@ -105,6 +110,8 @@ func (t *trace) OnStartup() error {
 			)
 			t.tracer = tracer
 			t.tagSet = tagByProvider["datadog"]
+		case "otlp":
+			err = t.setupOTLP()
 		default:
 			err = fmt.Errorf("unknown endpoint type: %s", t.EndpointType)
 		}
@@ -112,6 +119,14 @@ func (t *trace) OnStartup() error {
 	return err
 }
 
+// OnShutdown flushes the spans and metrics an OTLP tracer hasn't exported yet.
+func (t *trace) OnShutdown() error {
+	if t.otlp == nil {
+		return nil
+	}
+	return t.otlp.shutdown()
+}
+
 func (t *trace) setupZipkin() error {
 	var opts []zipkinhttp.ReporterOption
 	opts = append(opts, zipkinhttp.Logger(stdlog.New(&loggerAdapter{log}, "", 0)))
@@ -143,6 +158,20 @@ func (t *trace) setupZipkin() error {
 	return err
 }
 
+// traceID returns the trace ID of the OpenTelemetry span context sc, which the bridge doesn't
+// expose, by injecting it as a W3C traceparent: "00-<trace-id>-<span-id>-<flags>".
+func (t *trace) traceID(sc ot.SpanContext) string {
+	carrier := ot.TextMapCarrier{}
+	if err := t.tracer.Inject(sc, ot.TextMap, carrier); err != nil {
+		return ""
+	}
+	parts := strings.Split(carrier["traceparent"], "-")
+	if len(parts) != 4 {
+		return ""
+	}
+	return parts[1]
+}
+
 // Name implements the Handler interface.
 func (t *trace) Name() string { return "trace" }
 
@@ -177,6 +206,10 @@ func (t *trace) ServeDNS(ctx context.Context, w dns.ResponseWriter, r *dns.Msg)
 		metadata.SetValueFunc(ctx, metaTraceIdKey, func() string { return spanCtx.TraceID.String() })
 	case ddtrace.SpanContext:
 		metadata.SetValueFunc(ctx, metaTraceIdKey, func() string { return fmt.Sprint(spanCtx.TraceID()) })
+	default:
+		if t.otlp != nil {
+			metadata.SetValueFunc(ctx, metaTraceIdKey, func() string { return t.traceID(spanCtx) })
+		}
 	}
 
 	rw := dnstest.NewRecorder(w)
@@ -227,3 +227,68 @@ www 3600 IN A   127.0.0.53
 		t.Errorf("Failed to get address for CNAME, expected 127.0.0.53, got %s", x)
 	}
 }
+
+func TestFileUpstreamAlias(t *testing.T) {
+	name, rm, err := test.TempFile(".", `$ORIGIN example.org.
+@	3600 IN	SOA   sns.dns.icann.org. noc.dns.icann.org. (
+        2017042745 ; serial
+        7200       ; refresh (2 hours)
+        3600       ; retry (1 hour)
+        1209600    ; expire (2 weeks)
+        3600       ; minimum (1 hour)
+)
+
+    3600 IN NS    a.iana-servers.net.
+    3600 IN NS    b.iana-servers.net.
+    300  IN ALIAS www.example.net.
+
+nx  300  IN ALIAS nxdomain
+`)
+	if err != nil {
+		t.Fatalf("Failed to create zone: %s", err)
+	}
+	defer rm()
+
+	corefile := `.:0 {
+		file ` + name + ` example.org
+		hosts {
+			10.0.0.1 www.example.net.
+			fallthrough example.org
+		}
+	}`
+
+	i, udp, _, err := CoreDNSServerAndPorts(corefile)
+	if err != nil {
+		t.Fatalf("Could not get CoreDNS serving instance: %s", err)
+	}
+	defer i.Stop()
+
+	m := new(dns.Msg)
+	m.SetQuestion("example.org.", dns.TypeA)
+	r, err := dns.Exchange(m, udp)
+	if err != nil {
+		t.Fatalf("Could not exchange msg: %s", err)
+	}
+	if len(r.Answer) != 1 {
+		t.Fatalf("Expected 1 RR in answer section, got %d", len(r.Answer))
+	}
+	if x := r.Answer[0].Header().Name; x != "example.org." {
+		t.Errorf("Expected owner name example.org., got %s", x)
+	}
+	if x := r.Answer[0].(*dns.A).A.String(); x != "10.0.0.1" {
+		t.Errorf("Failed to get address for ALIAS, expected 10.0.0.1 got %s", x)
+	}
+
+	// A target that doesn't exist is NODATA for the owner.
+	m.SetQuestion("nx.example.org.", dns.TypeA)
+	r, err = dns.Exchange(m, udp)
+	if err != nil {
+		t.Fatalf("Could not exchange msg: %s", err)
+	}
+	if r.Rcode != dns.RcodeSuccess || len(r.Answer) != 0 {
+		t.Errorf("Expected NODATA, got rcode %s with %d answers", dns.RcodeToString[r.Rcode], len(r.Answer))
+	}
+	if len(r.Ns) != 1 || r.Ns[0].Header().Rrtype != dns.TypeSOA {
+		t.Errorf("Expected SOA in authority section, got %v", r.Ns)
+	}
+}
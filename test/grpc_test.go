@@ -2,10 +2,13 @@ package test
 
 import (
 	"context"
+	"os"
+	"strings"
 	"testing"
 	"time"
 
 	"github.com/coredns/coredns/pb"
+	"github.com/coredns/coredns/plugin/test"
 
 	"github.com/miekg/dns"
 	"google.golang.org/grpc"
@@ -56,3 +59,145 @@ func TestGrpc(t *testing.T) {
 		t.Errorf("Expected 2 RRs in additional section, but got %d", len(d.Extra))
 	}
 }
+
+func TestGrpcStream(t *testing.T) {
+	corefile := `grpc://.:0 {
+		whoami
+	}`
+
+	g, _, tcp, err := CoreDNSServerAndPorts(corefile)
+	if err != nil {
+		t.Fatalf("Could not get CoreDNS serving instance: %s", err)
+	}
+	defer g.Stop()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	conn, err := grpc.DialContext(ctx, tcp, grpc.WithTransportCredentials(insecure.NewCredentials()), grpc.WithBlock())
+	if err != nil {
+		t.Fatalf("Expected no error but got: %s", err)
+	}
+	defer conn.Close()
+
+	stream, err := pb.NewDnsServiceClient(conn).Stream(ctx)
+	if err != nil {
+		t.Fatalf("Expected no error but got: %s", err)
+	}
+
+	ids := map[uint16]bool{}
+	for i := uint16(1); i <= 10; i++ {
+		m := new(dns.Msg)
+		m.SetQuestion("whoami.example.org.", dns.TypeA)
+		m.Id = i
+		msg, _ := m.Pack()
+		if err := stream.Send(&pb.DnsPacket{Msg: msg}); err != nil {
+			t.Fatalf("Expected no error but got: %s", err)
+		}
+		ids[i] = true
+	}
+	// A query that can't be unpacked gets FORMERR, and doesn't end the stream.
+	if err := stream.Send(&pb.DnsPacket{Msg: []byte{0, 11, 0xff}}); err != nil {
+		t.Fatalf("Expected no error but got: %s", err)
+	}
+	stream.CloseSend()
+
+	formerr := false
+
+	for len(ids) > 0 {
+		reply, err := stream.Recv()
+		if err != nil {
+			t.Fatalf("Expected no error but got: %s", err)
+		}
+		d := new(dns.Msg)
+		if err := d.Unpack(reply.Msg); err != nil {
+			t.Fatalf("Expected no error but got: %s", err)
+		}
+		if d.Id == 11 && !formerr {
+			if d.Rcode != dns.RcodeFormatError {
+				t.Errorf("Expected FORMERR for malformed query, got %d", d.Rcode)
+			}
+			formerr = true
+			continue
+		}
+		if !ids[d.Id] {
+			t.Fatalf("Unexpected reply with ID %d", d.Id)
+		}
+		delete(ids, d.Id)
+		if d.Rcode != dns.RcodeSuccess || len(d.Extra) != 2 {
+			t.Errorf("Expected success with 2 RRs in additional section, but got %d with %d", d.Rcode, len(d.Extra))
+		}
+	}
+	if !formerr {
+		reply, err := stream.Recv()
+		if err != nil {
+			t.Fatalf("Expected FORMERR reply but got: %s", err)
+		}
+		d := new(dns.Msg)
+		if err := d.Unpack(reply.Msg); err != nil || d.Id != 11 || d.Rcode != dns.RcodeFormatError {
+			t.Errorf("Expected FORMERR with ID 11, got %v (%v)", d, err)
+		}
+	}
+}
+
+func TestGrpcWatch(t *testing.T) {
+	name, rm, err := test.TempFile(".", exampleOrg)
+	if err != nil {
+		t.Fatalf("Failed to create zone: %s", err)
+	}
+	defer rm()
+
+	corefile := `grpc://example.org:0 {
+		file ` + name + ` {
+			reload 100ms
+		}
+	}`
+
+	g, _, tcp, err := CoreDNSServerAndPorts(corefile)
+	if err != nil {
+		t.Fatalf("Could not get CoreDNS serving instance: %s", err)
+	}
+	defer g.Stop()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	conn, err := grpc.DialContext(ctx, tcp, grpc.WithTransportCredentials(insecure.NewCredentials()), grpc.WithBlock())
+	if err != nil {
+		t.Fatalf("Expected no error but got: %s", err)
+	}
+	defer conn.Close()
+
+	m := new(dns.Msg)
+	m.SetQuestion("short.example.org.", dns.TypeA)
+	msg, _ := m.Pack()
+	watch, err := pb.NewDnsServiceClient(conn).Watch(ctx, &pb.DnsPacket{Msg: msg})
+	if err != nil {
+		t.Fatalf("Expected no error but got: %s", err)
+	}
+
+	recv := func() *dns.Msg {
+		reply, err := watch.Recv()
+		if err != nil {
+			t.Fatalf("Expected no error but got: %s", err)
+		}
+		d := new(dns.Msg)
+		if err := d.Unpack(reply.Msg); err != nil {
+			t.Fatalf("Expected no error but got: %s", err)
+		}
+		return d
+	}
+
+	if d := recv(); len(d.Answer) != 1 || d.Answer[0].(*dns.A).A.String() != "127.0.0.3" {
+		t.Fatalf("Expected initial answer 127.0.0.3, got %v", d.Answer)
+	}
+
+	// A new serial and address for short.example.org.
+	zone := strings.Replace(exampleOrg, "2015082541", "2015082542", 1)
+	zone = strings.Replace(zone, "127.0.0.3", "127.0.0.4", 1)
+	if err := os.WriteFile(name, []byte(zone), 0644); err != nil {
+		t.Fatalf("Failed to update zone: %s", err)
+	}
+
+	if d := recv(); len(d.Answer) != 1 || d.Answer[0].(*dns.A).A.String() != "127.0.0.4" {
+		t.Fatalf("Expected changed answer 127.0.0.4, got %v", d.Answer)
+	}
+}
@@ -12,6 +12,8 @@ import (
 	"github.com/coredns/caddy"
 	"github.com/coredns/coredns/core/dnsserver"
 	"github.com/coredns/coredns/plugin"
+	"github.com/coredns/coredns/plugin/pkg/dnstest"
+	"github.com/coredns/coredns/plugin/test"
 
 	"github.com/miekg/dns"
 )
@@ -43,6 +45,50 @@ func TestReload(t *testing.T) {
 	c1.Stop()
 }
 
+func TestReloadKeepsCache(t *testing.T) {
+	upstream := dnstest.NewServer(func(w dns.ResponseWriter, r *dns.Msg) {
+		ret := new(dns.Msg)
+		ret.SetReply(r)
+		ret.Answer = append(ret.Answer, test.A("example.org. 300 IN A 127.0.0.1"))
+		w.WriteMsg(ret)
+	})
+
+	corefile := `.:0 {
+		cache
+		forward . ` + upstream.Addr + `
+	}`
+
+	c, err := CoreDNSServer(corefile)
+	if err != nil {
+		t.Fatalf("Could not get CoreDNS serving instance: %s", err)
+	}
+	udp, _ := CoreDNSServerPorts(c, 0)
+
+	m := new(dns.Msg)
+	m.SetQuestion("example.org.", dns.TypeA)
+	if _, err := dns.Exchange(m, udp); err != nil {
+		t.Fatalf("Expected to receive reply, but didn't: %s", err)
+	}
+
+	c1, err := c.Restart(NewInput(corefile))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer c1.Stop()
+	udp, _ = CoreDNSServerPorts(c1, 0)
+
+	// with the upstream gone the answer can only come from the cache of the previous instance.
+	upstream.Close()
+
+	r, err := dns.Exchange(m, udp)
+	if err != nil {
+		t.Fatalf("Expected to receive reply, but didn't: %s", err)
+	}
+	if r.Rcode != dns.RcodeSuccess || len(r.Answer) != 1 {
+		t.Errorf("Expected cached answer after reload, got %s", r)
+	}
+}
+
 func send(t *testing.T, server string) {
 	m := new(dns.Msg)
 	m.SetQuestion("whoami.example.org.", dns.TypeSRV)
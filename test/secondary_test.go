@@ -1,9 +1,13 @@
 package test
 
 import (
+	"fmt"
+	"net"
+	"sync/atomic"
 	"testing"
 	"time"
 
+	"github.com/coredns/coredns/plugin/pkg/dnstest"
 	"github.com/coredns/coredns/plugin/test"
 
 	"github.com/miekg/dns"
@@ -215,3 +219,62 @@ www    IN AAAA ::1
 		}
 	}
 }
+
+func TestSecondaryNotify(t *testing.T) {
+	var serial uint32 = 2017042745
+	primary := dnstest.NewServer(func(w dns.ResponseWriter, r *dns.Msg) {
+		m := new(dns.Msg)
+		m.SetReply(r)
+		soa := test.SOA(fmt.Sprintf("example.org. 3600 IN SOA sns.dns.icann.org. noc.dns.icann.org. %d 7200 3600 1209600 3600", atomic.LoadUint32(&serial)))
+		switch r.Question[0].Qtype {
+		case dns.TypeSOA:
+			m.Answer = []dns.RR{soa}
+		case dns.TypeAXFR:
+			m.Answer = []dns.RR{soa, test.NS("example.org. 3600 IN NS a.iana-servers.net."), soa}
+		default:
+			m.Rcode = dns.RcodeNotImplemented
+		}
+		w.WriteMsg(m)
+	})
+	defer primary.Close()
+	_, port, _ := net.SplitHostPort(primary.Addr)
+
+	corefile := `example.org:0 {
+		secondary {
+			transfer from 127.0.0.1:` + port + `
+		}
+	}`
+	i, udp, _, err := CoreDNSServerAndPorts(corefile)
+	if err != nil {
+		t.Fatalf("Could not get CoreDNS serving instance: %s", err)
+	}
+	defer i.Stop()
+
+	waitSerial := func(want uint32) {
+		m := new(dns.Msg)
+		m.SetQuestion("example.org.", dns.TypeSOA)
+		for j := 0; j < 50; j++ {
+			r, err := dns.Exchange(m, udp)
+			if err == nil && len(r.Answer) == 1 && r.Answer[0].(*dns.SOA).Serial == want {
+				return
+			}
+			time.Sleep(100 * time.Millisecond)
+		}
+		t.Fatalf("Expected serial %d", want)
+	}
+	waitSerial(2017042745)
+
+	atomic.StoreUint32(&serial, 2017042746)
+	m := new(dns.Msg)
+	m.SetNotify("example.org.")
+	// Send the notify from the address of the primary.
+	_, sport, _ := net.SplitHostPort(udp)
+	r, err := dns.Exchange(m, net.JoinHostPort("127.0.0.1", sport))
+	if err != nil {
+		t.Fatalf("Expected a reply to the notify: %s", err)
+	}
+	if r.Rcode != dns.RcodeSuccess || r.Opcode != dns.OpcodeNotify {
+		t.Fatalf("Expected a successful notify reply, got %s", r)
+	}
+	waitSerial(2017042746)
+}